@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Trip вручную переводит Circuit Breaker в состояние Open.
+func (cb *CircuitBreaker) Trip() {
+	cb.trip()
+}
+
+// Reset вручную переводит Circuit Breaker в состояние Closed
+// и снимает режим, заданный через SetMode.
+func (cb *CircuitBreaker) Reset() {
+	cb.SetMode(KillSwitchOff)
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.current.Load().state != StateClosed {
+		cb.transition(StateClosed, ReasonManual, nil)
+	}
+}
+
+// Mode возвращает текущий принудительный режим, см. SetMode и WithKillSwitch.
+func (cb *CircuitBreaker) Mode() KillSwitchMode {
+	return cb.mode()
+}
+
+type AdminOption func(*adminHandler)
+
+// WithAdminMiddleware оборачивает все запросы к AdminHandler в middleware,
+// например для аутентификации. Первый middleware - внешний.
+func WithAdminMiddleware(middleware ...func(http.Handler) http.Handler) AdminOption {
+	return func(h *adminHandler) {
+		h.middleware = append(h.middleware, middleware...)
+	}
+}
+
+// WithAdminEvents добавляет GET /events - поток событий bus, см. EventStreamHandler.
+func WithAdminEvents(bus *EventBus) AdminOption {
+	return func(h *adminHandler) {
+		h.bus = bus
+	}
+}
+
+// AdminHandler возвращает http.Handler для управления Circuit Breaker реестра:
+//
+//	GET  /stats                   - статистика реестра, см. RegistryStats
+//	GET  /breakers                - список с состояниями
+//	GET  /breakers/{name}         - статистика Circuit Breaker
+//	POST /breakers/{name}/trip    - перевод в Open, см. Trip
+//	POST /breakers/{name}/reset   - перевод в Closed, см. Reset
+//	POST /breakers/{name}/disable - отключение, см. KillSwitchDisabled
+//	GET  /events                  - поток событий, см. WithAdminEvents
+//
+// Для монтирования под префиксом используется http.StripPrefix.
+func AdminHandler(r *Registry, options ...AdminOption) http.Handler {
+	h := newAdminHandler(r, options)
+	return h.wrap(h.mux())
+}
+
+type adminHandler struct {
+	registry   *Registry
+	bus        *EventBus
+	middleware []func(http.Handler) http.Handler
+}
+
+func newAdminHandler(r *Registry, options []AdminOption) *adminHandler {
+	h := &adminHandler{registry: r}
+	for _, opt := range options {
+		opt(h)
+	}
+	return h
+}
+
+func (h *adminHandler) mux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /stats", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, h.registry.Stats())
+	})
+	mux.HandleFunc("GET /breakers", h.list)
+	mux.HandleFunc("GET /breakers/{name}", h.get)
+	mux.HandleFunc("POST /breakers/{name}/trip", h.action((*CircuitBreaker).Trip))
+	mux.HandleFunc("POST /breakers/{name}/reset", h.action((*CircuitBreaker).Reset))
+	mux.HandleFunc("POST /breakers/{name}/disable", h.action(func(cb *CircuitBreaker) {
+		cb.SetMode(KillSwitchDisabled)
+	}))
+	if h.bus != nil {
+		mux.Handle("GET /events", EventStreamHandler(h.bus))
+	}
+	return mux
+}
+
+// wrap оборачивает handler в middleware.
+func (h *adminHandler) wrap(handler http.Handler) http.Handler {
+	for i := len(h.middleware) - 1; i >= 0; i-- {
+		handler = h.middleware[i](handler)
+	}
+	return handler
+}
+
+type adminBreakerJSON struct {
+	Name  string     `json:"name"`
+	State string     `json:"state"`
+	Mode  string     `json:"mode"`
+	Stats *statsJSON `json:"stats,omitempty"`
+}
+
+func newAdminBreakerJSON(name string, cb *CircuitBreaker) adminBreakerJSON {
+	return adminBreakerJSON{Name: name, State: cb.State().String(), Mode: cb.Mode().String()}
+}
+
+func (h *adminHandler) list(w http.ResponseWriter, _ *http.Request) {
+	breakers := []adminBreakerJSON{}
+	h.registry.Range(func(name string, cb *CircuitBreaker) bool {
+		breakers = append(breakers, newAdminBreakerJSON(name, cb))
+		return true
+	})
+	writeJSON(w, breakers)
+}
+
+func (h *adminHandler) get(w http.ResponseWriter, req *http.Request) {
+	name := req.PathValue("name")
+	cb, ok := h.registry.Lookup(name)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	v := newAdminBreakerJSON(name, cb)
+	stats := cb.Stats().toJSON()
+	v.Stats = &stats
+	writeJSON(w, v)
+}
+
+// action выполняет fn над Circuit Breaker из пути и возвращает его новое состояние.
+func (h *adminHandler) action(fn func(cb *CircuitBreaker)) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		name := req.PathValue("name")
+		cb, ok := h.registry.Lookup(name)
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+
+		fn(cb)
+		writeJSON(w, newAdminBreakerJSON(name, cb))
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeIndentedJSON пишет v с отступами, для ответов, читаемых человеком.
+func writeIndentedJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminHandler(t *testing.T) {
+	r := NewRegistry()
+	payments := r.Get("payments")
+	r.Get("search")
+	handler := AdminHandler(r)
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/breakers/payments/trip")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"name": "payments", "state": "open", "mode": "off"}`, rec.Body.String())
+	assert.Equal(t, StateOpen, payments.State())
+
+	rec = do(http.MethodPost, "/breakers/search/disable")
+	assert.JSONEq(t, `{"name": "search", "state": "closed", "mode": "disabled"}`, rec.Body.String())
+
+	rec = do(http.MethodGet, "/breakers")
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `[
+		{"name": "payments", "state": "open", "mode": "off"},
+		{"name": "search", "state": "closed", "mode": "disabled"}
+	]`, rec.Body.String())
+
+	rec = do(http.MethodPost, "/breakers/search/reset")
+	assert.JSONEq(t, `{"name": "search", "state": "closed", "mode": "off"}`, rec.Body.String())
+	do(http.MethodPost, "/breakers/payments/reset")
+	assert.Equal(t, StateClosed, payments.State())
+	assert.Equal(t, ReasonManual, payments.Transitions(time.Time{})[1].Reason)
+
+	rec = do(http.MethodGet, "/breakers/payments")
+	var v struct {
+		Name  string
+		Stats struct {
+			Version int `json:"version"`
+		}
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &v))
+	assert.Equal(t, "payments", v.Name)
+	assert.Equal(t, StatsVersion, v.Stats.Version)
+
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/breakers/orders").Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodPost, "/breakers/orders/trip").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, do(http.MethodGet, "/breakers/payments/trip").Code)
+	_, ok := r.Lookup("orders")
+	assert.False(t, ok)
+}
+
+func TestAdminHandler_Middleware(t *testing.T) {
+	r := NewRegistry()
+	cb := r.Get("payments")
+
+	var order []string
+	trace := func(name string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, req)
+			})
+		}
+	}
+	auth := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Header.Get("Authorization") != "Bearer secret" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+	handler := AdminHandler(r, WithAdminMiddleware(trace("outer"), trace("inner")), WithAdminMiddleware(auth))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/breakers/payments/trip", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, []string{"outer", "inner"}, order)
+
+	req := httptest.NewRequest(http.MethodPost, "/breakers/payments/trip", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, StateOpen, cb.State())
+}
+
+func TestAdminHandler_Events(t *testing.T) {
+	r := NewRegistry()
+	rec := httptest.NewRecorder()
+	AdminHandler(r).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	AdminHandler(r, WithAdminEvents(NewEventBus(nil))).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events?type=bogus", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
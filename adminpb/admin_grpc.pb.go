@@ -0,0 +1,298 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: adminpb/admin.proto
+
+package adminpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	CircuitBreakerAdmin_ListBreakers_FullMethodName = "/circuitbreaker.admin.v1.CircuitBreakerAdmin/ListBreakers"
+	CircuitBreakerAdmin_GetBreaker_FullMethodName   = "/circuitbreaker.admin.v1.CircuitBreakerAdmin/GetBreaker"
+	CircuitBreakerAdmin_Trip_FullMethodName         = "/circuitbreaker.admin.v1.CircuitBreakerAdmin/Trip"
+	CircuitBreakerAdmin_Reset_FullMethodName        = "/circuitbreaker.admin.v1.CircuitBreakerAdmin/Reset"
+	CircuitBreakerAdmin_WatchEvents_FullMethodName  = "/circuitbreaker.admin.v1.CircuitBreakerAdmin/WatchEvents"
+)
+
+// CircuitBreakerAdminClient is the client API for CircuitBreakerAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// CircuitBreakerAdmin управляет Circuit Breaker реестра.
+type CircuitBreakerAdminClient interface {
+	ListBreakers(ctx context.Context, in *ListBreakersRequest, opts ...grpc.CallOption) (*ListBreakersResponse, error)
+	GetBreaker(ctx context.Context, in *GetBreakerRequest, opts ...grpc.CallOption) (*Breaker, error)
+	Trip(ctx context.Context, in *TripRequest, opts ...grpc.CallOption) (*Breaker, error)
+	// Reset переводит Circuit Breaker в Closed и снимает режим, заданный вручную.
+	Reset(ctx context.Context, in *ResetRequest, opts ...grpc.CallOption) (*Breaker, error)
+	// WatchEvents передает события Circuit Breaker по мере их появления.
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (CircuitBreakerAdmin_WatchEventsClient, error)
+}
+
+type circuitBreakerAdminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCircuitBreakerAdminClient(cc grpc.ClientConnInterface) CircuitBreakerAdminClient {
+	return &circuitBreakerAdminClient{cc}
+}
+
+func (c *circuitBreakerAdminClient) ListBreakers(ctx context.Context, in *ListBreakersRequest, opts ...grpc.CallOption) (*ListBreakersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBreakersResponse)
+	err := c.cc.Invoke(ctx, CircuitBreakerAdmin_ListBreakers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *circuitBreakerAdminClient) GetBreaker(ctx context.Context, in *GetBreakerRequest, opts ...grpc.CallOption) (*Breaker, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Breaker)
+	err := c.cc.Invoke(ctx, CircuitBreakerAdmin_GetBreaker_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *circuitBreakerAdminClient) Trip(ctx context.Context, in *TripRequest, opts ...grpc.CallOption) (*Breaker, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Breaker)
+	err := c.cc.Invoke(ctx, CircuitBreakerAdmin_Trip_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *circuitBreakerAdminClient) Reset(ctx context.Context, in *ResetRequest, opts ...grpc.CallOption) (*Breaker, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Breaker)
+	err := c.cc.Invoke(ctx, CircuitBreakerAdmin_Reset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *circuitBreakerAdminClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (CircuitBreakerAdmin_WatchEventsClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CircuitBreakerAdmin_ServiceDesc.Streams[0], CircuitBreakerAdmin_WatchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &circuitBreakerAdminWatchEventsClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CircuitBreakerAdmin_WatchEventsClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type circuitBreakerAdminWatchEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *circuitBreakerAdminWatchEventsClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CircuitBreakerAdminServer is the server API for CircuitBreakerAdmin service.
+// All implementations must embed UnimplementedCircuitBreakerAdminServer
+// for forward compatibility
+//
+// CircuitBreakerAdmin управляет Circuit Breaker реестра.
+type CircuitBreakerAdminServer interface {
+	ListBreakers(context.Context, *ListBreakersRequest) (*ListBreakersResponse, error)
+	GetBreaker(context.Context, *GetBreakerRequest) (*Breaker, error)
+	Trip(context.Context, *TripRequest) (*Breaker, error)
+	// Reset переводит Circuit Breaker в Closed и снимает режим, заданный вручную.
+	Reset(context.Context, *ResetRequest) (*Breaker, error)
+	// WatchEvents передает события Circuit Breaker по мере их появления.
+	WatchEvents(*WatchEventsRequest, CircuitBreakerAdmin_WatchEventsServer) error
+	mustEmbedUnimplementedCircuitBreakerAdminServer()
+}
+
+// UnimplementedCircuitBreakerAdminServer must be embedded to have forward compatible implementations.
+type UnimplementedCircuitBreakerAdminServer struct {
+}
+
+func (UnimplementedCircuitBreakerAdminServer) ListBreakers(context.Context, *ListBreakersRequest) (*ListBreakersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBreakers not implemented")
+}
+func (UnimplementedCircuitBreakerAdminServer) GetBreaker(context.Context, *GetBreakerRequest) (*Breaker, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBreaker not implemented")
+}
+func (UnimplementedCircuitBreakerAdminServer) Trip(context.Context, *TripRequest) (*Breaker, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Trip not implemented")
+}
+func (UnimplementedCircuitBreakerAdminServer) Reset(context.Context, *ResetRequest) (*Breaker, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reset not implemented")
+}
+func (UnimplementedCircuitBreakerAdminServer) WatchEvents(*WatchEventsRequest, CircuitBreakerAdmin_WatchEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedCircuitBreakerAdminServer) mustEmbedUnimplementedCircuitBreakerAdminServer() {}
+
+// UnsafeCircuitBreakerAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CircuitBreakerAdminServer will
+// result in compilation errors.
+type UnsafeCircuitBreakerAdminServer interface {
+	mustEmbedUnimplementedCircuitBreakerAdminServer()
+}
+
+func RegisterCircuitBreakerAdminServer(s grpc.ServiceRegistrar, srv CircuitBreakerAdminServer) {
+	s.RegisterService(&CircuitBreakerAdmin_ServiceDesc, srv)
+}
+
+func _CircuitBreakerAdmin_ListBreakers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBreakersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CircuitBreakerAdminServer).ListBreakers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CircuitBreakerAdmin_ListBreakers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CircuitBreakerAdminServer).ListBreakers(ctx, req.(*ListBreakersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CircuitBreakerAdmin_GetBreaker_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBreakerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CircuitBreakerAdminServer).GetBreaker(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CircuitBreakerAdmin_GetBreaker_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CircuitBreakerAdminServer).GetBreaker(ctx, req.(*GetBreakerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CircuitBreakerAdmin_Trip_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TripRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CircuitBreakerAdminServer).Trip(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CircuitBreakerAdmin_Trip_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CircuitBreakerAdminServer).Trip(ctx, req.(*TripRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CircuitBreakerAdmin_Reset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CircuitBreakerAdminServer).Reset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CircuitBreakerAdmin_Reset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CircuitBreakerAdminServer).Reset(ctx, req.(*ResetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CircuitBreakerAdmin_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CircuitBreakerAdminServer).WatchEvents(m, &circuitBreakerAdminWatchEventsServer{ServerStream: stream})
+}
+
+type CircuitBreakerAdmin_WatchEventsServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type circuitBreakerAdminWatchEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *circuitBreakerAdminWatchEventsServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// CircuitBreakerAdmin_ServiceDesc is the grpc.ServiceDesc for CircuitBreakerAdmin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CircuitBreakerAdmin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "circuitbreaker.admin.v1.CircuitBreakerAdmin",
+	HandlerType: (*CircuitBreakerAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListBreakers",
+			Handler:    _CircuitBreakerAdmin_ListBreakers_Handler,
+		},
+		{
+			MethodName: "GetBreaker",
+			Handler:    _CircuitBreakerAdmin_GetBreaker_Handler,
+		},
+		{
+			MethodName: "Trip",
+			Handler:    _CircuitBreakerAdmin_Trip_Handler,
+		},
+		{
+			MethodName: "Reset",
+			Handler:    _CircuitBreakerAdmin_Reset_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _CircuitBreakerAdmin_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "adminpb/admin.proto",
+}
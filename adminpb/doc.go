@@ -0,0 +1,4 @@
+// Package adminpb содержит gRPC API управления Circuit Breaker, см. admin.proto.
+package adminpb
+
+//go:generate protoc -I .. --go_out=.. --go_opt=paths=source_relative --go-grpc_out=.. --go-grpc_opt=paths=source_relative adminpb/admin.proto
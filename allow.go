@@ -0,0 +1,69 @@
+package main
+
+import "sync"
+
+// allow допускает запрос, результат которого становится известен не сразу,
+// например поток gRPC. Учет аналогичен Execute: если запрос допущен,
+// результат передается в done, повторные вызовы done игнорируются.
+func (cb *CircuitBreaker) allow() (done func(err error), err error) {
+	mode := cb.mode()
+	if mode == KillSwitchDisabled {
+		return func(error) {}, nil
+	}
+
+	parentDone := func(error) {}
+	if parent := cb.config().parent; parent != nil {
+		if parentMode := parent.mode(); parentMode != KillSwitchDisabled {
+			parentGeneration, err := parent.admit(parentMode)
+			if err != nil {
+				return nil, err
+			}
+			parentDone = func(err error) {
+				if isRejection(err) {
+					parent.cancelRequest(parentGeneration)
+				} else {
+					parent.afterRequest(parentGeneration, err)
+				}
+			}
+		}
+	}
+
+	generation, err := cb.admit(mode)
+	if err != nil {
+		parentDone(err)
+		return nil, err
+	}
+
+	subscribed := cb.subscribed()
+	if subscribed {
+		cb.publish(Event{Type: EventAdmitted, State: cb.State()})
+	}
+
+	s := cb.config()
+	start := s.timeProvider.Now()
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() {
+			cb.recordCall(s, start, err)
+			if subscribed {
+				cb.publishOutcome(err)
+			}
+			cb.afterRequest(generation, err)
+			parentDone(err)
+		})
+	}, nil
+}
+
+// isOpen сообщает, что cb отклонит запрос в состоянии Open, не учитывая отказ.
+// Истекшее состояние Open не считается открытым: следующий запрос переведет
+// Circuit Breaker в Half-Open.
+func (cb *CircuitBreaker) isOpen() bool {
+	switch cb.mode() {
+	case KillSwitchForceOpen:
+		return true
+	case KillSwitchForceClosed, KillSwitchDisabled:
+		return false
+	}
+	current := cb.current.Load()
+	return current.state == StateOpen && !current.expiry.Before(cb.config().timeProvider.Now())
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// AMQPChannel - канал AMQP для публикации сообщений, например *amqp.Channel.
+type AMQPChannel interface {
+	PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+}
+
+// AMQPHandler обрабатывает сообщение, полученное из очереди, и подтверждает его.
+type AMQPHandler func(ctx context.Context, delivery amqp.Delivery) error
+
+type AMQPOption func(*amqpConfig)
+
+type amqpConfig struct {
+	key       func(exchange, routingKey string) string
+	isFailure func(err error) bool
+	reject    func(delivery amqp.Delivery, err error) error
+}
+
+// WithAMQPKey задает ключ Circuit Breaker публикации. По умолчанию AMQPExchangeKey.
+func WithAMQPKey(key func(exchange, routingKey string) string) AMQPOption {
+	return func(c *amqpConfig) {
+		c.key = key
+	}
+}
+
+// WithAMQPFailurePolicy задает, какие ошибки публикации и обработки сообщений
+// считаются неуспешными запросами. По умолчанию DefaultAMQPFailurePolicy.
+func WithAMQPFailurePolicy(isFailure func(err error) bool) AMQPOption {
+	return func(c *amqpConfig) {
+		c.isFailure = isFailure
+	}
+}
+
+// WithAMQPRejection задает действие с сообщением, отклоненным Circuit Breaker
+// обработчика; его ошибка возвращается из обработчика. По умолчанию AMQPRequeue.
+func WithAMQPRejection(reject func(delivery amqp.Delivery, err error) error) AMQPOption {
+	return func(c *amqpConfig) {
+		c.reject = reject
+	}
+}
+
+func newAMQPConfig(options []AMQPOption) *amqpConfig {
+	c := &amqpConfig{key: AMQPExchangeKey, isFailure: DefaultAMQPFailurePolicy, reject: AMQPRequeue}
+	for _, opt := range options {
+		opt(c)
+	}
+	return c
+}
+
+// AMQPExchangeKey возвращает точку обмена, один Circuit Breaker на точку обмена.
+// Для точки обмена по умолчанию возвращается "amq.default".
+func AMQPExchangeKey(exchange, _ string) string {
+	if exchange == "" {
+		return "amq.default"
+	}
+	return exchange
+}
+
+// AMQPRequeue возвращает сообщение в очередь и возвращает err.
+func AMQPRequeue(delivery amqp.Delivery, err error) error {
+	if nackErr := delivery.Nack(false, true); nackErr != nil {
+		return errors.Join(err, nackErr)
+	}
+	return err
+}
+
+// AMQPDeadLetter отклоняет сообщение без возврата в очередь, чтобы оно попало
+// в dead letter exchange очереди, и возвращает err.
+func AMQPDeadLetter(delivery amqp.Delivery, err error) error {
+	if nackErr := delivery.Nack(false, false); nackErr != nil {
+		return errors.Join(err, nackErr)
+	}
+	return err
+}
+
+// DefaultAMQPFailurePolicy считает неуспешными ошибки соединения, в том числе
+// amqp.ErrClosed при публикации в закрытый канал во время переподключения,
+// и ошибки, после которых брокер закрывает соединение. Ошибки канала, вызванные
+// самим сообщением, например NOT_FOUND для неизвестной точки обмена или
+// ACCESS_REFUSED, и отмена контекста вызывающей стороной считаются успешными
+// запросами.
+func DefaultAMQPFailurePolicy(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var amqpErr *amqp.Error
+	if errors.As(err, &amqpErr) {
+		return !amqpErr.Recover
+	}
+	return true
+}
+
+// AMQPPublisher публикует сообщения через Circuit Breaker группы по ключу
+// публикации, см. WithAMQPKey. Публикация, отклоненная Circuit Breaker, не
+// отправляется и завершается ошибкой, оборачивающей ErrOpenState или
+// ErrTooManyRequests.
+type AMQPPublisher struct {
+	channel AMQPChannel
+	group   *Group
+	config  *amqpConfig
+}
+
+func NewAMQPPublisher(channel AMQPChannel, group *Group, options ...AMQPOption) *AMQPPublisher {
+	return &AMQPPublisher{channel: channel, group: group, config: newAMQPConfig(options)}
+}
+
+func (p *AMQPPublisher) PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error {
+	cb := p.group.Get(p.config.key(exchange, key))
+	var called bool
+	var publishErr error
+	_, err := cb.ExecuteContext(ctx, func(ctx context.Context) (interface{}, error) {
+		called = true
+		publishErr = p.channel.PublishWithContext(ctx, exchange, key, mandatory, immediate, msg)
+		return nil, p.config.outcome(publishErr)
+	})
+	if !called {
+		return amqpNotCalled(cb, err)
+	}
+	return publishErr
+}
+
+// AMQPConsumerMiddleware возвращает middleware обработчика сообщений, выполняющее
+// его через cb, например защищающий нижестоящий сервис, в который пишет обработчик.
+// Сообщения, отклоненные cb, не передаются обработчику, см. WithAMQPRejection.
+// Пока cb в состоянии Open, возвращенные в очередь сообщения доставляются снова,
+// поэтому кол-во неподтвержденных сообщений стоит ограничить через Channel.Qos.
+func AMQPConsumerMiddleware(cb *CircuitBreaker, options ...AMQPOption) func(AMQPHandler) AMQPHandler {
+	config := newAMQPConfig(options)
+	return func(next AMQPHandler) AMQPHandler {
+		return func(ctx context.Context, delivery amqp.Delivery) error {
+			var called bool
+			var handleErr error
+			_, err := cb.ExecuteContext(ctx, func(ctx context.Context) (interface{}, error) {
+				called = true
+				handleErr = next(ctx, delivery)
+				return nil, config.outcome(handleErr)
+			})
+			if !called {
+				if isRejection(err) {
+					return config.reject(delivery, amqpNotCalled(cb, err))
+				}
+				return err
+			}
+			return handleErr
+		}
+	}
+}
+
+// outcome возвращает err, если она считается неуспешным запросом, иначе nil.
+func (c *amqpConfig) outcome(err error) error {
+	if err == nil || !c.isFailure(err) {
+		return nil
+	}
+	return err
+}
+
+// amqpNotCalled возвращает ошибку вызова, не выполненного Circuit Breaker cb.
+func amqpNotCalled(cb *CircuitBreaker, err error) error {
+	if isRejection(err) {
+		return fmt.Errorf("circuit breaker %s: %w", cb.Path(), err)
+	}
+	return err
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testAMQPChannel возвращает err на каждую публикацию.
+type testAMQPChannel struct {
+	err       error
+	published []string
+}
+
+func (c *testAMQPChannel) PublishWithContext(_ context.Context, exchange, key string, _, _ bool, _ amqp.Publishing) error {
+	c.published = append(c.published, exchange+"/"+key)
+	return c.err
+}
+
+// testAcknowledger записывает отклоненные сообщения и параметр requeue.
+type testAcknowledger struct {
+	nacked []bool
+}
+
+func (*testAcknowledger) Ack(uint64, bool) error { return nil }
+
+func (a *testAcknowledger) Nack(_ uint64, _ bool, requeue bool) error {
+	a.nacked = append(a.nacked, requeue)
+	return nil
+}
+
+func (*testAcknowledger) Reject(uint64, bool) error { return nil }
+
+func TestAMQPPublisher(t *testing.T) {
+	channel := &testAMQPChannel{}
+	group := tripOnFirstFailure()
+	publisher := NewAMQPPublisher(channel, group)
+	ctx := context.Background()
+
+	channel.err = amqp.ErrClosed
+	assert.ErrorIs(t, publisher.PublishWithContext(ctx, "orders", "created", false, false, amqp.Publishing{}), amqp.ErrClosed)
+	orders := group.Get("orders")
+	assert.Equal(t, StateOpen, orders.State())
+
+	err := publisher.PublishWithContext(ctx, "orders", "created", false, false, amqp.Publishing{})
+	assert.ErrorIs(t, err, ErrOpenState)
+	assert.EqualError(t, err, "circuit breaker orders: "+ErrOpenState.Error())
+
+	notFound := &amqp.Error{Code: amqp.NotFound, Reason: "NOT_FOUND - no exchange 'missing'", Server: true, Recover: true}
+	channel.err = notFound
+	assert.ErrorIs(t, publisher.PublishWithContext(ctx, "missing", "created", false, false, amqp.Publishing{}), notFound)
+	assert.Equal(t, StateClosed, group.Get("missing").State())
+
+	channel.err = nil
+	require.NoError(t, publisher.PublishWithContext(ctx, "", "tasks", false, false, amqp.Publishing{}))
+	assert.Equal(t, uint32(1), group.Get("amq.default").Counts().TotalSuccess)
+	assert.Equal(t, []string{"orders/created", "missing/created", "/tasks"}, channel.published)
+}
+
+func TestAMQPConsumerMiddleware(t *testing.T) {
+	cb := NewCircuitBreaker(WithName("payments"), WithReadyToTrip(func(counts Counts) bool {
+		return counts.ConsecutiveFailures >= 1
+	}))
+	ack := &testAcknowledger{}
+	var handled int
+	handler := AMQPConsumerMiddleware(cb)(func(context.Context, amqp.Delivery) error {
+		handled++
+		return errors.New("payments unavailable")
+	})
+	delivery := amqp.Delivery{Acknowledger: ack, DeliveryTag: 1}
+
+	assert.EqualError(t, handler(context.Background(), delivery), "payments unavailable")
+	assert.Equal(t, StateOpen, cb.State())
+
+	err := handler(context.Background(), delivery)
+	assert.ErrorIs(t, err, ErrOpenState)
+	assert.Equal(t, 1, handled)
+	assert.Equal(t, []bool{true}, ack.nacked)
+
+	deadLetter := AMQPConsumerMiddleware(cb, WithAMQPRejection(AMQPDeadLetter))(func(context.Context, amqp.Delivery) error {
+		return nil
+	})
+	assert.ErrorIs(t, deadLetter(context.Background(), delivery), ErrOpenState)
+	assert.Equal(t, []bool{true, false}, ack.nacked)
+}
+
+func TestDefaultAMQPFailurePolicy(t *testing.T) {
+	assert.True(t, DefaultAMQPFailurePolicy(amqp.ErrClosed))
+	assert.True(t, DefaultAMQPFailurePolicy(&amqp.Error{Code: amqp.ConnectionForced, Reason: "CONNECTION_FORCED - broker forced connection closure", Server: true}))
+	assert.True(t, DefaultAMQPFailurePolicy(context.DeadlineExceeded))
+	assert.False(t, DefaultAMQPFailurePolicy(&amqp.Error{Code: amqp.AccessRefused, Reason: "ACCESS_REFUSED", Server: true, Recover: true}))
+	assert.False(t, DefaultAMQPFailurePolicy(context.Canceled))
+}
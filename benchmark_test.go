@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func benchmarkExecute(b *testing.B, cb *CircuitBreaker) {
+	req := func() (interface{}, error) {
+		return nil, nil
+	}
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = cb.Execute(req)
+		}
+	})
+}
+
+// Успешные запросы в Closed проходят по быстрому пути без блокировки.
+func BenchmarkCircuitBreaker_ExecuteClosed(b *testing.B) {
+	benchmarkExecute(b, NewCircuitBreaker())
+}
+
+// С включенным WithHealthyResetInterval каждый успешный запрос берет блокировку,
+// что позволяет сравнить быстрый путь с путем под блокировкой.
+func BenchmarkCircuitBreaker_ExecuteClosedLocked(b *testing.B) {
+	benchmarkExecute(b, NewCircuitBreaker(WithHealthyResetInterval(time.Hour)))
+}
+
+func BenchmarkCircuitBreaker_ExecuteOpen(b *testing.B) {
+	cb := NewCircuitBreaker(WithTimeout(time.Hour))
+	cb.trip()
+
+	benchmarkExecute(b, cb)
+}
+
+func BenchmarkCircuitBreaker_ExecuteHalfOpen(b *testing.B) {
+	// MaxRequests не будет достигнут, поэтому все запросы проходят в Half-Open
+	cb := NewCircuitBreaker(WithMaxRequests(^uint32(0)))
+	cb.mu.Lock()
+	cb.setState(StateHalfOpen)
+	cb.mu.Unlock()
+
+	benchmarkExecute(b, cb)
+}
+
+func BenchmarkCircuitBreaker_ExecuteClosedFailure(b *testing.B) {
+	cb := NewCircuitBreaker(WithReadyToTrip(func(Counts) bool { return false }))
+	err := errors.New("fail")
+	req := func() (interface{}, error) {
+		return nil, err
+	}
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = cb.Execute(req)
+		}
+	})
+}
+
+func TestCircuitBreaker_ExecuteClosedZeroAllocs(t *testing.T) {
+	cb := NewCircuitBreaker()
+	req := func() (interface{}, error) {
+		return nil, nil
+	}
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		_, _ = cb.Execute(req)
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestCircuitBreaker_ExecuteClosedFailureZeroAllocs(t *testing.T) {
+	cb := NewCircuitBreaker(WithReadyToTrip(func(Counts) bool { return false }))
+	err := errors.New("fail")
+	req := func() (interface{}, error) {
+		return nil, err
+	}
+
+	allocs := testing.AllocsPerRun(1000, func() {
+		_, _ = cb.Execute(req)
+	})
+	assert.Zero(t, allocs)
+}
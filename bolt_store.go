@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var boltBucket = []byte("circuit-breakers")
+
+// BatchStateStore - StateStore, который умеет атомарно сохранять и загружать
+// состояние сразу всех Circuit Breaker реестра.
+type BatchStateStore interface {
+	StateStore
+	SaveAll(snapshots map[string]Snapshot) error
+	LoadAll() (map[string]Snapshot, error)
+}
+
+// BoltStateStore хранит состояние Circuit Breaker во встроенной базе BoltDB.
+// Подходит для реестров с большим кол-вом Circuit Breaker: все состояния
+// хранятся в одном файле и сохраняются одной транзакцией.
+type BoltStateStore struct {
+	db *bolt.DB
+}
+
+func OpenBoltStateStore(path string) (*BoltStateStore, error) {
+	db, err := bolt.Open(path, 0o600, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &BoltStateStore{db: db}, nil
+}
+
+func (s *BoltStateStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *BoltStateStore) Load(name string) (Snapshot, bool, error) {
+	var (
+		snapshot Snapshot
+		ok       bool
+	)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(boltBucket).Get([]byte(name))
+		if data == nil {
+			return nil
+		}
+		ok = true
+		return json.Unmarshal(data, &snapshot)
+	})
+
+	return snapshot, ok, err
+}
+
+func (s *BoltStateStore) Save(name string, snapshot Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Put([]byte(name), data)
+	})
+}
+
+// SaveAll сохраняет все состояния одной транзакцией.
+func (s *BoltStateStore) SaveAll(snapshots map[string]Snapshot) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltBucket)
+		for name, snapshot := range snapshots {
+			data, err := json.Marshal(snapshot)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(name), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *BoltStateStore) LoadAll() (map[string]Snapshot, error) {
+	snapshots := make(map[string]Snapshot)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).ForEach(func(name, data []byte) error {
+			var snapshot Snapshot
+			if err := json.Unmarshal(data, &snapshot); err != nil {
+				return err
+			}
+			snapshots[string(name)] = snapshot
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
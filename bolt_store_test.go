@@ -0,0 +1,55 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoltStateStore(t *testing.T) {
+	store, err := OpenBoltStateStore(filepath.Join(t.TempDir(), "state.db"))
+	require.NoError(t, err)
+	defer store.Close()
+
+	_, ok, err := store.Load("payments")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	snapshot := Snapshot{State: StateHalfOpen, Counts: Counts{1, 1, 0, 1, 0}, Generation: 2}
+	require.NoError(t, store.Save("payments", snapshot))
+
+	loaded, ok, err := store.Load("payments")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, snapshot, loaded)
+}
+
+func TestRegistry_PersistRestore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+	store, err := OpenBoltStateStore(path)
+	require.NoError(t, err)
+
+	r := NewRegistry(WithDefaults(WithTimeout(time.Hour)))
+	r.Get("payments").trip()
+	assert.Nil(t, succeed(r.Get("orders")))
+	require.NoError(t, r.Persist(store))
+	require.NoError(t, store.Close())
+
+	store, err = OpenBoltStateStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	snapshots, err := store.LoadAll()
+	require.NoError(t, err)
+	assert.Len(t, snapshots, 2)
+
+	restarted := NewRegistry(WithDefaults(WithTimeout(time.Hour)))
+	orders := restarted.Get("orders")
+	require.NoError(t, restarted.Restore(store))
+
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, orders.Counts())
+	assert.Equal(t, StateOpen, restarted.Get("payments").State())
+}
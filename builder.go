@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidConfig = errors.New("invalid circuit breaker config")
+
+// Builder - альтернативный функциональным опциям способ настройки Circuit Breaker
+// с проверкой настроек при вызове Build.
+type Builder struct {
+	options []Option
+}
+
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+func (b *Builder) SetName(name string) *Builder {
+	return b.With(WithName(name))
+}
+
+func (b *Builder) SetTimeout(timeout time.Duration) *Builder {
+	return b.With(WithTimeout(timeout))
+}
+
+func (b *Builder) SetMaxRequests(maxRequests uint32) *Builder {
+	return b.With(WithMaxRequests(maxRequests))
+}
+
+func (b *Builder) SetTripStrategy(readyToTrip func(counts Counts) bool) *Builder {
+	return b.With(WithReadyToTrip(readyToTrip))
+}
+
+func (b *Builder) SetOpenDurationBounds(min, max time.Duration) *Builder {
+	return b.With(WithMinOpenDuration(min), WithMaxOpenDuration(max))
+}
+
+func (b *Builder) SetHealthyResetInterval(d time.Duration) *Builder {
+	return b.With(WithHealthyResetInterval(d))
+}
+
+func (b *Builder) SetWarmup(period time.Duration, readyToTrip func(counts Counts) bool) *Builder {
+	return b.With(WithWarmup(period, readyToTrip))
+}
+
+func (b *Builder) SetLabels(labels map[string]string) *Builder {
+	return b.With(WithLabels(labels))
+}
+
+func (b *Builder) SetTimeProvider(timeProvider TimeProvider) *Builder {
+	return b.With(WithTimeProvider(timeProvider))
+}
+
+// With добавляет произвольные опции, для которых нет отдельного метода.
+func (b *Builder) With(options ...Option) *Builder {
+	b.options = append(b.options, options...)
+	return b
+}
+
+// Build проверяет настройки и создает Circuit Breaker.
+func (b *Builder) Build() (*CircuitBreaker, error) {
+	s, err := validSettings(b.options)
+	if err != nil {
+		return nil, err
+	}
+	return newCircuitBreaker(s), nil
+}
+
+// validSettings применяет options к настройкам по умолчанию и проверяет результат.
+func validSettings(options []Option) (*settings, error) {
+	s := defaultSettings()
+	for _, opt := range options {
+		opt(s)
+	}
+
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// validate проверяет согласованность настроек.
+func (s *settings) validate() error {
+	var errs []error
+	if s.maxRequests == 0 {
+		errs = append(errs, errors.New("max requests must be positive"))
+	}
+	if s.timeout <= 0 {
+		errs = append(errs, errors.New("timeout must be positive"))
+	}
+	if s.readyToTrip == nil {
+		errs = append(errs, errors.New("trip strategy is required"))
+	}
+	if s.timeProvider == nil {
+		errs = append(errs, errors.New("time provider is required"))
+	}
+	if s.minOpenDuration < 0 || s.maxOpenDuration < 0 {
+		errs = append(errs, errors.New("open duration bounds must not be negative"))
+	}
+	if s.minOpenDuration > 0 && s.maxOpenDuration > 0 && s.minOpenDuration > s.maxOpenDuration {
+		errs = append(errs, fmt.Errorf("min open duration %s exceeds max open duration %s", s.minOpenDuration, s.maxOpenDuration))
+	}
+	if s.healthyResetInterval < 0 || s.warmupPeriod < 0 || s.killSwitchPollInterval < 0 || s.logSummaryInterval < 0 {
+		errs = append(errs, errors.New("intervals must not be negative"))
+	}
+	if s.healthCheck != nil && s.healthCheckInterval <= 0 {
+		errs = append(errs, errors.New("health check interval must be positive"))
+	}
+	if s.halfOpenProbe != nil && s.halfOpenProbeInterval <= 0 {
+		errs = append(errs, errors.New("half-open probe interval must be positive"))
+	}
+	if s.historySize < 0 {
+		errs = append(errs, errors.New("history size must not be negative"))
+	}
+	if s.chaosFailureRate < 0 || s.chaosFailureRate > 1 {
+		errs = append(errs, fmt.Errorf("chaos failure rate %v is out of range [0, 1]", s.chaosFailureRate))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder_Build(t *testing.T) {
+	cb, err := NewBuilder().
+		SetName("payments").
+		SetTimeout(time.Minute).
+		SetMaxRequests(2).
+		SetTripStrategy(func(counts Counts) bool {
+			return counts.ConsecutiveFailures >= 2
+		}).
+		SetOpenDurationBounds(time.Second, time.Hour).
+		Build()
+	require.NoError(t, err)
+
+	assert.Equal(t, "payments", cb.Name())
+	assert.Equal(t, time.Minute, cb.openDuration(nil))
+
+	assert.NotNil(t, fail(cb))
+	assert.NotNil(t, fail(cb))
+	assert.Equal(t, StateOpen, cb.State())
+}
+
+func TestBuilder_Validation(t *testing.T) {
+	_, err := NewBuilder().
+		SetMaxRequests(0).
+		SetTimeout(-time.Second).
+		SetOpenDurationBounds(time.Hour, time.Minute).
+		With(WithChaos(2, 0)).
+		With(WithHealthCheck(func(context.Context) error { return nil }, 0)).
+		With(WithHalfOpenProbe(func(context.Context) error { return nil }, -time.Second)).
+		Build()
+
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+	assert.ErrorContains(t, err, "max requests must be positive")
+	assert.ErrorContains(t, err, "timeout must be positive")
+	assert.ErrorContains(t, err, "min open duration 1h0m0s exceeds max open duration 1m0s")
+	assert.ErrorContains(t, err, "chaos failure rate 2 is out of range")
+	assert.ErrorContains(t, err, "health check interval must be positive")
+	assert.ErrorContains(t, err, "half-open probe interval must be positive")
+}
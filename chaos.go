@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+var ErrChaosInjected = errors.New("chaos: injected failure")
+
+// WithChaos включает внесение хаоса в запросы, прошедшие через Circuit Breaker:
+// с вероятностью failureRate (0..1) запрос не выполняется и завершается ErrChaosInjected,
+// а к остальным добавляется случайная задержка до extraLatency.
+// Предназначено для проверки fallback-логики и алертинга вне production.
+func WithChaos(failureRate float64, extraLatency time.Duration) Option {
+	return func(s *settings) {
+		s.chaosFailureRate = failureRate
+		s.chaosLatency = extraLatency
+	}
+}
+
+// call выполняет запрос с учетом настроек хаоса и записывает его результат.
+func (cb *CircuitBreaker) call(req Request) (interface{}, error) {
+	s := cb.config()
+	if s.outcomeRecorder == nil && len(s.observers) == 0 {
+		return cb.callWithChaos(s, req)
+	}
+
+	start := s.timeProvider.Now()
+	response, err := cb.callWithChaos(s, req)
+	cb.recordCall(s, start, err)
+
+	return response, err
+}
+
+// recordCall передает результат запроса, начатого в start, в OutcomeRecorder и наблюдателям.
+func (cb *CircuitBreaker) recordCall(s *settings, start time.Time, err error) {
+	if s.outcomeRecorder == nil && len(s.observers) == 0 {
+		return
+	}
+
+	record := OutcomeRecord{At: start, Duration: s.timeProvider.Now().Sub(start)}
+	if err != nil {
+		record.Outcome = OutcomeFailure
+	}
+	if s.outcomeRecorder != nil {
+		s.outcomeRecorder.Record(record)
+	}
+	for _, o := range s.observers {
+		o.observeCall(cb, record)
+	}
+}
+
+func (cb *CircuitBreaker) callWithChaos(s *settings, req Request) (interface{}, error) {
+	if s.chaosFailureRate > 0 && rand.Float64() < s.chaosFailureRate {
+		return nil, ErrChaosInjected
+	}
+	if s.chaosLatency > 0 {
+		time.Sleep(rand.N(s.chaosLatency))
+	}
+
+	return req()
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker_ChaosFailures(t *testing.T) {
+	cb := NewCircuitBreaker(
+		WithChaos(1, 0),
+		WithReadyToTrip(func(counts Counts) bool {
+			return counts.ConsecutiveFailures >= 3
+		}),
+	)
+
+	called := false
+	for i := 0; i < 3; i++ {
+		_, err := cb.Execute(func() (interface{}, error) {
+			called = true
+			return nil, nil
+		})
+		assert.ErrorIs(t, err, ErrChaosInjected)
+	}
+
+	// внесенные ошибки учитываются как обычные и открывают Circuit Breaker
+	assert.False(t, called)
+	assert.Equal(t, StateOpen, cb.State())
+}
+
+func TestCircuitBreaker_ChaosLatency(t *testing.T) {
+	cb := NewCircuitBreaker(WithChaos(0, 10*time.Millisecond))
+
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, succeed(cb))
+	}
+	assert.Equal(t, uint32(5), cb.Counts().TotalSuccess)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Checker - общий интерфейс проверки работоспособности, совместимый
+// с распространенными health-фреймворками.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckError - ошибка проверки работоспособности Circuit Breaker,
+// находящегося не в состоянии Closed.
+type CheckError struct {
+	Name  string
+	State State
+	// Ошибка последнего неуспешного запроса, если он был.
+	LastError error
+}
+
+func (e *CheckError) Error() string {
+	msg := fmt.Sprintf("circuit breaker %s is %s", e.Name, e.State)
+	if e.LastError != nil {
+		msg += ": " + e.LastError.Error()
+	}
+	return msg
+}
+
+func (e *CheckError) Unwrap() error {
+	return e.LastError
+}
+
+// Check возвращает *CheckError, если Circuit Breaker в состоянии Open или Half-Open,
+// то есть защищаемая зависимость недоступна или еще не восстановилась.
+func (cb *CircuitBreaker) Check(context.Context) error {
+	state := cb.State()
+	if state == StateClosed {
+		return nil
+	}
+	return &CheckError{Name: cb.Path(), State: state, LastError: cb.LastError()}
+}
+
+// Check возвращает ошибки проверки всех Circuit Breaker реестра в порядке имен.
+func (r *Registry) Check(ctx context.Context) error {
+	var errs []error
+	r.Range(func(_ string, cb *CircuitBreaker) bool {
+		if err := cb.Check(ctx); err != nil {
+			errs = append(errs, err)
+		}
+		return true
+	})
+	return errors.Join(errs...)
+}
+
+// Checks возвращает результаты проверки Circuit Breaker реестра по именам.
+func (r *Registry) Checks(ctx context.Context) map[string]error {
+	results := make(map[string]error)
+	r.Range(func(name string, cb *CircuitBreaker) bool {
+		results[name] = cb.Check(ctx)
+		return true
+	})
+	return results
+}
+
+// AggregateCheckers возвращает Checker, выполняющий все checkers
+// и объединяющий их ошибки.
+func AggregateCheckers(checkers ...Checker) Checker {
+	return aggregateChecker(checkers)
+}
+
+type aggregateChecker []Checker
+
+func (a aggregateChecker) Check(ctx context.Context) error {
+	var errs []error
+	for _, checker := range a {
+		if err := checker.Check(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker_Check(t *testing.T) {
+	ctx := context.Background()
+	cb := NewCircuitBreaker(
+		WithName("payments"),
+		WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures > 0 }),
+	)
+	assert.NoError(t, cb.Check(ctx))
+
+	assert.NotNil(t, fail(cb))
+	err := cb.Check(ctx)
+	assert.EqualError(t, err, "circuit breaker payments is open: fail")
+
+	var checkErr *CheckError
+	assert.True(t, errors.As(err, &checkErr))
+	assert.Equal(t, StateOpen, checkErr.State)
+	assert.EqualError(t, errors.Unwrap(err), "fail")
+
+	var _ Checker = cb
+}
+
+func TestRegistry_Check(t *testing.T) {
+	ctx := context.Background()
+	r := NewRegistry()
+	r.Get("search")
+	assert.NoError(t, r.Check(ctx))
+
+	r.Get("payments").trip()
+	r.Get("orders").trip()
+	assert.EqualError(t, r.Check(ctx), "circuit breaker orders is open\ncircuit breaker payments is open")
+
+	checks := r.Checks(ctx)
+	assert.Len(t, checks, 3)
+	assert.NoError(t, checks["search"])
+	assert.EqualError(t, checks["payments"], "circuit breaker payments is open")
+}
+
+func TestAggregateCheckers(t *testing.T) {
+	ctx := context.Background()
+	healthy := NewCircuitBreaker(WithName("search"))
+	open := NewCircuitBreaker(WithName("payments"))
+	open.trip()
+
+	assert.NoError(t, AggregateCheckers(healthy).Check(ctx))
+	assert.NoError(t, AggregateCheckers().Check(ctx))
+	assert.EqualError(t, AggregateCheckers(healthy, open).Check(ctx), "circuit breaker payments is open")
+}
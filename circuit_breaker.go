@@ -1,18 +1,25 @@
 package main
 
 import (
+	"context"
 	"errors"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/raymanovg/circuit-breaker/clock"
 )
 
 type TimeProvider interface {
 	Now() time.Time
 }
 
-type RealTimeTimeProvider struct{}
+// Clock - источник времени с таймерами. Если TimeProvider реализует Clock,
+// его таймеры используются и для фоновых задач Circuit Breaker.
+type Clock = clock.Clock
 
-func (RealTimeTimeProvider) Now() time.Time {
-	return time.Now()
+type RealTimeTimeProvider struct {
+	clock.Real
 }
 
 type State int
@@ -24,50 +31,115 @@ const (
 )
 
 var (
-	ErrTooManyRequests = errors.New("too many requests")
-	ErrOpenState       = errors.New("state is open")
+	ErrTooManyRequests  = errors.New("too many requests")
+	ErrOpenState        = errors.New("state is open")
+	ErrResourcePressure = errors.New("process is under resource pressure")
 )
 
-type Option func(*CircuitBreaker)
+type Option func(*settings)
 
 func WithTimeout(timeout time.Duration) Option {
-	return func(cb *CircuitBreaker) {
-		cb.timeout = timeout
+	return func(s *settings) {
+		s.timeout = timeout
 	}
 }
 
 func WithMaxRequests(maxRequests uint32) Option {
-	return func(cb *CircuitBreaker) {
-		cb.maxRequests = maxRequests
+	return func(s *settings) {
+		s.maxRequests = maxRequests
 	}
 }
 
 func WithReadyToTrip(readyToTrip func(counts Counts) bool) Option {
-	return func(cb *CircuitBreaker) {
-		cb.readyToTrip = readyToTrip
+	return func(s *settings) {
+		s.readyToTrip = readyToTrip
 	}
 }
 
 func WithTimeProvider(timeProvider TimeProvider) Option {
-	return func(cb *CircuitBreaker) {
-		cb.timeProvider = timeProvider
+	return func(s *settings) {
+		s.timeProvider = timeProvider
+	}
+}
+
+// WithClock задает общий источник времени для переходов между состояниями
+// и фоновых проверок.
+func WithClock(c Clock) Option {
+	return WithTimeProvider(c)
+}
+
+// WithMinOpenDuration задает нижнюю границу периода нахождения в состоянии Open.
+func WithMinOpenDuration(d time.Duration) Option {
+	return func(s *settings) {
+		s.minOpenDuration = d
+	}
+}
+
+// WithMaxOpenDuration задает верхнюю границу периода нахождения в состоянии Open.
+func WithMaxOpenDuration(d time.Duration) Option {
+	return func(s *settings) {
+		s.maxOpenDuration = d
+	}
+}
+
+// WithHealthyResetInterval включает полный сброс счетчиков в состоянии Closed,
+// если за указанный период не было ни одной ошибки.
+func WithHealthyResetInterval(d time.Duration) Option {
+	return func(s *settings) {
+		s.healthyResetInterval = d
+	}
+}
+
+// WithShedOnQueueDepth задает стратегию, по которой Circuit Breaker
+// упреждающе переходит в Open, получив глубину очереди через ReportQueueDepth.
+func WithShedOnQueueDepth(shed func(depth int64) bool) Option {
+	return func(s *settings) {
+		s.shedOnQueueDepth = shed
 	}
 }
 
 func NewCircuitBreaker(options ...Option) *CircuitBreaker {
-	cb := &CircuitBreaker{
-		state:       StateClosed,
+	s := defaultSettings()
+	for _, opt := range options {
+		opt(s)
+	}
+
+	return newCircuitBreaker(s)
+}
+
+func defaultSettings() *settings {
+	return &settings{
 		maxRequests: 5,
 		timeout:     10 * time.Second,
 		readyToTrip: func(counts Counts) bool {
 			return counts.ConsecutiveFailures > 5
 		},
-		counts:       Counts{},
-		timeProvider: &RealTimeTimeProvider{},
+		timeProvider:          &RealTimeTimeProvider{},
+		notificationQueueSize: defaultNotificationQueueSize,
+	}
+}
+
+func newCircuitBreaker(s *settings) *CircuitBreaker {
+	cb := &CircuitBreaker{
+		counts:    newShardedCounts(),
+		createdAt: s.timeProvider.Now(),
 	}
+	cb.lifetime, cb.cancel = context.WithCancel(context.Background())
+	cb.settings.Store(s)
+	cb.current.Store(&stateSnapshot{state: StateClosed, since: cb.createdAt})
 
-	for _, opt := range options {
-		opt(cb)
+	if s.parent != nil {
+		s.parent.addChild(cb)
+	}
+	cb.loadState()
+	if s.configProvider != nil {
+		cb.startConfigProvider()
+	}
+	if s.killSwitch != nil && s.killSwitchPollInterval > 0 {
+		cb.startKillSwitchPolling(s)
+	}
+	if s.logger != nil && s.logSummaryInterval > 0 {
+		cb.startLogSummary(s)
 	}
 
 	return cb
@@ -76,7 +148,21 @@ func NewCircuitBreaker(options ...Option) *CircuitBreaker {
 type (
 	Request func() (interface{}, error)
 
-	CircuitBreaker struct {
+	// stateSnapshot - неизменяемое описание текущего состояния.
+	// Заменяется целиком при каждой смене состояния.
+	stateSnapshot struct {
+		state State
+		// Номер состояния. Увеличивается при каждой смене состояния,
+		// чтобы результаты запросов, начатых в прошлом состоянии, не учитывались.
+		generation uint64
+		expiry     time.Time
+		// Время перехода в состояние.
+		since time.Time
+	}
+
+	// settings - неизменяемый снимок настроек Circuit Breaker.
+	// При изменении настроек через UpdateConfig заменяется целиком.
+	settings struct {
 		// Максимальное кол-во запросов которые может пропустить через себя Circuit Breaker
 		// пока находится в состоянии Half-Open.
 		maxRequests uint32
@@ -88,67 +174,527 @@ type (
 		//   return counts.ConsecutiveFailures > 5
 		// }
 		readyToTrip func(counts Counts) bool
+		// Границы периода нахождения в состоянии Open. Нулевое значение - без ограничения.
+		minOpenDuration time.Duration
+		maxOpenDuration time.Duration
+		// Период без ошибок в состоянии Closed, после которого счетчики сбрасываются.
+		// Нулевое значение - сброс отключен.
+		healthyResetInterval time.Duration
+		// Проверка ресурсов процесса перед выполнением запроса.
+		resourceProbe ResourceProbe
+		overloaded    func(usage ResourceUsage) bool
+		// Стратегия упреждающего перехода в Open по глубине очереди приложения.
+		shedOnQueueDepth func(depth int64) bool
+		// Фоновая проверка доступности в состоянии Open.
+		healthCheck         HealthCheck
+		healthCheckInterval time.Duration
+		// Синтетический запрос для оценки восстановления в состоянии Half-Open.
+		halfOpenProbe         HealthCheck
+		halfOpenProbeInterval time.Duration
+		// Общее колесо таймеров для перехода из Open в Half-Open.
+		timerWheel *TimerWheel
+		// Обработчик смены состояния и ограничения очереди уведомлений.
+		onStateChange         func(change StateChange)
+		notificationQueueSize int
+		notificationInterval  time.Duration
+		// Внесение искусственных ошибок и задержек в запросы.
+		chaosFailureRate float64
+		chaosLatency     time.Duration
+		// Получатель результатов всех выполненных запросов.
+		outcomeRecorder OutcomeRecorder
+		// Имя и положение в иерархии Circuit Breaker.
+		name               string
+		parent             *CircuitBreaker
+		childTripThreshold int
+		// Произвольные метки. Не изменяются после создания настроек.
+		labels map[string]string
+		// Хранилище состояния между перезапусками.
+		stateStore        StateStore
+		onStateStoreError func(err error)
+		// Период прогрева после создания и стратегия перехода в Open на это время.
+		warmupPeriod      time.Duration
+		warmupReadyToTrip func(counts Counts) bool
+		// Внешний источник настроек.
+		configProvider ConfigProvider
+		onConfigError  func(err error)
+		// Журнал событий Circuit Breaker и экспорт метрик.
+		logger             Logger
+		logSummaryInterval time.Duration
+		observers          []observer
+		traceAnnotations   bool
+		eventBus           *EventBus
+		historySize        int
+		// Принудительное управление режимом.
+		killSwitch             KillSwitch
+		killSwitchPollInterval time.Duration
+		// Метки pprof для горутин, выполняющих Execute.
+		pprofLabels bool
 
-		state        State
-		counts       Counts
-		expiry       time.Time
 		timeProvider TimeProvider
 	}
+
+	CircuitBreaker struct {
+		settings atomic.Pointer[settings]
+
+		// Блокировка берется только при смене состояния. В состоянии Closed
+		// успешные запросы учитываются без блокировки.
+		mu      sync.Mutex
+		current atomic.Pointer[stateSnapshot]
+		counts  shardedCounts
+		// Счетчики за все время жизни, не сбрасываются при смене состояния.
+		totals totals
+		// Режим KillSwitch, полученный последним опросом.
+		killSwitch killSwitchState
+		// Отклоненные запросы, еще не записанные в лог.
+		rejectionLog rejectionLog
+		// Подписчики на события, см. Subscribe.
+		subscriptions subscriptions
+		// Последние события, см. WithHistory.
+		history history
+
+		notifier  notifier
+		persister persister
+		// Синхронный обработчик переходов для внутреннего использования, например в Simulate.
+		onTransition func(change StateChange)
+		// Дочерние Circuit Breaker, зарегистрированные через WithParent.
+		children []*CircuitBreaker
+
+		// Момент создания, от которого отсчитывается период прогрева.
+		createdAt time.Time
+
+		// Контекст жизни Circuit Breaker, отменяется в Close,
+		// и фоновые горутины проверок доступности.
+		lifetime   context.Context
+		cancel     context.CancelFunc
+		background sync.WaitGroup
+		// Фоновые опросы, зависящие от настроек. Защищены mu.
+		pollers pollers
+		// Последняя глубина очереди, переданная через ReportQueueDepth.
+		queueDepth int64
+		// Момент начала текущей серии успешных запросов в состоянии Closed.
+		healthySince time.Time
+		// Причина и ошибка запроса, приводящие к смене состояния. Передаются в StateChange.
+		reason TransitionReason
+		cause  error
+		// Последние переходы, см. Transitions.
+		audit transitionLog
+		// Время нахождения в состояниях, см. TimeInState.
+		stateTimes stateTimes
+		// Отклоненные запросы по часам, см. Report.
+		rejectionBuckets rejectionBuckets
+		// Длительности последних запросов, см. WithLatencyStats.
+		latency latencySamples
+	}
 )
 
+func (cb *CircuitBreaker) config() *settings {
+	return cb.settings.Load()
+}
+
+// clock возвращает часы Circuit Breaker. Если TimeProvider не реализует Clock,
+// таймеры берутся из системных часов.
+func (cb *CircuitBreaker) clock() Clock {
+	tp := cb.config().timeProvider
+	if c, ok := tp.(Clock); ok {
+		return c
+	}
+	return providerClock{TimeProvider: tp}
+}
+
+type providerClock struct {
+	TimeProvider
+	clock.Real
+}
+
+func (c providerClock) Now() time.Time {
+	return c.TimeProvider.Now()
+}
+
+// UpdateConfig применяет опции к копии текущих настроек и атомарно заменяет их.
+// Состояние и счетчики сохраняются, новые настройки действуют для последующих запросов.
+// Опрос KillSwitch и сводка в лог перезапускаются, если меняется их интервал.
+func (cb *CircuitBreaker) UpdateConfig(options ...Option) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	s := *cb.config()
+	for _, opt := range options {
+		opt(&s)
+	}
+	s.parent = cb.config().parent
+
+	cb.restartPollers(cb.config(), &s)
+	cb.settings.Store(&s)
+}
+
+// replaceConfig атомарно заменяет настройки целиком, сохраняя состояние,
+// счетчики и место в иерархии.
+func (cb *CircuitBreaker) replaceConfig(s *settings) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	s.parent = cb.config().parent
+	cb.restartPollers(cb.config(), s)
+	cb.settings.Store(s)
+}
+
+// pollers хранит отмену фоновых опросов, запущенных по настройкам.
+type pollers struct {
+	killSwitch context.CancelFunc
+	logSummary context.CancelFunc
+}
+
+// restartPollers останавливает и запускает фоновые опросы KillSwitch
+// и сводки в лог, если их интервал изменяется при замене настроек prev на s.
+// Вызывается под mu до сохранения s. После Close опросы не запускаются.
+func (cb *CircuitBreaker) restartPollers(prev, s *settings) {
+	pollInterval := func(s *settings) time.Duration {
+		if s.killSwitch == nil {
+			return 0
+		}
+		return s.killSwitchPollInterval
+	}
+	if pollInterval(prev) != pollInterval(s) {
+		if cb.pollers.killSwitch != nil {
+			cb.pollers.killSwitch()
+			cb.pollers.killSwitch = nil
+		}
+		if pollInterval(s) > 0 && cb.lifetime.Err() == nil {
+			cb.startKillSwitchPolling(s)
+		}
+	}
+
+	summaryInterval := func(s *settings) time.Duration {
+		if s.logger == nil {
+			return 0
+		}
+		return s.logSummaryInterval
+	}
+	if summaryInterval(prev) != summaryInterval(s) {
+		if cb.pollers.logSummary != nil {
+			cb.pollers.logSummary()
+			cb.pollers.logSummary = nil
+		}
+		if summaryInterval(s) > 0 && cb.lifetime.Err() == nil {
+			cb.startLogSummary(s)
+		}
+	}
+}
+
+// retryAfter - ошибка, сообщающая, через сколько зависимость просит повторить запрос,
+// например StatusError с заголовком Retry-After.
+type retryAfter interface {
+	RetryAfter() time.Duration
+}
+
+// openDuration возвращает период нахождения в состоянии Open
+// с учетом ограничений minOpenDuration и maxOpenDuration.
+// Если причина перехода cause сообщает RetryAfter, он используется вместо timeout.
+func (cb *CircuitBreaker) openDuration(cause error) time.Duration {
+	s := cb.config()
+
+	d := s.timeout
+	var ra retryAfter
+	if errors.As(cause, &ra) && ra.RetryAfter() > 0 {
+		d = ra.RetryAfter()
+	}
+	if s.minOpenDuration > 0 && d < s.minOpenDuration {
+		d = s.minOpenDuration
+	}
+	if s.maxOpenDuration > 0 && d > s.maxOpenDuration {
+		d = s.maxOpenDuration
+	}
+	return d
+}
+
+// resetIfHealthy сбрасывает накопленную историю ошибок, если в состоянии Closed
+// не было ошибок дольше healthyResetInterval.
+func (cb *CircuitBreaker) resetIfHealthy() {
+	s := cb.config()
+	if s.healthyResetInterval <= 0 {
+		return
+	}
+
+	now := s.timeProvider.Now()
+	if cb.healthySince.IsZero() {
+		cb.healthySince = now
+		return
+	}
+	if now.Sub(cb.healthySince) >= s.healthyResetInterval {
+		cb.counts.clear()
+		cb.healthySince = now
+	}
+}
+
+// setState переводит Circuit Breaker в новое состояние,
+// сбрасывая счетчики и выставляя срок нахождения в состоянии Open.
+// Вызывается под блокировкой cb.mu.
+func (cb *CircuitBreaker) setState(state State) {
+	prev := cb.current.Load()
+	cause, reason := cb.cause, cb.reason
+	cb.cause, cb.reason = nil, ""
+	now := cb.config().timeProvider.Now()
+
+	next := &stateSnapshot{
+		state:      state,
+		generation: prev.generation + 1,
+		since:      prev.since,
+	}
+	if prev.state != state {
+		next.since = now
+		cb.stateTimes.record(stateSpan{state: prev.state, from: prev.since, to: now})
+	}
+	if state == StateOpen {
+		next.expiry = now.Add(cb.openDuration(cause))
+	}
+
+	// счетчики прошлого состояния попадают в описание перехода
+	counts := cb.counts.snapshot()
+
+	cb.counts.clear()
+	cb.current.Store(next)
+
+	if prev.state != state {
+		cb.totals.countTransition(prev.state, state)
+		change := StateChange{
+			Name:   cb.Path(),
+			Labels: cb.config().labels,
+			From:   prev.state,
+			To:     state,
+			At:     now,
+			Reason: reason,
+			Counts: counts,
+			Err:    cause,
+		}
+		if cb.onTransition != nil {
+			cb.onTransition(change)
+		}
+		cb.audit.record(change)
+		cb.notifyStateChange(change)
+		cb.persistState()
+		cb.log(LogInfo, "state changed", "from", prev.state.String(), "to", state.String(), "counts", counts)
+		for _, o := range cb.config().observers {
+			o.observeTransition(cb, change)
+		}
+		cb.publish(Event{Type: EventStateChanged, At: now, State: state, Change: change})
+
+		if parent := cb.config().parent; parent != nil && state == StateOpen {
+			parent.onChildOpen()
+		}
+	}
+
+	cb.startStateTimers(next)
+}
+
+// startStateTimers запускает фоновые задачи, связанные с состоянием current.
+func (cb *CircuitBreaker) startStateTimers(current *stateSnapshot) {
+	if cb.lifetime.Err() != nil {
+		return
+	}
+
+	switch current.state {
+	case StateOpen:
+		s := cb.config()
+		if s.timerWheel != nil {
+			s.timerWheel.schedule(cb, current.generation, current.expiry)
+		}
+		if s.healthCheck != nil && s.healthCheckInterval > 0 {
+			cb.startHealthCheck(current.generation)
+		}
+	case StateHalfOpen:
+		if cb.probesHalfOpen() {
+			cb.startHalfOpenProbe(current.generation)
+		}
+	}
+}
+
+// trip принудительно переводит Circuit Breaker в состояние Open.
+func (cb *CircuitBreaker) trip() {
+	cb.tripFor(ReasonManual)
+}
+
+func (cb *CircuitBreaker) tripFor(reason TransitionReason) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.current.Load().state != StateOpen {
+		cb.transition(StateOpen, reason, nil)
+	}
+}
+
+func (cb *CircuitBreaker) State() State {
+	return cb.current.Load().state
+}
+
+func (cb *CircuitBreaker) Counts() Counts {
+	return cb.counts.snapshot()
+}
+
 func (cb *CircuitBreaker) onSuccess() {
-	switch cb.state {
+	cb.totals.successes.Add(1)
+
+	switch cb.current.Load().state {
 	case StateClosed:
 		cb.counts.onSuccess()
+		cb.resetIfHealthy()
 	case StateHalfOpen:
 		cb.counts.onSuccess()
-		if cb.counts.ConsecutiveSuccesses >= cb.maxRequests {
-			cb.state = StateClosed
-			cb.counts.clear()
-			cb.expiry = time.Time{}
+		if cb.counts.consecutiveSuccesses() >= cb.config().maxRequests {
+			cb.transition(StateClosed, ReasonRecovered, nil)
 		}
 	}
 }
 
-func (cb *CircuitBreaker) onFailure() {
-	switch cb.state {
+func (cb *CircuitBreaker) onFailure(err error) {
+	cb.totals.failures.Add(1)
+	cb.totals.lastFailure.store(err, cb.config().timeProvider.Now())
+	cb.totals.errors.record(err)
+
+	switch cb.current.Load().state {
 	case StateClosed:
 		cb.counts.onFailure()
-		if cb.readyToTrip(cb.counts) {
-			cb.expiry = cb.timeProvider.Now().Add(cb.timeout)
-			cb.state = StateOpen
-			cb.counts.clear()
+		cb.healthySince = time.Time{}
+		if cb.shouldTrip(cb.counts.snapshot()) {
+			cb.transition(StateOpen, ReasonTripStrategy, err)
 		}
 	case StateHalfOpen:
-		cb.expiry = cb.timeProvider.Now().Add(cb.timeout)
-		cb.state = StateOpen
-		cb.counts.clear()
+		cb.transition(StateOpen, ReasonHalfOpenFailure, err)
 	}
 }
 
 func (cb *CircuitBreaker) Execute(req Request) (interface{}, error) {
-	if cb.state == StateOpen && cb.expiry.Before(cb.timeProvider.Now()) {
-		cb.state = StateHalfOpen
-		cb.expiry = time.Time{}
-		cb.counts.clear()
+	if cb.config().pprofLabels {
+		return cb.executeLabeled(context.Background(), func(context.Context) (interface{}, error) {
+			return cb.run(req)
+		})
+	}
+	return cb.run(req)
+}
+
+// run выполняет req с учетом принудительного режима и родителя.
+func (cb *CircuitBreaker) run(req Request) (interface{}, error) {
+	mode := cb.mode()
+	if mode == KillSwitchDisabled {
+		return req()
+	}
+
+	parent := cb.config().parent
+	if parent == nil {
+		return cb.execute(req, mode)
+	}
+
+	parentMode := parent.mode()
+	if parentMode == KillSwitchDisabled {
+		return cb.execute(req, mode)
+	}
+
+	parentGeneration, err := parent.admit(parentMode)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := cb.execute(req, mode)
+	if isRejection(err) {
+		parent.cancelRequest(parentGeneration)
+	} else {
+		parent.afterRequest(parentGeneration, err)
+	}
+
+	return response, err
+}
+
+func (cb *CircuitBreaker) execute(req Request, mode KillSwitchMode) (interface{}, error) {
+	generation, err := cb.admit(mode)
+	if err != nil {
+		return nil, err
+	}
+
+	subscribed := cb.subscribed()
+	if subscribed {
+		cb.publish(Event{Type: EventAdmitted, State: cb.State()})
+	}
+
+	response, err := cb.call(req)
+	if subscribed {
+		cb.publishOutcome(err)
+	}
+
+	cb.afterRequest(generation, err)
+
+	return response, err
+}
+
+// isRejection сообщает, что запрос был отклонен Circuit Breaker и не выполнялся.
+func isRejection(err error) bool {
+	return err == ErrOpenState || err == ErrTooManyRequests || err == ErrResourcePressure
+}
+
+// cancelRequest отменяет учет запроса, допущенного в состоянии generation,
+// но не выполненного.
+func (cb *CircuitBreaker) cancelRequest(generation uint64) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.current.Load().generation == generation {
+		cb.counts.onCancel()
 	}
+}
 
-	if cb.state == StateOpen {
-		return nil, ErrOpenState
+func (cb *CircuitBreaker) beforeRequest() (uint64, error) {
+	if cb.underResourcePressure() {
+		return 0, cb.reject(ErrResourcePressure)
 	}
-	if cb.state == StateHalfOpen && cb.counts.Requests >= cb.maxRequests {
-		return nil, ErrTooManyRequests
+
+	// быстрый путь: в состоянии Closed запрос пропускается без блокировки
+	if current := cb.current.Load(); current.state == StateClosed {
+		cb.counts.onRequest()
+		return current.generation, nil
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	current := cb.current.Load()
+	if current.state == StateOpen && current.expiry.Before(cb.config().timeProvider.Now()) {
+		cb.transition(StateHalfOpen, ReasonTimeout, nil)
+		current = cb.current.Load()
+	}
+
+	switch {
+	case current.state == StateOpen:
+		return current.generation, cb.reject(ErrOpenState)
+	case current.state == StateHalfOpen && (cb.probesHalfOpen() || cb.counts.requests() >= cb.config().maxRequests):
+		return current.generation, cb.reject(ErrTooManyRequests)
 	}
 
 	cb.counts.onRequest()
 
-	response, err := req()
+	return current.generation, nil
+}
+
+func (cb *CircuitBreaker) afterRequest(generation uint64, err error) {
+	current := cb.current.Load()
+	if current.generation != generation {
+		return
+	}
+
+	// быстрый путь: успешный запрос в состоянии Closed учитывается без блокировки
+	if err == nil && current.state == StateClosed && cb.config().healthyResetInterval <= 0 {
+		cb.totals.successes.Add(1)
+		cb.counts.onSuccess()
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.current.Load().generation != generation {
+		return
+	}
 
 	if err != nil {
-		cb.onFailure()
+		cb.onFailure(err)
 	} else {
 		cb.onSuccess()
 	}
-
-	return response, err
 }
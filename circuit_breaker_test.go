@@ -2,27 +2,14 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
-)
-
-type TestTimeProvider struct {
-	modifiers []func(time time.Time) time.Time
-}
 
-func (p *TestTimeProvider) Modify(modifier func(time time.Time) time.Time) {
-	p.modifiers = append(p.modifiers, modifier)
-}
-
-func (p *TestTimeProvider) Now() time.Time {
-	now := time.Now()
-	for _, modifier := range p.modifiers {
-		now = modifier(now)
-	}
-	return now
-}
+	"github.com/raymanovg/circuit-breaker/clocktest"
+)
 
 func fail(cb *CircuitBreaker) error {
 	_, err := cb.Execute(func() (interface{}, error) {
@@ -41,7 +28,7 @@ func succeed(cb *CircuitBreaker) error {
 }
 
 func TestCircuitBreaker_Execute(t *testing.T) {
-	timeProvider := &TestTimeProvider{}
+	timeProvider := clocktest.New(time.Now())
 
 	cb := NewCircuitBreaker(
 		WithTimeout(5*time.Second),
@@ -58,67 +45,134 @@ func TestCircuitBreaker_Execute(t *testing.T) {
 	}
 
 	// состояние все еще Closed т.к. нужно > 5 ошибок подрят
-	assert.Equal(t, StateClosed, cb.state)
-	assert.Equal(t, Counts{5, 0, 5, 0, 5}, cb.counts)
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, Counts{5, 0, 5, 0, 5}, cb.Counts())
 
 	// успешный запуск. должен сбросить ConsecutiveFailures
 	assert.Nil(t, succeed(cb))
-	assert.Equal(t, StateClosed, cb.state)
-	assert.Equal(t, Counts{6, 1, 5, 1, 0}, cb.counts)
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, Counts{6, 1, 5, 1, 0}, cb.Counts())
 
 	// ошибка. статус все еще Closed т.к. ConsecutiveFailures=1
 	assert.NotNil(t, fail(cb))
-	assert.Equal(t, StateClosed, cb.state)
-	assert.Equal(t, Counts{7, 1, 6, 0, 1}, cb.counts)
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, Counts{7, 1, 6, 0, 1}, cb.Counts())
 
 	// StateClosed to StateOpen
 	for i := 0; i < 5; i++ {
 		assert.NotNil(t, fail(cb)) // 6 consecutive failures
 	}
 
-	assert.Equal(t, StateOpen, cb.state)
-	assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.counts)
-	assert.False(t, cb.expiry.IsZero())
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.Counts())
+	assert.False(t, cb.current.Load().expiry.IsZero())
 
 	// в Open запросы не проходят
 	assert.Error(t, succeed(cb))
 	assert.Error(t, fail(cb))
-	assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.counts)
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.Counts())
 
 	// StateOpen to StateHalfOpen
 	// over Timeout
-	timeProvider.Modify(func(t time.Time) time.Time {
-		return t.Add(6 * time.Second)
-	})
+	timeProvider.Advance(6 * time.Second)
 
 	assert.Nil(t, succeed(cb))
-	assert.Equal(t, StateHalfOpen, cb.state)
-	assert.True(t, cb.expiry.IsZero())
-	assert.Equal(t, Counts{1, 1, 0, 1, 0}, cb.counts)
+	assert.Equal(t, StateHalfOpen, cb.State())
+	assert.True(t, cb.current.Load().expiry.IsZero())
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, cb.Counts())
 
 	// StateHalfOpen to StateOpen
 	assert.NotNil(t, fail(cb))
-	assert.Equal(t, StateOpen, cb.state)
-	assert.False(t, cb.expiry.IsZero())
-	assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.counts)
+	assert.Equal(t, StateOpen, cb.State())
+	assert.False(t, cb.current.Load().expiry.IsZero())
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.Counts())
 
 	// StateOpen to StateHalfOpen
 	// over Timeout
-	timeProvider.Modify(func(t time.Time) time.Time {
-		return t.Add(6 * time.Second)
-	})
+	timeProvider.Advance(6 * time.Second)
 
 	assert.Nil(t, succeed(cb))
-	assert.Equal(t, StateHalfOpen, cb.state)
-	assert.True(t, cb.expiry.IsZero())
-	assert.Equal(t, Counts{1, 1, 0, 1, 0}, cb.counts)
+	assert.Equal(t, StateHalfOpen, cb.State())
+	assert.True(t, cb.current.Load().expiry.IsZero())
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, cb.Counts())
 
 	// StateHalfOpen to StateClosed
 	// ConsecutiveSuccesses(5) >= MaxRequests(5)
 	for i := 0; i < 4; i++ {
 		assert.Nil(t, succeed(cb))
 	}
-	assert.Equal(t, StateClosed, cb.state)
-	assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.counts)
-	assert.True(t, cb.expiry.IsZero())
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.Counts())
+	assert.True(t, cb.current.Load().expiry.IsZero())
+}
+
+func TestCircuitBreaker_OpenDurationBounds(t *testing.T) {
+	cb := NewCircuitBreaker(WithTimeout(time.Second), WithMinOpenDuration(3*time.Second))
+	assert.Equal(t, 3*time.Second, cb.openDuration(nil))
+
+	cb = NewCircuitBreaker(WithTimeout(time.Minute), WithMaxOpenDuration(30*time.Second))
+	assert.Equal(t, 30*time.Second, cb.openDuration(nil))
+
+	cb = NewCircuitBreaker(
+		WithTimeout(5*time.Second),
+		WithMinOpenDuration(time.Second),
+		WithMaxOpenDuration(10*time.Second),
+	)
+	assert.Equal(t, 5*time.Second, cb.openDuration(nil))
+
+	// Retry-After причины перехода заменяет timeout в пределах ограничений
+	assert.Equal(t, 7*time.Second, cb.openDuration(&StatusError{Retry: 7 * time.Second}))
+	assert.Equal(t, 10*time.Second, cb.openDuration(&StatusError{Retry: time.Hour}))
+	assert.Equal(t, time.Second, cb.openDuration(fmt.Errorf("wrapped: %w", &StatusError{Retry: time.Millisecond})))
+	assert.Equal(t, 5*time.Second, cb.openDuration(&StatusError{}))
+}
+
+func TestCircuitBreaker_HealthyReset(t *testing.T) {
+	timeProvider := clocktest.New(time.Now())
+
+	cb := NewCircuitBreaker(
+		WithHealthyResetInterval(time.Minute),
+		WithTimeProvider(timeProvider),
+	)
+
+	assert.NotNil(t, fail(cb))
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, Counts{2, 1, 1, 1, 0}, cb.Counts())
+
+	// период без ошибок еще не истек
+	timeProvider.Advance(30 * time.Second)
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, Counts{3, 2, 1, 2, 0}, cb.Counts())
+
+	// минута без ошибок - история сбрасывается
+	timeProvider.Advance(31 * time.Second)
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, Counts{0, 0, 0, 0, 0}, cb.Counts())
+
+	// ошибка прерывает серию успешных запросов
+	assert.NotNil(t, fail(cb))
+	assert.True(t, cb.healthySince.IsZero())
+}
+
+func TestCircuitBreaker_UpdateConfig(t *testing.T) {
+	cb := NewCircuitBreaker(
+		WithTimeout(time.Hour),
+		WithReadyToTrip(func(counts Counts) bool {
+			return counts.ConsecutiveFailures > 5
+		}),
+	)
+
+	for i := 0; i < 3; i++ {
+		assert.NotNil(t, fail(cb))
+	}
+
+	// новые настройки применяются без потери счетчиков
+	cb.UpdateConfig(WithReadyToTrip(func(counts Counts) bool {
+		return counts.ConsecutiveFailures > 3
+	}))
+	assert.Equal(t, Counts{3, 0, 3, 0, 3}, cb.Counts())
+	assert.Equal(t, time.Hour, cb.config().timeout)
+
+	assert.NotNil(t, fail(cb))
+	assert.Equal(t, StateOpen, cb.State())
 }
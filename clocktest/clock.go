@@ -0,0 +1,139 @@
+// Package clocktest содержит управляемые часы для детерминированного
+// тестирования конфигураций Circuit Breaker. Clock реализует clock.Clock.
+package clocktest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clock"
+)
+
+var _ clock.Clock = (*Clock)(nil)
+
+// Clock - часы, время которых меняется только через Advance и Set.
+type Clock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*Timer
+}
+
+func New(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Advance сдвигает время на d и срабатывает все истекшие таймеры.
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	c.fire()
+}
+
+// Set устанавливает время t и срабатывает все истекшие таймеры.
+// Время можно перевести и назад, тогда таймеры не срабатывают.
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = t
+	c.fire()
+}
+
+// Timer - таймер, срабатывающий по времени Clock.
+type Timer struct {
+	c        chan time.Time
+	clock    *Clock
+	deadline time.Time
+}
+
+// NewTimer создает таймер, который сработает, когда время Clock достигнет Now() + d.
+func (c *Clock) NewTimer(d time.Duration) clock.Timer {
+	return c.newTimer(d)
+}
+
+func (c *Clock) newTimer(d time.Duration) *Timer {
+	t := &Timer{c: make(chan time.Time, 1), clock: c}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t.deadline = c.now.Add(d)
+	c.timers = append(c.timers, t)
+	c.fire()
+
+	return t
+}
+
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.newTimer(d).c
+}
+
+func (t *Timer) C() <-chan time.Time {
+	return t.c
+}
+
+// Stop отменяет таймер. Возвращает false, если таймер уже сработал или остановлен.
+func (t *Timer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	return t.clock.remove(t)
+}
+
+// Reset перезапускает таймер на d от текущего времени Clock.
+// Возвращает true, если таймер был активен.
+func (t *Timer) Reset(d time.Duration) bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	active := c.remove(t)
+	t.deadline = c.now.Add(d)
+	c.timers = append(c.timers, t)
+	c.fire()
+
+	return active
+}
+
+// Timers возвращает кол-во активных таймеров. Позволяет тесту дождаться,
+// пока проверяемый код заведет таймер, прежде чем сдвигать время.
+func (c *Clock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.timers)
+}
+
+func (c *Clock) remove(t *Timer) bool {
+	for i, timer := range c.timers {
+		if timer == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Clock) fire() {
+	active := c.timers[:0]
+	for _, t := range c.timers {
+		if t.deadline.After(c.now) {
+			active = append(active, t)
+			continue
+		}
+		select {
+		case t.c <- c.now:
+		default:
+		}
+	}
+	c.timers = active
+}
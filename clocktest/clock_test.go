@@ -0,0 +1,59 @@
+package clocktest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClock_AdvanceAndSet(t *testing.T) {
+	start := time.Unix(1000, 0)
+	c := New(start)
+	assert.Equal(t, start, c.Now())
+
+	c.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Second), c.Now())
+
+	c.Set(start)
+	assert.Equal(t, start, c.Now())
+}
+
+func TestClock_Timer(t *testing.T) {
+	c := New(time.Unix(1000, 0))
+
+	timer := c.NewTimer(time.Second)
+	assert.Equal(t, 1, c.Timers())
+
+	c.Advance(500 * time.Millisecond)
+	select {
+	case <-timer.C():
+		t.Fatal("timer fired too early")
+	default:
+	}
+
+	c.Advance(500 * time.Millisecond)
+	assert.Equal(t, time.Unix(1001, 0), <-timer.C())
+	assert.Zero(t, c.Timers())
+	assert.False(t, timer.Stop())
+
+	assert.False(t, timer.Reset(time.Second))
+	assert.True(t, timer.Stop())
+	c.Advance(time.Hour)
+	select {
+	case <-timer.C():
+		t.Fatal("stopped timer fired")
+	default:
+	}
+}
+
+func TestClock_After(t *testing.T) {
+	c := New(time.Unix(1000, 0))
+
+	after := c.After(time.Minute)
+	c.Set(time.Unix(2000, 0))
+	assert.Equal(t, time.Unix(2000, 0), <-after)
+
+	// таймер с нулевой длительностью срабатывает сразу
+	assert.Equal(t, time.Unix(2000, 0), <-c.After(0))
+}
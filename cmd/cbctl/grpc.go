@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/raymanovg/circuit-breaker/adminpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// grpcClient обращается к gRPC API RegisterGRPCAdmin.
+type grpcClient struct {
+	conn   *grpc.ClientConn
+	client adminpb.CircuitBreakerAdminClient
+}
+
+func newGRPCClient(addr string) (*grpcClient, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	return &grpcClient{conn: conn, client: adminpb.NewCircuitBreakerAdminClient(conn)}, nil
+}
+
+func (c *grpcClient) list(ctx context.Context) ([]breaker, error) {
+	resp, err := c.client.ListBreakers(ctx, &adminpb.ListBreakersRequest{})
+	if err != nil {
+		return nil, err
+	}
+	breakers := make([]breaker, 0, len(resp.GetBreakers()))
+	for _, b := range resp.GetBreakers() {
+		breakers = append(breakers, fromProto(b))
+	}
+	return breakers, nil
+}
+
+func (c *grpcClient) get(ctx context.Context, name string) (breaker, error) {
+	b, err := c.client.GetBreaker(ctx, &adminpb.GetBreakerRequest{Name: name})
+	return fromProto(b), err
+}
+
+func (c *grpcClient) do(ctx context.Context, action, name string) (breaker, error) {
+	var (
+		b   *adminpb.Breaker
+		err error
+	)
+	switch action {
+	case "trip":
+		b, err = c.client.Trip(ctx, &adminpb.TripRequest{Name: name})
+	case "reset":
+		b, err = c.client.Reset(ctx, &adminpb.ResetRequest{Name: name})
+	default:
+		return breaker{}, errors.New(action + " is not supported over gRPC")
+	}
+	return fromProto(b), err
+}
+
+func (c *grpcClient) tail(ctx context.Context, names, types []string, fn func(event) error) error {
+	req := &adminpb.WatchEventsRequest{Names: names}
+	for _, t := range types {
+		value, ok := adminpb.EventType_value["EVENT_TYPE_"+strings.ToUpper(strings.ReplaceAll(t, "-", "_"))]
+		if !ok {
+			return errors.New("unknown event type " + t)
+		}
+		req.Types = append(req.Types, adminpb.EventType(value))
+	}
+
+	stream, err := c.client.WatchEvents(ctx, req)
+	if err != nil {
+		return err
+	}
+	for {
+		e, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(eventFromProto(e)); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *grpcClient) close() error {
+	return c.conn.Close()
+}
+
+func fromProto(b *adminpb.Breaker) breaker {
+	if b == nil {
+		return breaker{}
+	}
+	v := breaker{
+		Name:            b.GetName(),
+		State:           stateName(b.GetState()),
+		Mode:            b.GetMode(),
+		Since:           b.GetSince().AsTime(),
+		FailuresTotal:   b.GetFailuresTotal(),
+		RejectionsTotal: b.GetRejectionsTotal(),
+		LastError:       b.GetLastError(),
+	}
+	if b.GetLastFailureAt() != nil {
+		v.LastFailureAt = b.GetLastFailureAt().AsTime()
+	}
+	if c := b.GetCounts(); c != nil {
+		v.Counts = counts{
+			Requests:             c.GetRequests(),
+			TotalSuccess:         c.GetTotalSuccess(),
+			TotalFailures:        c.GetTotalFailures(),
+			ConsecutiveSuccesses: c.GetConsecutiveSuccesses(),
+			ConsecutiveFailures:  c.GetConsecutiveFailures(),
+		}
+	}
+	return v
+}
+
+func eventFromProto(e *adminpb.Event) event {
+	v := event{
+		Type:  strings.ReplaceAll(strings.ToLower(strings.TrimPrefix(e.GetType().String(), "EVENT_TYPE_")), "_", "-"),
+		Name:  e.GetName(),
+		At:    e.GetAt().AsTime(),
+		State: stateName(e.GetState()),
+		Error: e.GetError(),
+	}
+	if change := e.GetChange(); change != nil {
+		v.Change = &stateChange{
+			From:   stateName(change.GetFrom()),
+			To:     stateName(change.GetTo()),
+			Reason: change.GetReason(),
+			Error:  change.GetError(),
+		}
+	}
+	return v
+}
+
+// stateName возвращает имя состояния в том же виде, что и HTTP API.
+func stateName(state adminpb.State) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimPrefix(state.String(), "STATE_")), "_", "-")
+}
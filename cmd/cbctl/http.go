@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// httpClient обращается к HTTP API AdminHandler.
+type httpClient struct {
+	base   string
+	client *http.Client
+}
+
+func newHTTPClient(base string) *httpClient {
+	return &httpClient{base: strings.TrimSuffix(base, "/"), client: http.DefaultClient}
+}
+
+type breakerJSON struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+	Mode  string `json:"mode"`
+	Stats *struct {
+		Since         time.Time `json:"since"`
+		Counts        counts    `json:"counts"`
+		Rejections    uint64    `json:"rejections_total"`
+		Failures      uint64    `json:"failures_total"`
+		LastError     string    `json:"last_error"`
+		LastFailureAt time.Time `json:"last_failure_at"`
+	} `json:"stats"`
+}
+
+func (b breakerJSON) breaker() breaker {
+	v := breaker{Name: b.Name, State: b.State, Mode: b.Mode}
+	if b.Stats != nil {
+		v.Since = b.Stats.Since
+		v.Counts = b.Stats.Counts
+		v.RejectionsTotal = b.Stats.Rejections
+		v.FailuresTotal = b.Stats.Failures
+		v.LastError = b.Stats.LastError
+		v.LastFailureAt = b.Stats.LastFailureAt
+	}
+	return v
+}
+
+func (c *httpClient) list(ctx context.Context) ([]breaker, error) {
+	var resp []breakerJSON
+	if err := c.call(ctx, http.MethodGet, "/breakers", &resp); err != nil {
+		return nil, err
+	}
+	breakers := make([]breaker, 0, len(resp))
+	for _, b := range resp {
+		breakers = append(breakers, b.breaker())
+	}
+	return breakers, nil
+}
+
+func (c *httpClient) get(ctx context.Context, name string) (breaker, error) {
+	var resp breakerJSON
+	err := c.call(ctx, http.MethodGet, "/breakers/"+url.PathEscape(name), &resp)
+	return resp.breaker(), err
+}
+
+func (c *httpClient) do(ctx context.Context, action, name string) (breaker, error) {
+	var resp breakerJSON
+	err := c.call(ctx, http.MethodPost, "/breakers/"+url.PathEscape(name)+"/"+action, &resp)
+	return resp.breaker(), err
+}
+
+func (c *httpClient) call(ctx context.Context, method, path string, v any) error {
+	resp, err := c.send(ctx, method, path)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (c *httpClient) send(ctx context.Context, method, path string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+// tail читает поток Server-Sent Events из GET /events.
+func (c *httpClient) tail(ctx context.Context, names, types []string, fn func(event) error) error {
+	query := url.Values{"name": names, "type": types}
+	resp, err := c.send(ctx, http.MethodGet, "/events?"+query.Encode())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var e event
+		if err := json.Unmarshal([]byte(data), &e); err != nil {
+			return err
+		}
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (c *httpClient) close() error {
+	return nil
+}
@@ -0,0 +1,223 @@
+// Команда cbctl управляет Circuit Breaker работающего сервиса через
+// HTTP API AdminHandler или gRPC API RegisterGRPCAdmin.
+//
+//	cbctl [-addr URL | -grpc HOST:PORT] list
+//	cbctl show NAME
+//	cbctl trip NAME
+//	cbctl reset NAME
+//	cbctl disable NAME
+//	cbctl tail [-type TYPE]... [NAME...]
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// breaker - состояние Circuit Breaker, полученное от сервиса.
+type breaker struct {
+	Name            string
+	State           string
+	Mode            string
+	Since           time.Time
+	Counts          counts
+	FailuresTotal   uint64
+	RejectionsTotal uint64
+	LastError       string
+	LastFailureAt   time.Time
+}
+
+type counts struct {
+	Requests             uint32 `json:"requests"`
+	TotalSuccess         uint32 `json:"total_success"`
+	TotalFailures        uint32 `json:"total_failures"`
+	ConsecutiveSuccesses uint32 `json:"consecutive_successes"`
+	ConsecutiveFailures  uint32 `json:"consecutive_failures"`
+}
+
+type event struct {
+	Type   string       `json:"type"`
+	Name   string       `json:"name"`
+	At     time.Time    `json:"at"`
+	State  string       `json:"state"`
+	Error  string       `json:"error"`
+	Change *stateChange `json:"change"`
+}
+
+type stateChange struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Reason string `json:"reason"`
+	Error  string `json:"error"`
+}
+
+// client - API управления Circuit Breaker.
+type client interface {
+	list(ctx context.Context) ([]breaker, error)
+	get(ctx context.Context, name string) (breaker, error)
+	// do выполняет действие trip, reset или disable.
+	do(ctx context.Context, action, name string) (breaker, error)
+	tail(ctx context.Context, names, types []string, fn func(event) error) error
+	close() error
+}
+
+var errUsage = errors.New("usage: cbctl [-addr URL | -grpc HOST:PORT] list | show NAME | trip NAME | reset NAME | disable NAME | tail [-type TYPE]... [NAME...]")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "cbctl:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("cbctl", flag.ContinueOnError)
+	addr := flags.String("addr", "http://localhost:8080", "base URL of the admin HTTP API")
+	grpcAddr := flags.String("grpc", "", "address of the admin gRPC API, used instead of -addr")
+	timeout := flags.Duration("timeout", 10*time.Second, "timeout of commands other than tail")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return errUsage
+	}
+
+	var (
+		c   client
+		err error
+	)
+	if *grpcAddr != "" {
+		c, err = newGRPCClient(*grpcAddr)
+	} else {
+		c = newHTTPClient(*addr)
+	}
+	if err != nil {
+		return err
+	}
+	defer c.close()
+
+	command, args := flags.Arg(0), flags.Args()[1:]
+	if command == "tail" {
+		return tail(ctx, c, args, stdout)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	switch command {
+	case "list":
+		if len(args) != 0 {
+			return errUsage
+		}
+		breakers, err := c.list(ctx)
+		if err != nil {
+			return err
+		}
+		printList(stdout, breakers)
+		return nil
+	case "show", "trip", "reset", "disable":
+		if len(args) != 1 {
+			return errUsage
+		}
+		var b breaker
+		if command == "show" {
+			b, err = c.get(ctx, args[0])
+		} else {
+			b, err = c.do(ctx, command, args[0])
+		}
+		if err != nil {
+			return err
+		}
+		printBreaker(stdout, b)
+		return nil
+	default:
+		return errUsage
+	}
+}
+
+func tail(ctx context.Context, c client, args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("tail", flag.ContinueOnError)
+	var types stringList
+	flags.Var(&types, "type", "event type: admitted, rejected, success, failure or state-changed (default state-changed)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	err := c.tail(ctx, flags.Args(), types, func(e event) error {
+		_, err := fmt.Fprintln(stdout, formatEvent(e))
+		return err
+	})
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+func printList(w io.Writer, breakers []breaker) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSTATE\tMODE")
+	for _, b := range breakers {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", b.Name, b.State, b.Mode)
+	}
+	tw.Flush()
+}
+
+func printBreaker(w io.Writer, b breaker) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Name:\t%s\n", b.Name)
+	fmt.Fprintf(tw, "State:\t%s\n", b.State)
+	fmt.Fprintf(tw, "Mode:\t%s\n", b.Mode)
+	if !b.Since.IsZero() {
+		fmt.Fprintf(tw, "Since:\t%s\n", b.Since.Format(time.RFC3339))
+	}
+	fmt.Fprintf(tw, "Requests:\t%d (%d succeeded, %d failed)\n", b.Counts.Requests, b.Counts.TotalSuccess, b.Counts.TotalFailures)
+	fmt.Fprintf(tw, "Consecutive:\t%d successes, %d failures\n", b.Counts.ConsecutiveSuccesses, b.Counts.ConsecutiveFailures)
+	fmt.Fprintf(tw, "Failures total:\t%d\n", b.FailuresTotal)
+	fmt.Fprintf(tw, "Rejections total:\t%d\n", b.RejectionsTotal)
+	if b.LastError != "" {
+		fmt.Fprintf(tw, "Last error:\t%s at %s\n", b.LastError, b.LastFailureAt.Format(time.RFC3339))
+	}
+	tw.Flush()
+}
+
+func formatEvent(e event) string {
+	line := e.At.Format(time.RFC3339Nano) + " " + e.Name + " " + e.Type
+	if e.Change != nil {
+		line += " " + e.Change.From + " -> " + e.Change.To
+		if e.Change.Reason != "" {
+			line += " (" + e.Change.Reason + ")"
+		}
+		if e.Change.Error != "" {
+			line += fmt.Sprintf(" err=%q", e.Change.Error)
+		}
+		return line
+	}
+	line += " state=" + e.State
+	if e.Error != "" {
+		line += fmt.Sprintf(" err=%q", e.Error)
+	}
+	return line
+}
+
+// stringList - повторяемый флаг.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func adminServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /breakers", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `[{"name": "payments", "state": "open", "mode": "off"}, {"name": "search", "state": "closed", "mode": "disabled"}]`)
+	})
+	mux.HandleFunc("GET /breakers/payments", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"name": "payments", "state": "open", "mode": "off", "stats": {
+			"since": "2024-03-01T03:00:00Z",
+			"counts": {"requests": 3, "total_success": 1, "total_failures": 2, "consecutive_successes": 0, "consecutive_failures": 2},
+			"rejections_total": 7, "failures_total": 5,
+			"last_error": "connection refused", "last_failure_at": "2024-03-01T03:00:00Z"
+		}}`)
+	})
+	mux.HandleFunc("POST /breakers/{name}/trip", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("name") != "search" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"name": "search", "state": "open", "mode": "off"}`)
+	})
+	mux.HandleFunc("GET /events", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, []string{"payments"}, r.URL.Query()["name"])
+		assert.Equal(t, []string{"state-changed", "rejected"}, r.URL.Query()["type"])
+		fmt.Fprint(w, "event: state-changed\n")
+		fmt.Fprint(w, `data: {"type": "state-changed", "name": "payments", "at": "2024-03-01T03:00:00Z", "state": "open", "change": {"from": "closed", "to": "open", "reason": "trip strategy", "error": "timeout"}}`+"\n\n")
+		fmt.Fprint(w, ": keep-alive\n\n")
+		fmt.Fprint(w, "event: rejected\n")
+		fmt.Fprint(w, `data: {"type": "rejected", "name": "payments", "at": "2024-03-01T03:00:01Z", "state": "open", "error": "state is open"}`+"\n\n")
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func runCommand(t *testing.T, args ...string) (string, error) {
+	var out strings.Builder
+	err := run(context.Background(), append([]string{"-addr", adminServer(t).URL}, args...), &out)
+	return out.String(), err
+}
+
+func TestRun(t *testing.T) {
+	out, err := runCommand(t, "list")
+	require.NoError(t, err)
+	assert.Equal(t, "NAME      STATE   MODE\npayments  open    off\nsearch    closed  disabled\n", out)
+
+	out, err = runCommand(t, "show", "payments")
+	require.NoError(t, err)
+	assert.Equal(t, `Name:              payments
+State:             open
+Mode:              off
+Since:             2024-03-01T03:00:00Z
+Requests:          3 (1 succeeded, 2 failed)
+Consecutive:       0 successes, 2 failures
+Failures total:    5
+Rejections total:  7
+Last error:        connection refused at 2024-03-01T03:00:00Z
+`, out)
+
+	out, err = runCommand(t, "trip", "search")
+	require.NoError(t, err)
+	assert.Contains(t, out, "State:             open\n")
+
+	_, err = runCommand(t, "trip", "orders")
+	assert.ErrorContains(t, err, "POST /breakers/orders/trip: 404 Not Found")
+
+	out, err = runCommand(t, "tail", "-type", "state-changed", "-type", "rejected", "payments")
+	require.NoError(t, err)
+	assert.Equal(t, `2024-03-01T03:00:00Z payments state-changed closed -> open (trip strategy) err="timeout"
+2024-03-01T03:00:01Z payments rejected state=open err="state is open"
+`, out)
+}
+
+func TestRun_Usage(t *testing.T) {
+	for _, args := range [][]string{{}, {"show"}, {"list", "extra"}, {"explode"}} {
+		_, err := runCommand(t, args...)
+		assert.ErrorIs(t, err, errUsage, args)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CoarseTimeProvider - TimeProvider, который отдает время, обновляемое фоновым
+// тикером с заданной точностью. Now не обращается к системным часам,
+// поэтому подходит для высоконагруженных Circuit Breaker,
+// логике которых не нужна точность выше resolution.
+type CoarseTimeProvider struct {
+	now  atomic.Int64
+	stop chan struct{}
+	once sync.Once
+	done chan struct{}
+}
+
+// NewCoarseTimeProvider запускает тикер с шагом resolution.
+// Тикер работает до вызова Stop.
+func NewCoarseTimeProvider(resolution time.Duration) *CoarseTimeProvider {
+	p := &CoarseTimeProvider{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	p.now.Store(time.Now().UnixNano())
+
+	go func() {
+		defer close(p.done)
+
+		ticker := time.NewTicker(resolution)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-p.stop:
+				return
+			case now := <-ticker.C:
+				p.now.Store(now.UnixNano())
+			}
+		}
+	}()
+
+	return p
+}
+
+func (p *CoarseTimeProvider) Now() time.Time {
+	return time.Unix(0, p.now.Load())
+}
+
+// Stop останавливает тикер и дожидается завершения его горутины.
+// После Stop Now возвращает последнее полученное время.
+func (p *CoarseTimeProvider) Stop() {
+	p.once.Do(func() {
+		close(p.stop)
+	})
+	<-p.done
+}
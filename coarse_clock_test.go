@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoarseTimeProvider(t *testing.T) {
+	p := NewCoarseTimeProvider(time.Millisecond)
+
+	start := p.Now()
+	assert.WithinDuration(t, time.Now(), start, 10*time.Millisecond)
+
+	assert.Eventually(t, func() bool {
+		return p.Now().After(start)
+	}, time.Second, time.Millisecond)
+
+	p.Stop()
+	p.Stop()
+
+	stopped := p.Now()
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, stopped, p.Now())
+}
+
+func TestCircuitBreaker_CoarseTimeProvider(t *testing.T) {
+	p := NewCoarseTimeProvider(time.Millisecond)
+	defer p.Stop()
+
+	cb := NewCircuitBreaker(WithTimeout(10*time.Millisecond), WithTimeProvider(p))
+	cb.trip()
+	assert.ErrorIs(t, succeed(cb), ErrOpenState)
+
+	assert.Eventually(t, func() bool {
+		return succeed(cb) == nil
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, StateHalfOpen, cb.State())
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Config - декларативное описание настроек Circuit Breaker.
+// Нулевые значения полей означают настройки по умолчанию. Поля-функции
+// и зависимости не сериализуются.
+type Config struct {
+	Name   string            `json:"name,omitempty" yaml:"name,omitempty"`
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+
+	MaxRequests          uint32        `json:"max_requests,omitempty" yaml:"max_requests,omitempty"`
+	Timeout              time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	MinOpenDuration      time.Duration `json:"min_open_duration,omitempty" yaml:"min_open_duration,omitempty"`
+	MaxOpenDuration      time.Duration `json:"max_open_duration,omitempty" yaml:"max_open_duration,omitempty"`
+	HealthyResetInterval time.Duration `json:"healthy_reset_interval,omitempty" yaml:"healthy_reset_interval,omitempty"`
+	WarmupPeriod         time.Duration `json:"warmup_period,omitempty" yaml:"warmup_period,omitempty"`
+
+	// Декларативная стратегия перехода в Open, если ReadyToTrip не задан:
+	// после ConsecutiveFailures ошибок подряд либо при доле ошибок не меньше
+	// FailureRatio из не менее чем MinRequests запросов.
+	ConsecutiveFailures uint32  `json:"consecutive_failures,omitempty" yaml:"consecutive_failures,omitempty"`
+	FailureRatio        float64 `json:"failure_ratio,omitempty" yaml:"failure_ratio,omitempty"`
+	MinRequests         uint32  `json:"min_requests,omitempty" yaml:"min_requests,omitempty"`
+
+	HealthCheckInterval    time.Duration `json:"health_check_interval,omitempty" yaml:"health_check_interval,omitempty"`
+	HalfOpenProbeInterval  time.Duration `json:"half_open_probe_interval,omitempty" yaml:"half_open_probe_interval,omitempty"`
+	NotificationQueueSize  int           `json:"notification_queue_size,omitempty" yaml:"notification_queue_size,omitempty"`
+	NotificationInterval   time.Duration `json:"notification_interval,omitempty" yaml:"notification_interval,omitempty"`
+	ChildTripThreshold     int           `json:"child_trip_threshold,omitempty" yaml:"child_trip_threshold,omitempty"`
+	ChaosFailureRate       float64       `json:"chaos_failure_rate,omitempty" yaml:"chaos_failure_rate,omitempty"`
+	ChaosLatency           time.Duration `json:"chaos_latency,omitempty" yaml:"chaos_latency,omitempty"`
+	KillSwitchPollInterval time.Duration `json:"kill_switch_poll_interval,omitempty" yaml:"kill_switch_poll_interval,omitempty"`
+	LogSummaryInterval     time.Duration `json:"log_summary_interval,omitempty" yaml:"log_summary_interval,omitempty"`
+	HistorySize            int           `json:"history_size,omitempty" yaml:"history_size,omitempty"`
+	TraceAnnotations       bool          `json:"trace_annotations,omitempty" yaml:"trace_annotations,omitempty"`
+	PprofLabels            bool          `json:"pprof_labels,omitempty" yaml:"pprof_labels,omitempty"`
+	LatencyStats           bool          `json:"latency_stats,omitempty" yaml:"latency_stats,omitempty"`
+
+	ReadyToTrip       func(counts Counts) bool       `json:"-" yaml:"-"`
+	WarmupReadyToTrip func(counts Counts) bool       `json:"-" yaml:"-"`
+	TimeProvider      TimeProvider                   `json:"-" yaml:"-"`
+	HealthCheck       HealthCheck                    `json:"-" yaml:"-"`
+	HalfOpenProbe     HealthCheck                    `json:"-" yaml:"-"`
+	OnStateChange     func(change StateChange)       `json:"-" yaml:"-"`
+	ShedOnQueueDepth  func(depth int64) bool         `json:"-" yaml:"-"`
+	ResourceProbe     ResourceProbe                  `json:"-" yaml:"-"`
+	Overloaded        func(usage ResourceUsage) bool `json:"-" yaml:"-"`
+	OutcomeRecorder   OutcomeRecorder                `json:"-" yaml:"-"`
+	StateStore        StateStore                     `json:"-" yaml:"-"`
+	OnStateStoreError func(err error)                `json:"-" yaml:"-"`
+	Parent            *CircuitBreaker                `json:"-" yaml:"-"`
+	TimerWheel        *TimerWheel                    `json:"-" yaml:"-"`
+	ConfigProvider    ConfigProvider                 `json:"-" yaml:"-"`
+	OnConfigError     func(err error)                `json:"-" yaml:"-"`
+	KillSwitch        KillSwitch                     `json:"-" yaml:"-"`
+	Logger            Logger                         `json:"-" yaml:"-"`
+	EventBus          *EventBus                      `json:"-" yaml:"-"`
+}
+
+// NewFromConfig проверяет конфигурацию и создает по ней Circuit Breaker.
+func NewFromConfig(c Config) (*CircuitBreaker, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return NewBuilder().With(c.Options()...).Build()
+}
+
+// validate проверяет поля, которые не сводятся к проверке опций.
+func (c Config) validate() error {
+	if c.FailureRatio < 0 || c.FailureRatio > 1 {
+		return fmt.Errorf("%w: failure ratio %v is out of range [0, 1]", ErrInvalidConfig, c.FailureRatio)
+	}
+	return nil
+}
+
+// Options преобразует конфигурацию в список опций.
+func (c Config) Options() []Option {
+	var options []Option
+	add := func(set bool, opt Option) {
+		if set {
+			options = append(options, opt)
+		}
+	}
+
+	add(c.Name != "", WithName(c.Name))
+	add(c.Labels != nil, WithLabels(c.Labels))
+	add(c.MaxRequests > 0, WithMaxRequests(c.MaxRequests))
+	add(c.Timeout != 0, WithTimeout(c.Timeout))
+	add(c.MinOpenDuration != 0, WithMinOpenDuration(c.MinOpenDuration))
+	add(c.MaxOpenDuration != 0, WithMaxOpenDuration(c.MaxOpenDuration))
+	add(c.HealthyResetInterval != 0, WithHealthyResetInterval(c.HealthyResetInterval))
+	add(c.WarmupPeriod != 0, WithWarmup(c.WarmupPeriod, c.WarmupReadyToTrip))
+	add(c.ReadyToTrip != nil, WithReadyToTrip(c.ReadyToTrip))
+	add(c.ReadyToTrip == nil && (c.ConsecutiveFailures > 0 || c.FailureRatio > 0), WithReadyToTrip(c.tripStrategy()))
+	add(c.TimeProvider != nil, WithTimeProvider(c.TimeProvider))
+	add(c.HealthCheck != nil, WithHealthCheck(c.HealthCheck, c.HealthCheckInterval))
+	add(c.HalfOpenProbe != nil, WithHalfOpenProbe(c.HalfOpenProbe, c.HalfOpenProbeInterval))
+	add(c.OnStateChange != nil, WithOnStateChange(c.OnStateChange))
+	add(c.NotificationQueueSize != 0 || c.NotificationInterval != 0, WithNotificationLimits(c.NotificationQueueSize, c.NotificationInterval))
+	add(c.ShedOnQueueDepth != nil, WithShedOnQueueDepth(c.ShedOnQueueDepth))
+	add(c.ResourceProbe != nil, WithResourceProbe(c.ResourceProbe, c.Overloaded))
+	add(c.OutcomeRecorder != nil, WithOutcomeRecorder(c.OutcomeRecorder))
+	add(c.StateStore != nil, WithStateStore(c.StateStore, c.OnStateStoreError))
+	add(c.Parent != nil, WithParent(c.Parent))
+	add(c.ChildTripThreshold != 0, WithChildTripThreshold(c.ChildTripThreshold))
+	add(c.ChaosFailureRate != 0 || c.ChaosLatency != 0, WithChaos(c.ChaosFailureRate, c.ChaosLatency))
+	add(c.TimerWheel != nil, WithTimerWheel(c.TimerWheel))
+	add(c.Logger != nil, WithLogger(c.Logger))
+	add(c.LogSummaryInterval != 0, WithLogSummary(c.LogSummaryInterval))
+	add(c.TraceAnnotations, WithTraceAnnotations())
+	add(c.PprofLabels, WithPprofLabels())
+	add(c.LatencyStats, WithLatencyStats())
+	add(c.EventBus != nil, WithEventBus(c.EventBus))
+	add(c.HistorySize != 0, WithHistory(c.HistorySize))
+	add(c.KillSwitch != nil, WithKillSwitch(c.KillSwitch, c.KillSwitchPollInterval))
+	add(c.ConfigProvider != nil, WithConfigProvider(c.ConfigProvider, c.OnConfigError))
+
+	return options
+}
+
+func (c Config) tripStrategy() func(counts Counts) bool {
+	return func(counts Counts) bool {
+		if c.ConsecutiveFailures > 0 && counts.ConsecutiveFailures >= c.ConsecutiveFailures {
+			return true
+		}
+		return c.FailureRatio > 0 && counts.Requests > 0 && counts.Requests >= c.MinRequests &&
+			float64(counts.TotalFailures) >= c.FailureRatio*float64(counts.Requests)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileConfig - описание реестра Circuit Breaker в файле конфигурации.
+// Поддерживаются YAML и JSON, длительности задаются строками вида "30s".
+//
+//	expiry_model: background
+//	defaults:
+//	  timeout: 30s
+//	breakers:
+//	  payments:
+//	    consecutive_failures: 3
+type FileConfig struct {
+	ExpiryModel ExpiryModel       `json:"expiry_model,omitempty" yaml:"expiry_model,omitempty"`
+	ExpiryTick  time.Duration     `json:"expiry_tick,omitempty" yaml:"expiry_tick,omitempty"`
+	Defaults    Config            `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+	Breakers    map[string]Config `json:"breakers,omitempty" yaml:"breakers,omitempty"`
+}
+
+// LoadConfigFile читает и проверяет файл конфигурации.
+func LoadConfigFile(path string) (*FileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fc, err := ParseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return fc, nil
+}
+
+// ParseConfig разбирает и проверяет конфигурацию в формате YAML или JSON.
+// Неизвестные поля считаются ошибкой.
+func ParseConfig(data []byte) (*FileConfig, error) {
+	var fc FileConfig
+
+	// JSON является подмножеством YAML, поэтому достаточно одного парсера.
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&fc); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
+
+	if err := fc.validate(); err != nil {
+		return nil, err
+	}
+	return &fc, nil
+}
+
+func (fc *FileConfig) validate() error {
+	if fc.ExpiryTick < 0 {
+		return fmt.Errorf("%w: expiry tick must not be negative", ErrInvalidConfig)
+	}
+	if err := fc.Defaults.validate(); err != nil {
+		return fmt.Errorf("defaults: %w", err)
+	}
+	if _, err := validSettings(fc.Defaults.Options()); err != nil {
+		return fmt.Errorf("defaults: %w", err)
+	}
+
+	for _, name := range fc.names() {
+		c := fc.Breakers[name]
+		if err := c.validate(); err != nil {
+			return fmt.Errorf("breaker %q: %w", name, err)
+		}
+		if _, err := validSettings(fc.options(name)); err != nil {
+			return fmt.Errorf("breaker %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Registry создает реестр по конфигурации и сразу создает в нем все описанные
+// Circuit Breaker. Опции из файла применяются перед WithDefaults и WithOverrides
+// из options.
+func (fc *FileConfig) Registry(options ...RegistryOption) *Registry {
+	all := []RegistryOption{WithExpiryModel(fc.ExpiryModel)}
+	if fc.ExpiryTick > 0 {
+		all = append(all, WithExpiryTick(fc.ExpiryTick))
+	}
+
+	r := NewRegistry(append(all, options...)...)
+	r.configDefaults, r.configOverrides = fc.registryOptions()
+	for _, name := range fc.names() {
+		r.Get(name)
+	}
+	return r
+}
+
+// Apply применяет конфигурацию к работающему реестру: настройки всех
+// Circuit Breaker заменяются атомарно с сохранением состояния и счетчиков,
+// недостающие Circuit Breaker создаются. Если новые настройки хотя бы одного
+// Circuit Breaker некорректны, реестр не изменяется.
+// ExpiryModel и ExpiryTick после создания реестра не меняются.
+func (fc *FileConfig) Apply(r *Registry) error {
+	defaults, overrides := fc.registryOptions()
+	if err := r.reconfigure(defaults, overrides); err != nil {
+		return err
+	}
+
+	for _, name := range fc.names() {
+		r.Get(name)
+	}
+	return nil
+}
+
+func (fc *FileConfig) registryOptions() ([]Option, map[string][]Option) {
+	overrides := make(map[string][]Option, len(fc.Breakers))
+	for _, name := range fc.names() {
+		overrides[name] = fc.breakerConfig(name).Options()
+	}
+	return fc.Defaults.Options(), overrides
+}
+
+// breakerConfig возвращает настройки name. Имя всегда берется из ключа.
+func (fc *FileConfig) breakerConfig(name string) Config {
+	c := fc.Breakers[name]
+	c.Name = name
+	return c
+}
+
+// options возвращает опции name в том порядке, в котором их применит реестр.
+func (fc *FileConfig) options(name string) []Option {
+	return append(fc.Defaults.Options(), fc.breakerConfig(name).Options()...)
+}
+
+func (fc *FileConfig) names() []string {
+	names := make([]string, 0, len(fc.Breakers))
+	for name := range fc.Breakers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig_YAML(t *testing.T) {
+	fc, err := ParseConfig([]byte(`
+expiry_model: background
+expiry_tick: 5ms
+defaults:
+  timeout: 30s
+  max_requests: 2
+breakers:
+  payments:
+    consecutive_failures: 3
+    labels:
+      team: billing
+  search:
+    timeout: 1m
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, ExpiryBackground, fc.ExpiryModel)
+	assert.Equal(t, 5*time.Millisecond, fc.ExpiryTick)
+	assert.Equal(t, 30*time.Second, fc.Defaults.Timeout)
+	assert.Equal(t, uint32(3), fc.Breakers["payments"].ConsecutiveFailures)
+
+	r := fc.Registry()
+	defer r.Stop()
+
+	var names []string
+	r.Range(func(name string, _ *CircuitBreaker) bool {
+		names = append(names, name)
+		return true
+	})
+	assert.Equal(t, []string{"payments", "search"}, names)
+
+	payments := r.Get("payments")
+	assert.Equal(t, "payments", payments.Name())
+	assert.Equal(t, map[string]string{"team": "billing"}, payments.Labels())
+	assert.Equal(t, 30*time.Second, payments.config().timeout)
+	assert.Equal(t, uint32(2), payments.config().maxRequests)
+	assert.NotNil(t, payments.config().timerWheel)
+	assert.Equal(t, time.Minute, r.Get("search").config().timeout)
+
+	for i := 0; i < 3; i++ {
+		assert.NotNil(t, fail(payments))
+	}
+	assert.Equal(t, StateOpen, payments.State())
+}
+
+func TestLoadConfigFile_JSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "breakers.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"defaults": {"timeout": "15s"},
+		"breakers": {"payments": {"failure_ratio": 0.5, "min_requests": 10}}
+	}`), 0o644))
+
+	fc, err := LoadConfigFile(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, ExpiryLazy, fc.ExpiryModel)
+	assert.Equal(t, 15*time.Second, fc.Defaults.Timeout)
+	assert.Equal(t, 0.5, fc.Breakers["payments"].FailureRatio)
+}
+
+func TestParseConfig_Invalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"unknown field": "breakers:\n  payments:\n    window: sliding\n",
+		"bad duration":  "defaults:\n  timeout: soon\n",
+		"bad model":     "expiry_model: eager\n",
+		"bad ratio":     "breakers:\n  payments:\n    failure_ratio: 2\n",
+		"bad bounds":    "defaults:\n  min_open_duration: 1h\n  max_open_duration: 1m\n",
+		"bad overrides": "defaults:\n  timeout: 1s\nbreakers:\n  payments:\n    timeout: -1s\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(data))
+			assert.ErrorIs(t, err, ErrInvalidConfig)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import "context"
+
+// ConfigProvider - источник настроек Circuit Breaker, например центральный
+// сервис конфигурации.
+type ConfigProvider interface {
+	// Get возвращает текущие настройки Circuit Breaker с именем name.
+	Get(ctx context.Context, name string) (Config, error)
+	// Watch возвращает канал новых версий настроек name.
+	// Канал закрывается провайдером при отмене ctx.
+	Watch(ctx context.Context, name string) (<-chan Config, error)
+}
+
+// WithConfigProvider подписывает Circuit Breaker на настройки из provider по его имени.
+// Каждая полученная версия применяется поверх текущих настроек с сохранением
+// состояния и счетчиков, нулевые поля Config настроек не меняют. Некорректные
+// версии и ошибки provider передаются в onError, текущие настройки при этом сохраняются.
+func WithConfigProvider(provider ConfigProvider, onError func(err error)) Option {
+	return func(s *settings) {
+		s.configProvider = provider
+		s.onConfigError = onError
+	}
+}
+
+// startConfigProvider получает начальные настройки и следит за их изменением
+// до вызова Close.
+func (cb *CircuitBreaker) startConfigProvider() {
+	s := cb.config()
+	name := cb.Name()
+
+	cb.background.Add(1)
+	go func() {
+		defer cb.background.Done()
+
+		c, err := s.configProvider.Get(cb.lifetime, name)
+		if err == nil {
+			err = cb.applyConfig(c)
+		}
+		cb.reportConfigError(err)
+
+		updates, err := s.configProvider.Watch(cb.lifetime, name)
+		if err != nil {
+			cb.reportConfigError(err)
+			return
+		}
+
+		for {
+			select {
+			case <-cb.lifetime.Done():
+				return
+			case c, ok := <-updates:
+				if !ok {
+					return
+				}
+				cb.reportConfigError(cb.applyConfig(c))
+			}
+		}
+	}()
+}
+
+// applyConfig проверяет и атомарно применяет c поверх текущих настроек.
+// Имя и место в иерархии не меняются.
+func (cb *CircuitBreaker) applyConfig(c Config) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	current := cb.config()
+	s := *current
+	for _, opt := range c.Options() {
+		opt(&s)
+	}
+	s.name = current.name
+	s.parent = current.parent
+
+	if err := s.validate(); err != nil {
+		return err
+	}
+
+	cb.settings.Store(&s)
+	return nil
+}
+
+func (cb *CircuitBreaker) reportConfigError(err error) {
+	if err == nil || cb.lifetime.Err() != nil {
+		return
+	}
+	if onError := cb.config().onConfigError; onError != nil {
+		onError(err)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testConfigProvider struct {
+	config  Config
+	updates chan Config
+}
+
+func (p *testConfigProvider) Get(_ context.Context, name string) (Config, error) {
+	if name != "payments" {
+		return Config{}, errors.New("unknown breaker")
+	}
+	return p.config, nil
+}
+
+func (p *testConfigProvider) Watch(context.Context, string) (<-chan Config, error) {
+	return p.updates, nil
+}
+
+func TestWithConfigProvider(t *testing.T) {
+	provider := &testConfigProvider{
+		config:  Config{Timeout: time.Minute},
+		updates: make(chan Config),
+	}
+
+	var mu sync.Mutex
+	var errs []error
+	cb := NewCircuitBreaker(
+		WithName("payments"),
+		WithMaxRequests(2),
+		WithConfigProvider(provider, func(err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		}),
+	)
+	defer cb.Close(context.Background())
+
+	assert.Eventually(t, func() bool {
+		return cb.config().timeout == time.Minute
+	}, time.Second, time.Millisecond)
+
+	assert.NotNil(t, fail(cb))
+	provider.updates <- Config{Name: "renamed", ConsecutiveFailures: 2}
+	assert.Eventually(t, func() bool {
+		return cb.config().readyToTrip(Counts{ConsecutiveFailures: 2})
+	}, time.Second, time.Millisecond)
+
+	assert.Equal(t, "payments", cb.Name())
+	assert.Equal(t, time.Minute, cb.config().timeout)
+	assert.Equal(t, uint32(2), cb.config().maxRequests)
+	assert.Equal(t, uint32(1), cb.Counts().ConsecutiveFailures)
+
+	provider.updates <- Config{Timeout: -time.Second}
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(errs) == 1
+	}, time.Second, time.Millisecond)
+	assert.ErrorIs(t, errs[0], ErrInvalidConfig)
+	assert.Equal(t, time.Minute, cb.config().timeout)
+}
+
+func TestWithConfigProvider_Close(t *testing.T) {
+	provider := &testConfigProvider{updates: make(chan Config)}
+	cb := NewCircuitBreaker(WithName("payments"), WithConfigProvider(provider, nil))
+
+	require.NoError(t, cb.Close(context.Background()))
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+)
+
+func TestNewFromConfig(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cb, err := NewFromConfig(Config{
+		Name:                "payments",
+		Labels:              map[string]string{"team": "billing"},
+		Timeout:             time.Minute,
+		ConsecutiveFailures: 2,
+		TimeProvider:        clock,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "payments", cb.Name())
+	assert.Equal(t, map[string]string{"team": "billing"}, cb.Labels())
+
+	assert.NotNil(t, fail(cb))
+	assert.Equal(t, StateClosed, cb.State())
+	assert.NotNil(t, fail(cb))
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Equal(t, clock.Now().Add(time.Minute), cb.current.Load().expiry)
+}
+
+func TestNewFromConfig_FailureRatio(t *testing.T) {
+	cb, err := NewFromConfig(Config{FailureRatio: 0.5, MinRequests: 4})
+	require.NoError(t, err)
+
+	assert.Nil(t, succeed(cb))
+	assert.NotNil(t, fail(cb))
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateClosed, cb.State())
+	assert.NotNil(t, fail(cb))
+	assert.Equal(t, StateOpen, cb.State())
+}
+
+func TestNewFromConfig_Defaults(t *testing.T) {
+	cb, err := NewFromConfig(Config{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 10*time.Second, cb.config().timeout)
+	assert.Equal(t, uint32(5), cb.config().maxRequests)
+}
+
+func TestNewFromConfig_LatencyStats(t *testing.T) {
+	cb, err := NewFromConfig(Config{LatencyStats: true})
+	require.NoError(t, err)
+
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, 1, cb.Stats().Latency.Samples)
+}
+
+func TestNewFromConfig_Invalid(t *testing.T) {
+	_, err := NewFromConfig(Config{FailureRatio: 1.5})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+
+	_, err = NewFromConfig(Config{MinOpenDuration: time.Hour, MaxOpenDuration: time.Minute})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+
+	_, err = NewFromConfig(Config{HealthCheck: func(context.Context) error { return nil }})
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
+
+func TestConfig_JSON(t *testing.T) {
+	c := Config{
+		Name:                "payments",
+		Timeout:             time.Second,
+		ConsecutiveFailures: 3,
+		ReadyToTrip:         func(Counts) bool { return false },
+	}
+
+	data, err := json.Marshal(c)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"payments","timeout":1000000000,"consecutive_failures":3}`, string(data))
+}
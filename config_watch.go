@@ -0,0 +1,92 @@
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// ConfigWatcher перечитывает файл конфигурации при его изменении и применяет
+// его к реестру через FileConfig.Apply.
+type ConfigWatcher struct {
+	path     string
+	registry *Registry
+	onError  func(err error)
+
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+}
+
+// WatchConfigFile начинает следить за файлом path. Некорректная конфигурация
+// не применяется, ошибка передается в onError.
+func WatchConfigFile(path string, r *Registry, onError func(err error)) (*ConfigWatcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	// Следим за каталогом, так как редакторы и системы деплоя часто
+	// заменяют файл целиком через rename.
+	path = filepath.Clean(path)
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	w := &ConfigWatcher{
+		path:     path,
+		registry: r,
+		onError:  onError,
+		watcher:  watcher,
+		done:     make(chan struct{}),
+	}
+	go w.run()
+
+	return w, nil
+}
+
+// Reload перечитывает файл и применяет его к реестру.
+func (w *ConfigWatcher) Reload() error {
+	fc, err := LoadConfigFile(w.path)
+	if err != nil {
+		return err
+	}
+	return fc.Apply(w.registry)
+}
+
+// Close прекращает слежение за файлом.
+func (w *ConfigWatcher) Close() error {
+	err := w.watcher.Close()
+	<-w.done
+	return err
+}
+
+func (w *ConfigWatcher) run() {
+	defer close(w.done)
+
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != w.path || event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			if err := w.Reload(); err != nil {
+				w.report(err)
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			w.report(err)
+		}
+	}
+}
+
+func (w *ConfigWatcher) report(err error) {
+	if w.onError != nil {
+		w.onError(err)
+	}
+}
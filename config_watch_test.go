@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, path, data string) {
+	t.Helper()
+	tmp := path + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, []byte(data), 0o644))
+	require.NoError(t, os.Rename(tmp, path))
+}
+
+func TestFileConfig_Apply(t *testing.T) {
+	fc, err := ParseConfig([]byte("defaults:\n  timeout: 1m\n  consecutive_failures: 5\n"))
+	require.NoError(t, err)
+
+	r := fc.Registry()
+	payments := r.Get("payments", WithOnStateChange(func(StateChange) {}))
+	assert.NotNil(t, fail(payments))
+	assert.NotNil(t, fail(payments))
+
+	fc, err = ParseConfig([]byte("breakers:\n  payments:\n    consecutive_failures: 3\n  search: {}\n"))
+	require.NoError(t, err)
+	require.NoError(t, fc.Apply(r))
+
+	// Поле, удаленное из файла, возвращается к значению по умолчанию,
+	// счетчики и опции из Get сохраняются.
+	assert.Equal(t, 10*time.Second, payments.config().timeout)
+	assert.NotNil(t, payments.config().onStateChange)
+	assert.Equal(t, uint32(2), payments.Counts().ConsecutiveFailures)
+	assert.NotNil(t, fail(payments))
+	assert.Equal(t, StateOpen, payments.State())
+
+	assert.Len(t, r.Filter(), 2)
+}
+
+func TestFileConfig_ApplyInvalid(t *testing.T) {
+	fc, err := ParseConfig([]byte("defaults:\n  timeout: 1m\n"))
+	require.NoError(t, err)
+	r := fc.Registry()
+	payments := r.Get("payments", WithMinOpenDuration(time.Minute))
+
+	// Сам файл корректен, но вместе с опциями из Get дает min > max.
+	fc, err = ParseConfig([]byte("defaults:\n  timeout: 1s\n  max_open_duration: 1s\n"))
+	require.NoError(t, err)
+	assert.ErrorIs(t, fc.Apply(r), ErrInvalidConfig)
+	assert.Equal(t, time.Minute, payments.config().timeout)
+	assert.Zero(t, payments.config().maxOpenDuration)
+}
+
+func TestWatchConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "breakers.yaml")
+	writeConfig(t, path, "breakers:\n  payments:\n    timeout: 1m\n")
+
+	fc, err := LoadConfigFile(path)
+	require.NoError(t, err)
+	r := fc.Registry()
+	payments := r.Get("payments")
+
+	errs := make(chan error, 10)
+	w, err := WatchConfigFile(path, r, func(err error) { errs <- err })
+	require.NoError(t, err)
+	defer w.Close()
+
+	writeConfig(t, path, "breakers:\n  payments:\n    timeout: soon\n")
+	select {
+	case err := <-errs:
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+	case <-time.After(5 * time.Second):
+		t.Fatal("invalid config was not reported")
+	}
+	assert.Equal(t, time.Minute, payments.config().timeout)
+
+	writeConfig(t, path, "breakers:\n  payments:\n    timeout: 2m\n")
+	assert.Eventually(t, func() bool {
+		return payments.config().timeout == 2*time.Minute
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -1,5 +1,11 @@
 package main
 
+import (
+	"math/rand/v2"
+	"runtime"
+	"sync/atomic"
+)
+
 type Counts struct {
 	Requests             uint32
 	TotalSuccess         uint32
@@ -8,26 +14,118 @@ type Counts struct {
 	ConsecutiveFailures  uint32
 }
 
-func (c *Counts) onRequest() {
-	c.Requests++
+// counterShard - часть счетчиков, занимающая отдельную кэш-линию,
+// чтобы параллельные запросы не конкурировали за одну и ту же память.
+type counterShard struct {
+	requests      atomic.Uint32
+	totalSuccess  atomic.Uint32
+	totalFailures atomic.Uint32
+	_             [128 - 3*4]byte
+}
+
+// shardedCounts - счетчики Circuit Breaker, которые можно обновлять без блокировки.
+// Итоговые значения распределены по шардам и суммируются только при чтении.
+// Внешнему коду они отдаются в виде снимка Counts.
+type shardedCounts struct {
+	shards []counterShard
+	// Значение TotalSuccess на момент последней ошибки. ConsecutiveSuccesses
+	// вычисляется как разница с ним, поэтому успешный запрос не пишет в общую память.
+	successMark         atomic.Uint32
+	consecutiveFailures atomic.Uint32
+}
+
+func newShardedCounts() shardedCounts {
+	n := 1
+	for n < runtime.GOMAXPROCS(0) {
+		n <<= 1
+	}
+
+	return shardedCounts{shards: make([]counterShard, n)}
+}
+
+func (c *shardedCounts) shard() *counterShard {
+	return &c.shards[rand.Uint32()&uint32(len(c.shards)-1)]
+}
+
+func (c *shardedCounts) onRequest() {
+	c.shard().requests.Add(1)
+}
+
+func (c *shardedCounts) onCancel() {
+	c.shard().requests.Add(^uint32(0))
 }
 
-func (c *Counts) onSuccess() {
-	c.TotalSuccess++
-	c.ConsecutiveSuccesses++
-	c.ConsecutiveFailures = 0
+func (c *shardedCounts) onSuccess() {
+	c.shard().totalSuccess.Add(1)
+	// запись только при необходимости, чтобы не конкурировать за кэш-линию
+	if c.consecutiveFailures.Load() != 0 {
+		c.consecutiveFailures.Store(0)
+	}
 }
 
-func (c *Counts) onFailure() {
-	c.TotalFailures++
-	c.ConsecutiveFailures++
-	c.ConsecutiveSuccesses = 0
+// onFailure вызывается под блокировкой Circuit Breaker.
+func (c *shardedCounts) onFailure() {
+	c.shard().totalFailures.Add(1)
+	c.consecutiveFailures.Add(1)
+	c.successMark.Store(c.totalSuccess())
 }
 
-func (c *Counts) clear() {
-	c.Requests = 0
-	c.TotalSuccess = 0
-	c.TotalFailures = 0
-	c.ConsecutiveSuccesses = 0
-	c.ConsecutiveFailures = 0
+func (c *shardedCounts) clear() {
+	for i := range c.shards {
+		c.shards[i].requests.Store(0)
+		c.shards[i].totalSuccess.Store(0)
+		c.shards[i].totalFailures.Store(0)
+	}
+	c.successMark.Store(0)
+	c.consecutiveFailures.Store(0)
+}
+
+// restore заменяет счетчики значениями counts.
+func (c *shardedCounts) restore(counts Counts) {
+	c.clear()
+	c.shards[0].requests.Store(counts.Requests)
+	c.shards[0].totalSuccess.Store(counts.TotalSuccess)
+	c.shards[0].totalFailures.Store(counts.TotalFailures)
+	c.successMark.Store(counts.TotalSuccess - min(counts.ConsecutiveSuccesses, counts.TotalSuccess))
+	c.consecutiveFailures.Store(counts.ConsecutiveFailures)
+}
+
+func (c *shardedCounts) requests() uint32 {
+	var sum uint32
+	for i := range c.shards {
+		sum += c.shards[i].requests.Load()
+	}
+	return sum
+}
+
+func (c *shardedCounts) totalSuccess() uint32 {
+	var sum uint32
+	for i := range c.shards {
+		sum += c.shards[i].totalSuccess.Load()
+	}
+	return sum
+}
+
+func (c *shardedCounts) consecutiveSuccesses() uint32 {
+	successes, mark := c.totalSuccess(), c.successMark.Load()
+	if successes < mark {
+		return 0
+	}
+	return successes - mark
+}
+
+func (c *shardedCounts) snapshot() Counts {
+	counts := Counts{
+		ConsecutiveFailures: c.consecutiveFailures.Load(),
+	}
+	for i := range c.shards {
+		counts.Requests += c.shards[i].requests.Load()
+		counts.TotalSuccess += c.shards[i].totalSuccess.Load()
+		counts.TotalFailures += c.shards[i].totalFailures.Load()
+	}
+	if mark := c.successMark.Load(); counts.TotalSuccess > mark {
+		counts.ConsecutiveSuccesses = counts.TotalSuccess - mark
+	}
+
+	return counts
 }
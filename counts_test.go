@@ -0,0 +1,39 @@
+package main
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShardedCounts(t *testing.T) {
+	c := newShardedCounts()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.onRequest()
+				c.onSuccess()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, Counts{800, 800, 0, 800, 0}, c.snapshot())
+
+	// ошибка сбрасывает ConsecutiveSuccesses
+	c.onRequest()
+	c.onFailure()
+	assert.Equal(t, Counts{801, 800, 1, 0, 1}, c.snapshot())
+
+	c.onRequest()
+	c.onSuccess()
+	assert.Equal(t, Counts{802, 801, 1, 1, 0}, c.snapshot())
+
+	c.clear()
+	assert.Equal(t, Counts{}, c.snapshot())
+}
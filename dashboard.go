@@ -0,0 +1,99 @@
+package main
+
+import "net/http"
+
+// DashboardHandler возвращает http.Handler с HTML-страницей, отображающей
+// все Circuit Breaker реестра: состояние, график доли ошибок и кнопки Trip и Reset.
+// Страница обращается к API AdminHandler, которое обслуживается тем же handler,
+// а options применяются к обоим. Монтируется под префиксом с завершающим
+// слешем, например:
+//
+//	mux.Handle("/circuit/", http.StripPrefix("/circuit", DashboardHandler(r)))
+func DashboardHandler(r *Registry, options ...AdminOption) http.Handler {
+	h := newAdminHandler(r, options)
+	mux := h.mux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(dashboardPage))
+	})
+	return h.wrap(mux)
+}
+
+const dashboardPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Circuit breakers</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { padding: 4px 12px; text-align: left; border-bottom: 1px solid #ddd; }
+.closed { color: #2a7d2a; } .open { color: #c0392b; } .half-open { color: #d68910; }
+.summary { margin-bottom: 1em; }
+</style>
+</head>
+<body>
+<h1>Circuit breakers</h1>
+<div class="summary" id="summary"></div>
+<table>
+<thead><tr><th>Name</th><th>State</th><th>Mode</th><th>Failure rate</th><th></th><th></th></tr></thead>
+<tbody id="breakers"></tbody>
+</table>
+<script>
+const historySize = 60;
+const history = {};
+
+function sparkline(values) {
+  const width = 120, height = 24;
+  const step = width / (historySize - 1);
+  const points = values.map((v, i) => (i * step).toFixed(1) + "," + (height - v * height).toFixed(1)).join(" ");
+  return '<svg width="' + width + '" height="' + height + '"><polyline fill="none" stroke="#c0392b" points="' + points + '"/></svg>';
+}
+
+function act(name, action) {
+  fetch("breakers/" + encodeURIComponent(name) + "/" + action, {method: "POST"}).then(refresh);
+}
+
+function cell(row, content) {
+  const td = row.insertCell();
+  td.textContent = content;
+  return td;
+}
+
+function button(row, label, name, action) {
+  const b = document.createElement("button");
+  b.textContent = label;
+  b.onclick = () => act(name, action);
+  row.insertCell().appendChild(b);
+}
+
+function refresh() {
+  Promise.all([fetch("stats").then(r => r.json()), fetch("breakers").then(r => r.json())]).then(([stats, breakers]) => {
+    document.getElementById("summary").textContent =
+      stats.total + " breakers: " + stats.closed + " closed, " + stats.open + " open, " + stats.half_open + " half-open";
+
+    const modes = {};
+    breakers.forEach(b => modes[b.name] = b.mode);
+
+    const body = document.getElementById("breakers");
+    body.innerHTML = "";
+    stats.breakers.forEach(b => {
+      const rates = history[b.name] = (history[b.name] || []).concat(b.failure_rate).slice(-historySize);
+      const row = body.insertRow();
+      cell(row, b.name);
+      cell(row, b.state).className = b.state;
+      cell(row, modes[b.name] || "off");
+      const rate = cell(row, (b.failure_rate * 100).toFixed(1) + "% ");
+      rate.insertAdjacentHTML("beforeend", sparkline(rates));
+      button(row, "Trip", b.name, "trip");
+      button(row, "Reset", b.name, "reset");
+    });
+  });
+}
+
+refresh();
+setInterval(refresh, 2000);
+</script>
+</body>
+</html>
+`
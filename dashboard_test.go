@@ -0,0 +1,36 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDashboardHandler(t *testing.T) {
+	r := NewRegistry()
+	cb := r.Get("payments")
+	mux := http.NewServeMux()
+	mux.Handle("/circuit/", http.StripPrefix("/circuit", DashboardHandler(r)))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/circuit/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), `fetch("stats")`)
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/circuit/stats", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"name":"payments"`)
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/circuit/breakers/payments/trip", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, StateOpen, cb.State())
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/circuit/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
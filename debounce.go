@@ -0,0 +1,91 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// WithDebouncedAlert вызывает onOpen, если Circuit Breaker не вернулся в Closed
+// в течение after после выхода из него, и onRecover при последующем возврате
+// в Closed. Переходы между Open и Half-Open не прерывают отсчет, а короткие
+// срабатывания, завершившиеся раньше after, не вызывают ни одного обработчика.
+// onOpen получает переход, с которого начался отсчет, onRecover - переход в Closed.
+// Обработчики вызываются в отдельной горутине, onRecover - всегда после onOpen.
+func WithDebouncedAlert(after time.Duration, onOpen, onRecover func(change StateChange)) Option {
+	return withObserver(&debouncedAlert{
+		after:     after,
+		onOpen:    onOpen,
+		onRecover: onRecover,
+		outages:   make(map[*CircuitBreaker]*outage),
+	})
+}
+
+type debouncedAlert struct {
+	after             time.Duration
+	onOpen, onRecover func(change StateChange)
+
+	mu      sync.Mutex
+	outages map[*CircuitBreaker]*outage
+}
+
+// outage - период от выхода из Closed до возврата в Closed.
+type outage struct {
+	start StateChange
+	// Закрывается при возврате в Closed, после заполнения recovery.
+	stop     chan struct{}
+	recovery StateChange
+}
+
+func (d *debouncedAlert) observeTransition(cb *CircuitBreaker, change StateChange) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch {
+	case change.From == StateClosed:
+		// Close запрещает запуск новых фоновых задач
+		if cb.lifetime.Err() != nil {
+			return
+		}
+		o := &outage{start: change, stop: make(chan struct{})}
+		d.outages[cb] = o
+		cb.background.Add(1)
+		go d.wait(cb, o)
+	case change.To == StateClosed:
+		if o, ok := d.outages[cb]; ok {
+			delete(d.outages, cb)
+			o.recovery = change
+			close(o.stop)
+		}
+	}
+}
+
+func (*debouncedAlert) observeCall(*CircuitBreaker, OutcomeRecord) {}
+
+func (*debouncedAlert) observeRejection(*CircuitBreaker, error) {}
+
+func (d *debouncedAlert) wait(cb *CircuitBreaker, o *outage) {
+	defer cb.background.Done()
+
+	timer := cb.clock().NewTimer(d.after)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+	case <-o.stop:
+		return
+	case <-cb.lifetime.Done():
+		return
+	}
+	if d.onOpen != nil {
+		d.onOpen(o.start)
+	}
+
+	select {
+	case <-o.stop:
+	case <-cb.lifetime.Done():
+		return
+	}
+	if d.onRecover != nil {
+		d.onRecover(o.recovery)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithDebouncedAlert(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		changes []StateChange
+	)
+	record := func(change StateChange) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, change)
+	}
+	recorded := func() []StateChange {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]StateChange(nil), changes...)
+	}
+
+	clock := clocktest.New(time.Now())
+	cb := NewCircuitBreaker(
+		WithName("payments"),
+		WithClock(clock),
+		WithTimeout(10*time.Second),
+		WithMaxRequests(1),
+		WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures > 0 }),
+		WithDebouncedAlert(time.Minute, record, record),
+	)
+	defer cb.Close(context.Background())
+
+	// короткое срабатывание не сообщается
+	assert.NotNil(t, fail(cb))
+	assert.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+	clock.Advance(20 * time.Second)
+	assert.Nil(t, succeed(cb))
+	assert.Eventually(t, func() bool { return clock.Timers() == 0 }, time.Second, time.Millisecond)
+
+	// переход из Half-Open обратно в Open не прерывает отсчет
+	assert.NotNil(t, fail(cb))
+	start := clock.Now()
+	assert.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+	clock.Advance(30 * time.Second)
+	assert.NotNil(t, fail(cb))
+	assert.Empty(t, recorded())
+
+	clock.Advance(30 * time.Second)
+	assert.Eventually(t, func() bool { return len(recorded()) == 1 }, time.Second, time.Millisecond)
+
+	clock.Advance(20 * time.Second)
+	assert.Nil(t, succeed(cb))
+	assert.Eventually(t, func() bool { return len(recorded()) == 2 }, time.Second, time.Millisecond)
+
+	alerts := recorded()
+	require.Len(t, alerts, 2)
+	assert.Equal(t, StateClosed, alerts[0].From)
+	assert.Equal(t, StateOpen, alerts[0].To)
+	assert.Equal(t, start, alerts[0].At)
+	assert.EqualError(t, alerts[0].Err, "fail")
+	assert.Equal(t, StateHalfOpen, alerts[1].From)
+	assert.Equal(t, StateClosed, alerts[1].To)
+	assert.Equal(t, start.Add(80*time.Second), alerts[1].At)
+}
+
+func TestWithDebouncedAlert_Close(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cb := NewCircuitBreaker(
+		WithClock(clock),
+		WithDebouncedAlert(time.Minute, func(StateChange) { t.Error("unexpected alert") }, nil),
+	)
+
+	cb.trip()
+	assert.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+	assert.NoError(t, cb.Close(context.Background()))
+	clock.Advance(time.Hour)
+}
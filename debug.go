@@ -0,0 +1,106 @@
+package main
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// debugPage - ответ DebugHandler.
+type debugPage struct {
+	Stats       statsJSON         `json:"stats"`
+	Transitions []stateChangeJSON `json:"transitions"`
+	// Последние события, если включена история, см. WithHistory.
+	Events []eventJSON `json:"events"`
+}
+
+func newDebugPage(cb *CircuitBreaker) debugPage {
+	page := debugPage{
+		Stats:       cb.Stats().toJSON(),
+		Transitions: []stateChangeJSON{},
+		Events:      []eventJSON{},
+	}
+	for _, change := range cb.Transitions(time.Time{}) {
+		page.Transitions = append(page.Transitions, newStateChangeJSON(change))
+	}
+	for _, event := range cb.History() {
+		page.Events = append(page.Events, newEventJSON(event))
+	}
+	return page
+}
+
+// DebugHandler возвращает http.Handler, отображающий состояние, счетчики, настройки,
+// последние переходы и события cb в JSON. Если запрос принимает text/html
+// или содержит параметр format=html, отображается HTML-страница.
+func DebugHandler(cb *CircuitBreaker) http.Handler {
+	return debugHandler(func(*http.Request) (*CircuitBreaker, bool) { return cb, true })
+}
+
+// RegistryDebugHandler возвращает DebugHandler для Circuit Breaker реестра,
+// имя которого задано параметром пути {name}, например:
+//
+//	mux.Handle("GET /debug/circuit/{name}", RegistryDebugHandler(r))
+func RegistryDebugHandler(r *Registry) http.Handler {
+	return debugHandler(func(req *http.Request) (*CircuitBreaker, bool) {
+		return r.Lookup(req.PathValue("name"))
+	})
+}
+
+type debugHandler func(req *http.Request) (*CircuitBreaker, bool)
+
+func (h debugHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	cb, ok := h(req)
+	if !ok {
+		http.NotFound(w, req)
+		return
+	}
+
+	page := newDebugPage(cb)
+	if req.URL.Query().Get("format") == "html" || strings.Contains(req.Header.Get("Accept"), "text/html") {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = debugTemplate.Execute(w, page)
+		return
+	}
+
+	writeIndentedJSON(w, page)
+}
+
+var debugTemplate = template.Must(template.New("debug").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Circuit breaker {{.Stats.Name}}</title></head>
+<body>
+<h1>{{.Stats.Name}}: {{.Stats.State}}</h1>
+<p>Since {{.Stats.Since.Format "2006-01-02T15:04:05Z07:00"}}{{if .Stats.LastError}}, last error: {{.Stats.LastError}}{{end}}</p>
+<h2>Counts</h2>
+<table>
+<tr><td>Requests</td><td>{{.Stats.Counts.Requests}}</td></tr>
+<tr><td>Successes</td><td>{{.Stats.Counts.TotalSuccess}}</td></tr>
+<tr><td>Failures</td><td>{{.Stats.Counts.TotalFailures}}</td></tr>
+<tr><td>Consecutive successes</td><td>{{.Stats.Counts.ConsecutiveSuccesses}}</td></tr>
+<tr><td>Consecutive failures</td><td>{{.Stats.Counts.ConsecutiveFailures}}</td></tr>
+<tr><td>Rejections total</td><td>{{.Stats.Rejections}}</td></tr>
+<tr><td>Failures total</td><td>{{.Stats.Failures}}</td></tr>
+</table>
+<h2>Config</h2>
+<table>
+<tr><td>Max requests</td><td>{{.Stats.Config.MaxRequests}}</td></tr>
+<tr><td>Timeout</td><td>{{.Stats.Config.Timeout}}</td></tr>
+<tr><td>Open duration</td><td>{{.Stats.Config.MinOpenDuration}} - {{.Stats.Config.MaxOpenDuration}}</td></tr>
+</table>
+<h2>Transitions</h2>
+<table>
+{{range .Transitions}}<tr><td>{{.At.Format "2006-01-02T15:04:05Z07:00"}}</td><td>{{.From}} &rarr; {{.To}}</td><td>{{.Reason}}</td><td>{{.Error}}</td></tr>
+{{end}}</table>
+<h2>Events</h2>
+<table>
+{{range .Events}}<tr><td>{{.At.Format "2006-01-02T15:04:05Z07:00"}}</td><td>{{.Type}}</td><td>{{.State}}</td><td>{{.Error}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugHandler(t *testing.T) {
+	clock := clocktest.New(time.Date(2024, 3, 1, 3, 0, 0, 0, time.UTC))
+	cb := NewCircuitBreaker(
+		WithName("payments"),
+		WithClock(clock),
+		WithHistory(10),
+		WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures > 0 }),
+	)
+	assert.NotNil(t, fail(cb))
+
+	rec := httptest.NewRecorder()
+	DebugHandler(cb).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/circuit/payments", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var page struct {
+		Stats struct {
+			Name  string `json:"name"`
+			State string `json:"state"`
+		} `json:"stats"`
+		Transitions []struct {
+			From, To, Reason, Error string
+		} `json:"transitions"`
+		Events []struct {
+			Type   string `json:"type"`
+			Change *struct {
+				To string `json:"to"`
+			} `json:"change"`
+		} `json:"events"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	assert.Equal(t, "payments", page.Stats.Name)
+	assert.Equal(t, "open", page.Stats.State)
+	require.Len(t, page.Transitions, 1)
+	assert.Equal(t, "closed", page.Transitions[0].From)
+	assert.Equal(t, "trip strategy", page.Transitions[0].Reason)
+	assert.Equal(t, "fail", page.Transitions[0].Error)
+	require.Len(t, page.Events, 3)
+	assert.Equal(t, "state-changed", page.Events[2].Type)
+	assert.Equal(t, "open", page.Events[2].Change.To)
+
+	rec = httptest.NewRecorder()
+	DebugHandler(cb).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?format=html", nil))
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "<h1>payments: open</h1>")
+	assert.Contains(t, rec.Body.String(), "closed &rarr; open")
+
+	rec = httptest.NewRecorder()
+	DebugHandler(cb).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestRegistryDebugHandler(t *testing.T) {
+	r := NewRegistry()
+	r.Get("payments")
+	mux := http.NewServeMux()
+	mux.Handle("GET /debug/circuit/{name}", RegistryDebugHandler(r))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/circuit/payments", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"name": "payments"`)
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/circuit/search", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	_, ok := r.Lookup("search")
+	assert.False(t, ok)
+}
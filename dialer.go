@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+)
+
+// ContextDialer устанавливает соединения, например *net.Dialer.
+type ContextDialer interface {
+	DialContext(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+type DialerOption func(*Dialer)
+
+// WithDialerKey задает ключ Circuit Breaker для соединения. По умолчанию DialAddressKey.
+func WithDialerKey(key func(network, address string) string) DialerOption {
+	return func(d *Dialer) {
+		d.key = key
+	}
+}
+
+// WithDialerFailurePolicy задает, какие ошибки соединения считаются неуспешными
+// запросами. По умолчанию DefaultDialFailurePolicy.
+func WithDialerFailurePolicy(isFailure func(err error) bool) DialerOption {
+	return func(d *Dialer) {
+		d.isFailure = isFailure
+	}
+}
+
+// DialAddressKey возвращает адрес соединения, например "10.0.0.1:443".
+func DialAddressKey(_, address string) string {
+	return address
+}
+
+// DefaultDialFailurePolicy считает неуспешными все ошибки соединения, включая
+// истечение времени ожидания, кроме отмены контекста вызывающей стороной.
+func DefaultDialFailurePolicy(err error) bool {
+	return !errors.Is(err, context.Canceled)
+}
+
+// Dialer устанавливает соединения через Circuit Breaker группы по адресу, чтобы
+// повторные попытки соединиться с недоступным хостом отклонялись сразу, ниже
+// уровня HTTP и gRPC. Учитывается только установка соединения, ошибки чтения
+// и записи не учитываются. Отклоненное соединение завершается *net.OpError,
+// оборачивающей ErrOpenState или ErrTooManyRequests:
+//
+//	dialer := NewDialer(&net.Dialer{Timeout: time.Second}, group)
+//	transport := &http.Transport{DialContext: dialer.DialContext}
+//	conn, err := grpc.NewClient(target, grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+//		return dialer.DialContext(ctx, "tcp", addr)
+//	}))
+type Dialer struct {
+	dialer    ContextDialer
+	group     *Group
+	key       func(network, address string) string
+	isFailure func(err error) bool
+}
+
+// NewDialer возвращает Dialer поверх dialer или net.Dialer без настроек, если dialer - nil.
+func NewDialer(dialer ContextDialer, group *Group, options ...DialerOption) *Dialer {
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+	d := &Dialer{dialer: dialer, group: group, key: DialAddressKey, isFailure: DefaultDialFailurePolicy}
+	for _, opt := range options {
+		opt(d)
+	}
+	return d
+}
+
+func (d *Dialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, address)
+}
+
+func (d *Dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	cb := d.group.Get(d.key(network, address))
+	var conn net.Conn
+	var called bool
+	var dialErr error
+	_, err := cb.ExecuteContext(ctx, func(ctx context.Context) (interface{}, error) {
+		called = true
+		conn, dialErr = d.dialer.DialContext(ctx, network, address)
+		if dialErr != nil && d.isFailure(dialErr) {
+			return nil, dialErr
+		}
+		return nil, nil
+	})
+	if !called {
+		if isRejection(err) {
+			err = &net.OpError{Op: "dial", Net: network, Err: fmt.Errorf("circuit breaker %s: %w", cb.Path(), err)}
+		}
+		return nil, err
+	}
+	return conn, dialErr
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// closedAddress возвращает адрес, соединения с которым отклоняются.
+func closedAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+	return address
+}
+
+func TestDialer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	group := tripOnFirstFailure()
+	dialer := NewDialer(nil, group)
+
+	conn, err := dialer.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+	assert.Equal(t, uint32(1), group.Get(listener.Addr().String()).Counts().TotalSuccess)
+
+	dead := closedAddress(t)
+	_, err = dialer.Dial("tcp", dead)
+	assert.ErrorIs(t, err, syscall.ECONNREFUSED)
+	assert.Equal(t, StateOpen, group.Get(dead).State())
+
+	_, err = dialer.DialContext(context.Background(), "tcp", dead)
+	assert.ErrorIs(t, err, ErrOpenState)
+	assert.EqualError(t, err, "dial tcp: circuit breaker "+dead+": "+ErrOpenState.Error())
+	var opErr *net.OpError
+	assert.ErrorAs(t, err, &opErr)
+}
+
+func TestDialer_Canceled(t *testing.T) {
+	group := tripOnFirstFailure()
+	dialer := NewDialer(nil, group)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	dead := closedAddress(t)
+	_, err := dialer.DialContext(ctx, "tcp", dead)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, StateClosed, group.Get(dead).State())
+}
+
+func TestDefaultDialFailurePolicy(t *testing.T) {
+	assert.True(t, DefaultDialFailurePolicy(&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}))
+	assert.True(t, DefaultDialFailurePolicy(context.DeadlineExceeded))
+	assert.False(t, DefaultDialFailurePolicy(&net.OpError{Op: "dial", Net: "tcp", Err: context.Canceled}))
+}
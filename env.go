@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// EnvOverrides разбирает переменные окружения вида <PREFIX>_<NAME>_<SETTING>=<value>,
+// например CB_PAYMENTS_TIMEOUT=30s, и возвращает опцию реестра, которая применяет их
+// поверх всех остальных настроек Circuit Breaker с именем NAME. SETTING - имя поля
+// файла конфигурации в верхнем регистре. В NAME все символы, кроме букв и цифр,
+// заменяются на "_", так что CB_PAYMENTS_API_TIMEOUT относится к "payments-api".
+func EnvOverrides(prefix string, environ []string) (RegistryOption, error) {
+	prefix = strings.ToUpper(prefix) + "_"
+	keys := configKeys()
+
+	values := make(map[string]map[string]string)
+	for _, kv := range environ {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		name, setting, ok := splitEnvKey(strings.TrimPrefix(key, prefix), keys)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s: unknown setting", ErrInvalidConfig, key)
+		}
+		if values[name] == nil {
+			values[name] = make(map[string]string)
+		}
+		values[name][setting] = value
+	}
+
+	overrides := make(map[string][]Option, len(values))
+	for name, settings := range values {
+		var c Config
+		if err := decodeEnvConfig(settings, &c); err != nil {
+			return nil, fmt.Errorf("%w: %s%s: %w", ErrInvalidConfig, prefix, name, err)
+		}
+		if err := c.validate(); err != nil {
+			return nil, fmt.Errorf("%s%s: %w", prefix, name, err)
+		}
+		if _, err := validSettings(c.Options()); err != nil {
+			return nil, fmt.Errorf("%s%s: %w", prefix, name, err)
+		}
+		overrides[name] = c.Options()
+	}
+
+	return func(r *Registry) {
+		r.envOverrides = overrides
+	}, nil
+}
+
+// envName приводит имя Circuit Breaker к виду, используемому в переменных окружения.
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, name)
+}
+
+// splitEnvKey отделяет имя Circuit Breaker от имени настройки по самому длинному
+// подходящему суффиксу.
+func splitEnvKey(key string, settings []string) (name, setting string, ok bool) {
+	for _, setting := range settings {
+		name, found := strings.CutSuffix(key, "_"+strings.ToUpper(setting))
+		if found && name != "" {
+			return name, setting, true
+		}
+	}
+	return "", "", false
+}
+
+// decodeEnvConfig разбирает значения так же, как поля файла конфигурации.
+func decodeEnvConfig(settings map[string]string, c *Config) error {
+	doc := yaml.Node{Kind: yaml.MappingNode}
+	for setting, value := range settings {
+		doc.Content = append(doc.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: setting},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: value},
+		)
+	}
+	return doc.Decode(c)
+}
+
+// configKeys возвращает имена скалярных полей файла конфигурации, начиная с самых длинных.
+func configKeys() []string {
+	var keys []string
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		key, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if key == "" || key == "-" || key == "name" || t.Field(i).Type.Kind() == reflect.Map {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return len(keys[i]) > len(keys[j])
+	})
+	return keys
+}
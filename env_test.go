@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvOverrides(t *testing.T) {
+	env, err := EnvOverrides("CB", []string{
+		"CB_PAYMENTS_API_TIMEOUT=30s",
+		"CB_PAYMENTS_API_MAX_OPEN_DURATION=1m",
+		"CB_SEARCH_CONSECUTIVE_FAILURES=2",
+		"HOME=/root",
+	})
+	require.NoError(t, err)
+
+	r := NewRegistry(
+		WithOverrides("payments-api", WithTimeout(time.Second)),
+		env,
+	)
+
+	payments := r.Get("payments-api", WithTimeout(time.Hour))
+	assert.Equal(t, 30*time.Second, payments.config().timeout)
+	assert.Equal(t, time.Minute, payments.config().maxOpenDuration)
+
+	search := r.Get("search")
+	assert.NotNil(t, fail(search))
+	assert.NotNil(t, fail(search))
+	assert.Equal(t, StateOpen, search.State())
+
+	assert.Equal(t, 10*time.Second, r.Get("other").config().timeout)
+}
+
+func TestEnvOverrides_Invalid(t *testing.T) {
+	for name, kv := range map[string]string{
+		"unknown setting": "CB_PAYMENTS_WINDOW=sliding",
+		"bad duration":    "CB_PAYMENTS_TIMEOUT=soon",
+		"bad number":      "CB_PAYMENTS_MAX_REQUESTS=many",
+		"bad ratio":       "CB_PAYMENTS_FAILURE_RATIO=2",
+		"bad timeout":     "CB_PAYMENTS_TIMEOUT=-1s",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := EnvOverrides("CB", []string{kv})
+			assert.ErrorIs(t, err, ErrInvalidConfig)
+		})
+	}
+}
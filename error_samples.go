@@ -0,0 +1,82 @@
+package main
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+)
+
+const (
+	// errorSampleSize - кол-во последних ошибок, по которым строится TopErrors.
+	errorSampleSize = 100
+	// topErrorsLimit - кол-во классов ошибок в Stats.TopErrors.
+	topErrorsLimit = 5
+)
+
+// ErrorClass - класс ошибок с одинаковым типом и текстом.
+type ErrorClass struct {
+	// Тип ошибки, например *net.OpError.
+	Type    string
+	Message string
+	// Кол-во ошибок класса среди последних 100 неуспешных запросов.
+	Count int
+}
+
+// errorClassKey хранит тип как reflect.Type, а не строку,
+// чтобы запись ошибки не выделяла память.
+type errorClassKey struct {
+	typ     reflect.Type
+	message string
+}
+
+// errorSamples - кольцевой буфер классов последних ошибок.
+type errorSamples struct {
+	mu      sync.Mutex
+	samples []errorClassKey
+	next    int
+}
+
+func (s *errorSamples) record(err error) {
+	key := errorClassKey{typ: reflect.TypeOf(err), message: err.Error()}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.samples) < errorSampleSize {
+		s.samples = append(s.samples, key)
+		return
+	}
+	s.samples[s.next] = key
+	s.next = (s.next + 1) % errorSampleSize
+}
+
+// top возвращает самые частые классы ошибок по убыванию.
+func (s *errorSamples) top(limit int) []ErrorClass {
+	s.mu.Lock()
+	counts := make(map[errorClassKey]int)
+	for _, key := range s.samples {
+		counts[key]++
+	}
+	s.mu.Unlock()
+
+	classes := make([]ErrorClass, 0, len(counts))
+	for key, count := range counts {
+		classes = append(classes, ErrorClass{Type: key.typ.String(), Message: key.message, Count: count})
+	}
+	sort.Slice(classes, func(i, j int) bool {
+		if classes[i].Count != classes[j].Count {
+			return classes[i].Count > classes[j].Count
+		}
+		if classes[i].Message != classes[j].Message {
+			return classes[i].Message < classes[j].Message
+		}
+		return classes[i].Type < classes[j].Type
+	})
+	if len(classes) > limit {
+		classes = classes[:limit]
+	}
+	if len(classes) == 0 {
+		return nil
+	}
+	return classes
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats_TopErrors(t *testing.T) {
+	cb := NewCircuitBreaker(WithReadyToTrip(func(Counts) bool { return false }))
+	assert.Nil(t, cb.Stats().TopErrors)
+
+	failWith := func(err error, n int) {
+		for i := 0; i < n; i++ {
+			_, _ = cb.Execute(func() (interface{}, error) { return nil, err })
+		}
+	}
+	failWith(context.DeadlineExceeded, 3)
+	failWith(fmt.Errorf("status %d", 503), 2)
+	failWith(errors.New("connection reset by peer"), 1)
+
+	assert.Equal(t, []ErrorClass{
+		{Type: "context.deadlineExceededError", Message: "context deadline exceeded", Count: 3},
+		{Type: "*errors.errorString", Message: "status 503", Count: 2},
+		{Type: "*errors.errorString", Message: "connection reset by peer", Count: 1},
+	}, cb.Stats().TopErrors)
+}
+
+func TestErrorSamples(t *testing.T) {
+	var s errorSamples
+	for i := 0; i < errorSampleSize; i++ {
+		s.record(errors.New("old"))
+	}
+	for i := 0; i < errorSampleSize-1; i++ {
+		s.record(fmt.Errorf("class %d", i%(topErrorsLimit+1)))
+	}
+
+	top := s.top(topErrorsLimit)
+	assert.Len(t, top, topErrorsLimit)
+	assert.Equal(t, ErrorClass{Type: "*errors.errorString", Message: "class 0", Count: 17}, top[0])
+	for _, class := range top {
+		assert.NotEqual(t, "class 5", class.Message)
+	}
+
+	s.record(errors.New("new"))
+	for _, class := range s.top(errorSampleSize) {
+		assert.NotEqual(t, "old", class.Message)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Listener обрабатывает событие EventBus. Ошибка передается в обработчик
+// ошибок шины и не влияет на других получателей.
+type Listener func(event Event) error
+
+// EventBus доставляет события Circuit Breaker нескольким получателям.
+// У каждого получателя своя очередь и горутина доставки, поэтому медленный,
+// возвращающий ошибку или паникующий получатель не мешает остальным.
+type EventBus struct {
+	mu        sync.RWMutex
+	listeners map[*busListener]struct{}
+	onError   func(err error)
+	dropped   atomic.Uint64
+}
+
+type busListener struct {
+	listener Listener
+	types    map[EventType]bool
+	queue    chan Event
+	done     chan struct{}
+}
+
+// NewEventBus создает шину событий. onError получает ошибки и паники получателей.
+func NewEventBus(onError func(err error)) *EventBus {
+	return &EventBus{
+		listeners: make(map[*busListener]struct{}),
+		onError:   onError,
+	}
+}
+
+// WithEventBus публикует события Circuit Breaker в bus.
+func WithEventBus(bus *EventBus) Option {
+	return func(s *settings) {
+		s.eventBus = bus
+	}
+}
+
+// Subscribe регистрирует listener для событий типов types, а если они не заданы -
+// для всех событий. bufferSize - размер очереди получателя: при ее переполнении
+// новые события для него отбрасываются. Возвращает функцию отписки, которая
+// дожидается обработки уже поставленных в очередь событий.
+func (b *EventBus) Subscribe(listener Listener, bufferSize int, types ...EventType) func() {
+	l := &busListener{
+		listener: listener,
+		queue:    make(chan Event, bufferSize),
+		done:     make(chan struct{}),
+	}
+	if len(types) > 0 {
+		l.types = make(map[EventType]bool, len(types))
+		for _, t := range types {
+			l.types[t] = true
+		}
+	}
+
+	b.mu.Lock()
+	b.listeners[l] = struct{}{}
+	b.mu.Unlock()
+
+	go b.deliver(l)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.listeners, l)
+			close(l.queue)
+			b.mu.Unlock()
+			<-l.done
+		})
+	}
+}
+
+// Publish ставит событие в очереди подходящих получателей.
+func (b *EventBus) Publish(event Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for l := range b.listeners {
+		if l.types != nil && !l.types[event.Type] {
+			continue
+		}
+		select {
+		case l.queue <- event:
+		default:
+			b.dropped.Add(1)
+		}
+	}
+}
+
+// Dropped возвращает кол-во событий, отброшенных из-за переполнения очередей
+// получателей, включая отписавшихся.
+func (b *EventBus) Dropped() uint64 {
+	return b.dropped.Load()
+}
+
+func (b *EventBus) deliver(l *busListener) {
+	defer close(l.done)
+
+	for event := range l.queue {
+		if err := b.call(l.listener, event); err != nil && b.onError != nil {
+			b.onError(err)
+		}
+	}
+}
+
+// call вызывает listener, превращая панику в ошибку.
+func (b *EventBus) call(listener Listener, event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("event listener panicked: %v", r)
+		}
+	}()
+	return listener(event)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventBus(t *testing.T) {
+	var mu sync.Mutex
+	var errs []error
+	bus := NewEventBus(func(err error) {
+		mu.Lock()
+		errs = append(errs, err)
+		mu.Unlock()
+	})
+
+	var all, changes []EventType
+	unsubscribeAll := bus.Subscribe(func(event Event) error {
+		all = append(all, event.Type)
+		return nil
+	}, 16)
+	unsubscribeChanges := bus.Subscribe(func(event Event) error {
+		changes = append(changes, event.Type)
+		return nil
+	}, 16, EventStateChanged)
+	unsubscribeBroken := bus.Subscribe(func(event Event) error {
+		if event.Type == EventRejected {
+			panic("boom")
+		}
+		return errors.New("listener failed")
+	}, 16, EventStateChanged, EventRejected)
+
+	cb := NewCircuitBreaker(
+		WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures > 0 }),
+		WithEventBus(bus),
+	)
+	assert.NotNil(t, fail(cb))
+	assert.Equal(t, ErrOpenState, succeed(cb))
+
+	unsubscribeAll()
+	unsubscribeChanges()
+	unsubscribeBroken()
+
+	assert.Equal(t, []EventType{EventAdmitted, EventFailure, EventStateChanged, EventRejected}, all)
+	assert.Equal(t, []EventType{EventStateChanged}, changes)
+	require.Len(t, errs, 2)
+	assert.EqualError(t, errs[0], "listener failed")
+	assert.EqualError(t, errs[1], "event listener panicked: boom")
+}
+
+func TestEventBus_SlowListener(t *testing.T) {
+	bus := NewEventBus(nil)
+	release := make(chan struct{})
+	unsubscribeSlow := bus.Subscribe(func(Event) error {
+		<-release
+		return nil
+	}, 1)
+
+	var received int
+	unsubscribe := bus.Subscribe(func(Event) error {
+		received++
+		return nil
+	}, 10)
+
+	for i := 0; i < 10; i++ {
+		bus.Publish(Event{Type: EventSuccess})
+	}
+	assert.Eventually(t, func() bool { return bus.Dropped() >= 8 }, time.Second, time.Millisecond)
+	dropped := bus.Dropped()
+
+	close(release)
+	unsubscribe()
+	unsubscribeSlow()
+	assert.Equal(t, 10, received)
+	// отписка не уменьшает счетчик
+	assert.Equal(t, dropped, bus.Dropped())
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// EventSchemaVersion - версия JSON-схемы Event. Увеличивается при несовместимом
+// изменении схемы, новые поля добавляются без смены версии.
+const EventSchemaVersion = 1
+
+type eventJSON struct {
+	Version int              `json:"version"`
+	Type    string           `json:"type"`
+	Name    string           `json:"name"`
+	At      time.Time        `json:"at"`
+	State   string           `json:"state"`
+	Error   string           `json:"error,omitempty"`
+	Change  *stateChangeJSON `json:"change,omitempty"`
+}
+
+func newEventJSON(event Event) eventJSON {
+	v := eventJSON{
+		Version: EventSchemaVersion,
+		Type:    event.Type.String(),
+		Name:    event.Name,
+		At:      event.At,
+		State:   event.State.String(),
+	}
+	if event.Err != nil {
+		v.Error = event.Err.Error()
+	}
+	if event.Type == EventStateChanged {
+		change := newStateChangeJSON(event.Change)
+		v.Change = &change
+	}
+	return v
+}
+
+// MarshalJSON кодирует событие по схеме версии EventSchemaVersion.
+func (e Event) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newEventJSON(e))
+}
+
+// EventEncoder пишет события в формате JSON Lines, по одному объекту на строку,
+// например для отправки в ELK или ClickHouse. Безопасен для конкурентного использования.
+type EventEncoder struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func NewEventEncoder(w io.Writer) *EventEncoder {
+	return &EventEncoder{enc: json.NewEncoder(w)}
+}
+
+func (e *EventEncoder) Encode(event Event) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.enc.Encode(newEventJSON(event))
+}
+
+// EncodeAll пишет события events, например полученные из Subscribe, до закрытия
+// канала или первой ошибки записи.
+func (e *EventEncoder) EncodeAll(events <-chan Event) error {
+	for event := range events {
+		if err := e.Encode(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
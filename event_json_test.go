@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvent_MarshalJSON(t *testing.T) {
+	at := time.Date(2024, 3, 1, 3, 0, 0, 0, time.UTC)
+	data, err := json.Marshal(Event{Type: EventRejected, Name: "payments", At: at, State: StateOpen, Err: ErrOpenState})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"version": 1, "type": "rejected", "name": "payments", "at": "2024-03-01T03:00:00Z",
+		"state": "open", "error": "state is open"
+	}`, string(data))
+
+	data, err = json.Marshal(Event{
+		Type:  EventStateChanged,
+		Name:  "payments",
+		At:    at,
+		State: StateOpen,
+		Change: StateChange{
+			Name:   "payments",
+			Labels: map[string]string{"team": "billing"},
+			From:   StateClosed,
+			To:     StateOpen,
+			At:     at,
+			Reason: ReasonTripStrategy,
+			Counts: Counts{Requests: 3, TotalFailures: 3, ConsecutiveFailures: 3},
+			Err:    errors.New("timeout"),
+		},
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"version": 1, "type": "state-changed", "name": "payments", "at": "2024-03-01T03:00:00Z", "state": "open",
+		"change": {
+			"name": "payments", "labels": {"team": "billing"}, "from": "closed", "to": "open",
+			"at": "2024-03-01T03:00:00Z", "reason": "trip strategy", "error": "timeout",
+			"counts": {"requests": 3, "total_success": 0, "total_failures": 3, "consecutive_successes": 0, "consecutive_failures": 3}
+		}
+	}`, string(data))
+}
+
+func TestEventEncoder(t *testing.T) {
+	cb := NewCircuitBreaker(WithName("payments"))
+	events, unsubscribe := cb.Subscribe()
+
+	assert.Nil(t, succeed(cb))
+	cb.Trip()
+	unsubscribe()
+
+	var buf bytes.Buffer
+	require.NoError(t, NewEventEncoder(&buf).EncodeAll(events))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	var types []string
+	for _, line := range lines {
+		var v struct {
+			Version int    `json:"version"`
+			Type    string `json:"type"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(line), &v))
+		assert.Equal(t, EventSchemaVersion, v.Version)
+		types = append(types, v.Type)
+	}
+	assert.Equal(t, []string{"admitted", "success", "state-changed"}, types)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type EventType int
+
+const (
+	// EventAdmitted - запрос допущен к выполнению.
+	EventAdmitted EventType = iota
+	// EventRejected - запрос отклонен, Err содержит причину.
+	EventRejected
+	// EventSuccess - запрос выполнен успешно.
+	EventSuccess
+	// EventFailure - запрос завершился ошибкой Err.
+	EventFailure
+	// EventStateChanged - смена состояния, описанная в Change.
+	EventStateChanged
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventAdmitted:
+		return "admitted"
+	case EventRejected:
+		return "rejected"
+	case EventSuccess:
+		return "success"
+	case EventFailure:
+		return "failure"
+	case EventStateChanged:
+		return "state-changed"
+	default:
+		return fmt.Sprintf("EventType(%d)", int(t))
+	}
+}
+
+// Event - событие Circuit Breaker.
+type Event struct {
+	Type EventType
+	// Путь Circuit Breaker в иерархии, см. Path.
+	Name string
+	At   time.Time
+	// Состояние в момент события. Для EventStateChanged - новое состояние.
+	State  State
+	Err    error
+	Change StateChange
+}
+
+// subscriptionBuffer - размер буфера канала подписки.
+const subscriptionBuffer = 64
+
+type subscriptions struct {
+	mu     sync.RWMutex
+	subs   map[chan Event]struct{}
+	active atomic.Int32
+	// Кол-во событий, не доставленных из-за переполнения буфера подписчика.
+	dropped atomic.Uint64
+}
+
+// Subscribe возвращает канал событий Circuit Breaker и функцию отписки,
+// которая закрывает канал. Событие не доставляется подписчику, если его буфер
+// заполнен, поэтому медленный подписчик не задерживает Execute и других подписчиков.
+func (cb *CircuitBreaker) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriptionBuffer)
+
+	s := &cb.subscriptions
+	s.mu.Lock()
+	if s.subs == nil {
+		s.subs = make(map[chan Event]struct{})
+	}
+	s.subs[ch] = struct{}{}
+	s.active.Add(1)
+	s.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.mu.Lock()
+			delete(s.subs, ch)
+			s.active.Add(-1)
+			close(ch)
+			s.mu.Unlock()
+		})
+	}
+}
+
+// DroppedEvents возвращает кол-во событий, не доставленных подписчикам.
+func (cb *CircuitBreaker) DroppedEvents() uint64 {
+	return cb.subscriptions.dropped.Load()
+}
+
+// subscribed сообщает, что у событий Circuit Breaker есть получатели.
+func (cb *CircuitBreaker) subscribed() bool {
+	s := cb.config()
+	return cb.subscriptions.active.Load() > 0 || s.eventBus != nil || s.historySize > 0
+}
+
+// publish рассылает событие подписчикам, в EventBus и в историю.
+// Name и At заполняются автоматически.
+func (cb *CircuitBreaker) publish(event Event) {
+	if !cb.subscribed() {
+		return
+	}
+	event.Name = cb.Path()
+	if event.At.IsZero() {
+		event.At = cb.config().timeProvider.Now()
+	}
+
+	if bus := cb.config().eventBus; bus != nil {
+		bus.Publish(event)
+	}
+	if size := cb.config().historySize; size > 0 {
+		cb.history.record(event, size)
+	}
+
+	s := &cb.subscriptions
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for ch := range s.subs {
+		select {
+		case ch <- event:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+func (cb *CircuitBreaker) publishOutcome(err error) {
+	event := Event{Type: EventSuccess, State: cb.State()}
+	if err != nil {
+		event.Type = EventFailure
+		event.Err = err
+	}
+	cb.publish(event)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker_Subscribe(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cb := NewCircuitBreaker(
+		WithName("payments"),
+		WithClock(clock),
+		WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures > 0 }),
+	)
+
+	events, unsubscribe := cb.Subscribe()
+	other, unsubscribeOther := cb.Subscribe()
+	defer unsubscribeOther()
+
+	assert.Nil(t, succeed(cb))
+	assert.NotNil(t, fail(cb))
+	assert.Equal(t, ErrOpenState, succeed(cb))
+	unsubscribe()
+	unsubscribe()
+
+	var got []Event
+	for event := range events {
+		got = append(got, event)
+	}
+	now := clock.Now()
+	assert.Equal(t, []Event{
+		{Type: EventAdmitted, Name: "payments", At: now, State: StateClosed},
+		{Type: EventSuccess, Name: "payments", At: now, State: StateClosed},
+		{Type: EventAdmitted, Name: "payments", At: now, State: StateClosed},
+		{Type: EventFailure, Name: "payments", At: now, State: StateClosed, Err: errors.New("fail")},
+		{Type: EventStateChanged, Name: "payments", At: now, State: StateOpen, Change: StateChange{
+			Name: "payments", From: StateClosed, To: StateOpen, At: now, Reason: ReasonTripStrategy,
+			Counts: Counts{Requests: 2, TotalSuccess: 1, TotalFailures: 1, ConsecutiveFailures: 1}, Err: errors.New("fail"),
+		}},
+		{Type: EventRejected, Name: "payments", At: now, State: StateOpen, Err: ErrOpenState},
+	}, got)
+	assert.Len(t, other, 6)
+}
+
+func TestCircuitBreaker_SubscribeSlowConsumer(t *testing.T) {
+	cb := NewCircuitBreaker()
+	events, unsubscribe := cb.Subscribe()
+	defer unsubscribe()
+
+	for i := 0; i < subscriptionBuffer; i++ {
+		assert.Nil(t, succeed(cb))
+	}
+
+	assert.Len(t, events, subscriptionBuffer)
+	assert.Equal(t, uint64(subscriptionBuffer), cb.DroppedEvents())
+}
@@ -0,0 +1,38 @@
+package main
+
+import "context"
+
+// ContextRequest - запрос, получающий контекст вызова.
+type ContextRequest func(ctx context.Context) (interface{}, error)
+
+// ExecuteContext выполняет req, как Execute, передавая ему ctx.
+// Если ctx уже отменен, запрос не выполняется и не учитывается.
+func (cb *CircuitBreaker) ExecuteContext(ctx context.Context, req ContextRequest) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if cb.config().pprofLabels {
+		return cb.executeLabeled(ctx, func(ctx context.Context) (interface{}, error) {
+			return cb.executeContext(ctx, req)
+		})
+	}
+	return cb.executeContext(ctx, req)
+}
+
+func (cb *CircuitBreaker) executeContext(ctx context.Context, req ContextRequest) (interface{}, error) {
+	if !cb.config().traceAnnotations {
+		return cb.run(func() (interface{}, error) {
+			return req(ctx)
+		})
+	}
+
+	response, err := cb.run(func() (interface{}, error) {
+		cb.annotateAdmission(ctx)
+		return req(ctx)
+	})
+	if isRejection(err) {
+		cb.annotateRejection(ctx, err)
+	}
+	return response, err
+}
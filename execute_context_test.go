@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker_ExecuteContext(t *testing.T) {
+	cb := NewCircuitBreaker()
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "value")
+	response, err := cb.ExecuteContext(ctx, func(ctx context.Context) (interface{}, error) {
+		return ctx.Value(key{}), nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "value", response)
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = cb.ExecuteContext(ctx, func(ctx context.Context) (interface{}, error) {
+		return nil, errors.New("must not be called")
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, uint32(1), cb.Counts().Requests)
+}
@@ -0,0 +1,26 @@
+package main
+
+import "expvar"
+
+// expvarName - имя переменной expvar, под которым PublishExpvar публикует реестр.
+const expvarName = "circuit_breakers"
+
+// PublishExpvar публикует статистику всех Circuit Breaker реестра в /debug/vars
+// под именем circuit_breakers. Значения вычисляются при каждом чтении.
+// Как и expvar.Publish, паникует при повторной публикации.
+func PublishExpvar(r *Registry) {
+	expvar.Publish(expvarName, ExpvarFunc(r))
+}
+
+// ExpvarFunc возвращает переменную expvar со статистикой Circuit Breaker реестра
+// по именам, например для публикации под другим именем.
+func ExpvarFunc(r *Registry) expvar.Func {
+	return func() any {
+		stats := make(map[string]Stats)
+		r.Range(func(name string, cb *CircuitBreaker) bool {
+			stats[name] = cb.Stats()
+			return true
+		})
+		return stats
+	}
+}
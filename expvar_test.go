@@ -0,0 +1,50 @@
+package main
+
+import (
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishExpvar(t *testing.T) {
+	r := NewRegistry(WithDefaults(WithClock(clocktest.New(time.Date(2024, 3, 1, 3, 0, 0, 0, time.UTC)))))
+	payments := r.Get("payments")
+	assert.Nil(t, succeed(payments))
+	search := r.Get("search")
+	assert.NotNil(t, fail(search))
+	search.trip()
+
+	config := `"config": {
+		"max_requests": 5, "timeout": "10s", "min_open_duration": "0s", "max_open_duration": "0s",
+		"healthy_reset_interval": "0s", "warmup_period": "0s"
+	}`
+	assert.JSONEq(t, `{
+		"payments": {
+			"version": 1, "name": "payments", "state": "closed", "since": "2024-03-01T03:00:00Z",
+			"counts": {"requests": 1, "total_success": 1, "total_failures": 0, "consecutive_successes": 1, "consecutive_failures": 0},
+			"success_rate": 1, "failure_rate": 0, "rejections_total": 0,
+			"successes_total": 1, "failures_total": 0,
+			"transitions": {"trips_total": 0, "reopens_total": 0, "recoveries_total": 0},
+			`+config+`
+		},
+		"search": {
+			"version": 1, "name": "search", "state": "open", "since": "2024-03-01T03:00:00Z",
+			"counts": {"requests": 0, "total_success": 0, "total_failures": 0, "consecutive_successes": 0, "consecutive_failures": 0},
+			"success_rate": 0, "failure_rate": 0, "rejections_total": 0,
+			"successes_total": 0, "failures_total": 1,
+			"transitions": {"trips_total": 1, "reopens_total": 0, "recoveries_total": 0},
+			"last_error": "fail", "last_failure_at": "2024-03-01T03:00:00Z",
+			"top_errors": [{"type": "*errors.errorString", "message": "fail", "count": 1}],
+			`+config+`
+		}
+	}`, ExpvarFunc(r).String())
+
+	if expvar.Get("circuit_breakers") == nil {
+		PublishExpvar(r)
+	}
+	assert.NotNil(t, expvar.Get("circuit_breakers"))
+	assert.Panics(t, func() { PublishExpvar(r) })
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// FastHTTPDoer - клиент fasthttp, например *fasthttp.Client или *fasthttp.HostClient.
+type FastHTTPDoer interface {
+	Do(req *fasthttp.Request, resp *fasthttp.Response) error
+	DoTimeout(req *fasthttp.Request, resp *fasthttp.Response, timeout time.Duration) error
+	DoDeadline(req *fasthttp.Request, resp *fasthttp.Response, deadline time.Time) error
+}
+
+type FastHTTPOption func(*FastHTTPClient)
+
+// WithFastHTTPKey задает ключ Circuit Breaker для запроса. По умолчанию FastHTTPHostKey.
+func WithFastHTTPKey(key func(req *fasthttp.Request) string) FastHTTPOption {
+	return func(c *FastHTTPClient) {
+		c.key = key
+	}
+}
+
+// WithFastHTTPStatusPolicy задает, какие статусы ответа считаются неуспешными
+// запросами. По умолчанию DefaultStatusPolicy.
+func WithFastHTTPStatusPolicy(isFailure func(status int) bool) FastHTTPOption {
+	return func(c *FastHTTPClient) {
+		c.isFailure = isFailure
+	}
+}
+
+// FastHTTPHostKey возвращает хост назначения запроса.
+func FastHTTPHostKey(req *fasthttp.Request) string {
+	return string(req.Host())
+}
+
+// FastHTTPHostMethodKey возвращает метод и хост назначения запроса, например "POST api.example.com".
+func FastHTTPHostMethodKey(req *fasthttp.Request) string {
+	return string(req.Header.Method()) + " " + string(req.Host())
+}
+
+// FastHTTPClient выполняет запросы fasthttp через Circuit Breaker группы по ключу
+// запроса, аналогично Transport: ошибки клиента и ответы с неуспешным статусом
+// учитываются как неуспешные запросы, Retry-After ответов 429 и 503 задает
+// длительность состояния Open, см. StatusError.
+type FastHTTPClient struct {
+	client    FastHTTPDoer
+	group     *Group
+	key       func(req *fasthttp.Request) string
+	isFailure func(status int) bool
+}
+
+func NewFastHTTPClient(client FastHTTPDoer, group *Group, options ...FastHTTPOption) *FastHTTPClient {
+	c := &FastHTTPClient{client: client, group: group, key: FastHTTPHostKey, isFailure: DefaultStatusPolicy}
+	for _, opt := range options {
+		opt(c)
+	}
+	return c
+}
+
+func (c *FastHTTPClient) Do(req *fasthttp.Request, resp *fasthttp.Response) error {
+	return c.do(req, resp, func() error {
+		return c.client.Do(req, resp)
+	})
+}
+
+func (c *FastHTTPClient) DoTimeout(req *fasthttp.Request, resp *fasthttp.Response, timeout time.Duration) error {
+	return c.do(req, resp, func() error {
+		return c.client.DoTimeout(req, resp, timeout)
+	})
+}
+
+func (c *FastHTTPClient) DoDeadline(req *fasthttp.Request, resp *fasthttp.Response, deadline time.Time) error {
+	return c.do(req, resp, func() error {
+		return c.client.DoDeadline(req, resp, deadline)
+	})
+}
+
+func (c *FastHTTPClient) do(req *fasthttp.Request, resp *fasthttp.Response, send func() error) error {
+	cb := c.group.Get(c.key(req))
+	_, err := cb.Execute(func() (interface{}, error) {
+		if err := send(); err != nil {
+			return nil, err
+		}
+		if status := resp.StatusCode(); c.isFailure(status) {
+			return nil, newStatusError(status, string(resp.Header.Peek(fasthttp.HeaderRetryAfter)), cb.clock().Now())
+		}
+		return nil, nil
+	})
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return nil
+	}
+	return err
+}
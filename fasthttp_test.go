@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func fastHTTPServer(t *testing.T, handler fasthttp.RequestHandler) *fasthttp.Client {
+	listener := fasthttputil.NewInmemoryListener()
+	go func() { _ = fasthttp.Serve(listener, handler) }()
+	t.Cleanup(func() { listener.Close() })
+	return &fasthttp.Client{Dial: func(string) (net.Conn, error) { return listener.Dial() }}
+}
+
+func fastHTTPGet(c *FastHTTPClient, url string) (int, error) {
+	req, resp := fasthttp.AcquireRequest(), fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.SetRequestURI(url)
+	err := c.Do(req, resp)
+	return resp.StatusCode(), err
+}
+
+func TestFastHTTPClient(t *testing.T) {
+	client := fastHTTPServer(t, func(ctx *fasthttp.RequestCtx) {
+		switch string(ctx.Host()) {
+		case "a.example.com":
+			ctx.Response.Header.Set(fasthttp.HeaderRetryAfter, "30")
+			ctx.SetStatusCode(fasthttp.StatusServiceUnavailable)
+		case "b.example.com":
+			ctx.SetStatusCode(fasthttp.StatusNotFound)
+		}
+	})
+	group := tripOnFirstFailure()
+	c := NewFastHTTPClient(client, group)
+
+	status, err := fastHTTPGet(c, "http://a.example.com/")
+	require.NoError(t, err)
+	assert.Equal(t, fasthttp.StatusServiceUnavailable, status)
+	_, err = fastHTTPGet(c, "http://a.example.com/")
+	assert.ErrorIs(t, err, ErrOpenState)
+
+	var statusErr *StatusError
+	require.ErrorAs(t, group.Get("a.example.com").LastError(), &statusErr)
+	assert.Equal(t, 30, int(statusErr.RetryAfter().Seconds()))
+
+	// 4xx не учитываются как ошибки, другой хост не затронут
+	status, err = fastHTTPGet(c, "http://b.example.com/")
+	require.NoError(t, err)
+	assert.Equal(t, fasthttp.StatusNotFound, status)
+	assert.Equal(t, StateClosed, group.Get("b.example.com").State())
+}
+
+func TestFastHTTPClient_Options(t *testing.T) {
+	client := fastHTTPServer(t, func(ctx *fasthttp.RequestCtx) {
+		ctx.SetStatusCode(fasthttp.StatusNotFound)
+	})
+	group := tripOnFirstFailure()
+	c := NewFastHTTPClient(client, group,
+		WithFastHTTPKey(FastHTTPHostMethodKey),
+		WithFastHTTPStatusPolicy(func(status int) bool { return status == fasthttp.StatusNotFound }),
+	)
+
+	_, err := fastHTTPGet(c, "http://a.example.com/")
+	require.NoError(t, err)
+	assert.Equal(t, StateOpen, group.Get("GET a.example.com").State())
+}
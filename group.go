@@ -0,0 +1,217 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// groupEvictionCloseTimeout ограничивает закрытие вытесненного Circuit Breaker.
+const groupEvictionCloseTimeout = 5 * time.Second
+
+type GroupOption func(*Group)
+
+// WithGroupOutlierDetector добавляет каждый созданный группой Circuit Breaker
+// в детектор выбросов detector.
+func WithGroupOutlierDetector(detector *OutlierDetector) GroupOption {
+	return func(g *Group) {
+		g.outliers = detector
+	}
+}
+
+// WithGroupCapacity ограничивает кол-во ключей в группе. При превышении
+// вытесняется Circuit Breaker ключа, к которому дольше всего не обращались.
+// Нулевое значение - без ограничения.
+func WithGroupCapacity(capacity int) GroupOption {
+	return func(g *Group) {
+		g.capacity = capacity
+	}
+}
+
+// WithGroupEviction задает обработчик вытеснения ключа из группы. Вытесненный
+// Circuit Breaker закрывается в фоне, см. CircuitBreaker.Close: его фоновые
+// задачи останавливаются, и он удаляется из дочерних у родителя.
+func WithGroupEviction(onEvict func(key string, cb *CircuitBreaker)) GroupOption {
+	return func(g *Group) {
+		g.onEvict = onEvict
+	}
+}
+
+// WithGroupRegistry сообщает группе, что factory создает Circuit Breaker
+// в реестре r под именем ключа, например через r.Get(key). Вытесненные из
+// группы ключи удаляются из r, его статистики и метрик. Удаление по времени
+// без обращений есть только у группы, у Registry его нет.
+func WithGroupRegistry(r *Registry) GroupOption {
+	return func(g *Group) {
+		g.registry = r
+	}
+}
+
+// WithGroupIdleTTL включает удаление ключей, к которым не обращались дольше ttl.
+// Устаревшие ключи удаляются при обращениях к группе и при вызове Reap,
+// для них вызывается обработчик WithGroupEviction. Circuit Breaker, созданные
+// в реестре, остаются в нем, если не задан WithGroupRegistry.
+func WithGroupIdleTTL(ttl time.Duration) GroupOption {
+	return func(g *Group) {
+		g.idleTTL = ttl
+	}
+}
+
+func WithGroupTimeProvider(timeProvider TimeProvider) GroupOption {
+	return func(g *Group) {
+		g.timeProvider = timeProvider
+	}
+}
+
+// NewGroup создает группу, в которой для каждого нового ключа
+// Circuit Breaker создается функцией factory.
+func NewGroup(factory func(key string) *CircuitBreaker, options ...GroupOption) *Group {
+	g := &Group{
+		factory:      factory,
+		breakers:     make(map[string]*list.Element),
+		lru:          list.New(),
+		timeProvider: &RealTimeTimeProvider{},
+	}
+
+	for _, opt := range options {
+		opt(g)
+	}
+
+	return g
+}
+
+// Group хранит отдельный Circuit Breaker для каждого динамического ключа
+// (хоста, клиента, шарда), чтобы проблемы одного ключа не влияли на остальные.
+type Group struct {
+	factory  func(key string) *CircuitBreaker
+	outliers *OutlierDetector
+	capacity int
+	onEvict  func(key string, cb *CircuitBreaker)
+	registry *Registry
+	// Время жизни ключа без обращений. Нулевое значение - без ограничения.
+	idleTTL      time.Duration
+	timeProvider TimeProvider
+
+	mu       sync.Mutex
+	breakers map[string]*list.Element
+	// Ключи в порядке последнего обращения, в начале - самые свежие.
+	lru *list.List
+}
+
+type groupEntry struct {
+	key      string
+	cb       *CircuitBreaker
+	lastUsed time.Time
+}
+
+// Get возвращает Circuit Breaker для ключа key, создавая его при первом обращении.
+func (g *Group) Get(key string) *CircuitBreaker {
+	var now time.Time
+	if g.idleTTL > 0 {
+		now = g.timeProvider.Now()
+	}
+
+	g.mu.Lock()
+
+	evicted := g.removeIdle(now, nil)
+
+	el, ok := g.breakers[key]
+	if ok {
+		g.lru.MoveToFront(el)
+	} else {
+		cb := g.factory(key)
+		el = g.lru.PushFront(&groupEntry{key: key, cb: cb})
+		g.breakers[key] = el
+		if g.outliers != nil {
+			g.outliers.Add(key, cb)
+		}
+	}
+	entry := el.Value.(*groupEntry)
+	entry.lastUsed = now
+
+	for g.capacity > 0 && g.lru.Len() > g.capacity {
+		evicted = append(evicted, g.removeElement(g.lru.Back()))
+	}
+
+	g.mu.Unlock()
+
+	g.evicted(evicted)
+
+	return entry.cb
+}
+
+// Reap удаляет ключи, к которым не обращались дольше WithGroupIdleTTL,
+// и возвращает их кол-во.
+func (g *Group) Reap() int {
+	if g.idleTTL <= 0 {
+		return 0
+	}
+
+	now := g.timeProvider.Now()
+
+	g.mu.Lock()
+	evicted := g.removeIdle(now, nil)
+	g.mu.Unlock()
+
+	g.evicted(evicted)
+
+	return len(evicted)
+}
+
+// removeIdle удаляет устаревшие ключи с конца списка LRU.
+func (g *Group) removeIdle(now time.Time, evicted []*groupEntry) []*groupEntry {
+	if g.idleTTL <= 0 {
+		return evicted
+	}
+
+	for el := g.lru.Back(); el != nil; el = g.lru.Back() {
+		if now.Sub(el.Value.(*groupEntry).lastUsed) < g.idleTTL {
+			break
+		}
+		evicted = append(evicted, g.removeElement(el))
+	}
+
+	return evicted
+}
+
+func (g *Group) evicted(entries []*groupEntry) {
+	for _, e := range entries {
+		if g.registry != nil {
+			g.registry.unregister(e.key, e.cb)
+		}
+		if g.onEvict != nil {
+			g.onEvict(e.key, e.cb)
+		}
+		go closeEvicted(e.cb)
+	}
+}
+
+// closeEvicted закрывает вытесненный cb, чтобы освободить его фоновые горутины.
+func closeEvicted(cb *CircuitBreaker) {
+	ctx, cancel := context.WithTimeout(context.Background(), groupEvictionCloseTimeout)
+	defer cancel()
+	_ = cb.Close(ctx)
+}
+
+// Execute выполняет запрос через Circuit Breaker ключа key.
+func (g *Group) Execute(key string, req Request) (interface{}, error) {
+	return g.Get(key).Execute(req)
+}
+
+// Len возвращает кол-во ключей в группе.
+func (g *Group) Len() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.lru.Len()
+}
+
+func (g *Group) removeElement(el *list.Element) *groupEntry {
+	e := g.lru.Remove(el).(*groupEntry)
+	delete(g.breakers, e.key)
+	if g.outliers != nil {
+		g.outliers.Remove(e.key)
+	}
+	return e
+}
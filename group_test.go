@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+)
+
+func TestGroup_Execute(t *testing.T) {
+	var created []string
+	g := NewGroup(func(key string) *CircuitBreaker {
+		created = append(created, key)
+		return NewCircuitBreaker(WithReadyToTrip(func(counts Counts) bool {
+			return counts.ConsecutiveFailures >= 2
+		}))
+	})
+
+	for i := 0; i < 2; i++ {
+		_, err := g.Execute("a.example.com", func() (interface{}, error) {
+			return nil, errors.New("fail")
+		})
+		assert.NotNil(t, err)
+	}
+
+	// ошибки одного ключа не влияют на другой
+	_, err := g.Execute("a.example.com", func() (interface{}, error) { return nil, nil })
+	assert.ErrorIs(t, err, ErrOpenState)
+	_, err = g.Execute("b.example.com", func() (interface{}, error) { return nil, nil })
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"a.example.com", "b.example.com"}, created)
+	assert.Equal(t, 2, g.Len())
+	assert.Same(t, g.Get("a.example.com"), g.Get("a.example.com"))
+}
+
+func TestGroup_OutlierDetector(t *testing.T) {
+	detector := NewOutlierDetector()
+	g := NewGroup(func(string) *CircuitBreaker {
+		return NewCircuitBreaker()
+	}, WithGroupOutlierDetector(detector))
+
+	cb := g.Get("a")
+	assert.Same(t, cb, detector.breakers["a"])
+}
+
+func TestGroup_Capacity(t *testing.T) {
+	var evicted []string
+	g := NewGroup(func(string) *CircuitBreaker {
+		return NewCircuitBreaker()
+	},
+		WithGroupCapacity(2),
+		WithGroupEviction(func(key string, cb *CircuitBreaker) {
+			evicted = append(evicted, key)
+		}),
+	)
+
+	a := g.Get("a")
+	g.Get("b")
+	// обращение к "a" делает "b" самым старым ключом
+	g.Get("a")
+	g.Get("c")
+
+	assert.Equal(t, []string{"b"}, evicted)
+	assert.Equal(t, 2, g.Len())
+	assert.Same(t, a, g.Get("a"))
+
+	// вытесненный ключ создается заново
+	g.Get("b")
+	assert.Equal(t, []string{"b", "c"}, evicted)
+}
+
+func TestGroup_IdleTTL(t *testing.T) {
+	clock := clocktest.New(time.Unix(1000, 0))
+
+	var evicted []string
+	g := NewGroup(func(string) *CircuitBreaker {
+		return NewCircuitBreaker()
+	},
+		WithGroupIdleTTL(time.Minute),
+		WithGroupTimeProvider(clock),
+		WithGroupEviction(func(key string, cb *CircuitBreaker) {
+			evicted = append(evicted, key)
+		}),
+	)
+
+	g.Get("once")
+	g.Get("hot")
+
+	clock.Advance(40 * time.Second)
+	g.Get("hot")
+	assert.Zero(t, g.Reap())
+
+	// к "once" не обращались больше минуты
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, 1, g.Reap())
+	assert.Equal(t, []string{"once"}, evicted)
+	assert.Equal(t, 1, g.Len())
+
+	// устаревшие ключи удаляются и при обращении к группе
+	clock.Advance(time.Hour)
+	g.Get("new")
+	assert.Equal(t, []string{"once", "hot"}, evicted)
+	assert.Equal(t, 1, g.Len())
+}
+
+func TestGroup_Registry(t *testing.T) {
+	clock := clocktest.New(time.Unix(1000, 0))
+	r := NewRegistry()
+	g := NewGroup(func(key string) *CircuitBreaker {
+		return r.Get(key)
+	},
+		WithGroupRegistry(r),
+		WithGroupCapacity(2),
+		WithGroupIdleTTL(time.Minute),
+		WithGroupTimeProvider(clock),
+	)
+
+	g.Get("a")
+	g.Get("b")
+	g.Get("c")
+	assert.Equal(t, 2, r.Stats().Total)
+	_, ok := r.Lookup("a")
+	assert.False(t, ok)
+
+	// ключи, удаленные по TTL, удаляются и из статистики реестра
+	clock.Advance(2 * time.Minute)
+	assert.Equal(t, 2, g.Reap())
+	assert.Zero(t, r.Stats().Total)
+}
+
+func TestGroup_EvictionClose(t *testing.T) {
+	parent := NewCircuitBreaker(WithName("payments"))
+	g := NewGroup(func(key string) *CircuitBreaker {
+		return NewCircuitBreaker(WithName(key), WithParent(parent))
+	}, WithGroupCapacity(1))
+
+	evicted := g.Get("a")
+	current := g.Get("b")
+
+	// вытесненный Circuit Breaker закрывается и отсоединяется от родителя
+	assert.Eventually(t, func() bool { return evicted.lifetime.Err() != nil }, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool { return len(parent.Children()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, []*CircuitBreaker{current}, parent.Children())
+	assert.NoError(t, current.lifetime.Err())
+}
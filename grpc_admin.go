@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+
+	"github.com/raymanovg/circuit-breaker/adminpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// RegisterGRPCAdmin регистрирует в server сервис adminpb.CircuitBreakerAdmin
+// для управления Circuit Breaker реестра r. WatchEvents передает события bus
+// и возвращает codes.FailedPrecondition, если bus не задан.
+func RegisterGRPCAdmin(server grpc.ServiceRegistrar, r *Registry, bus *EventBus) {
+	adminpb.RegisterCircuitBreakerAdminServer(server, &grpcAdmin{registry: r, bus: bus})
+}
+
+type grpcAdmin struct {
+	adminpb.UnimplementedCircuitBreakerAdminServer
+
+	registry *Registry
+	bus      *EventBus
+}
+
+func (a *grpcAdmin) ListBreakers(context.Context, *adminpb.ListBreakersRequest) (*adminpb.ListBreakersResponse, error) {
+	resp := &adminpb.ListBreakersResponse{}
+	a.registry.Range(func(name string, cb *CircuitBreaker) bool {
+		resp.Breakers = append(resp.Breakers, newBreakerProto(name, cb))
+		return true
+	})
+	return resp, nil
+}
+
+func (a *grpcAdmin) GetBreaker(_ context.Context, req *adminpb.GetBreakerRequest) (*adminpb.Breaker, error) {
+	return a.do(req.GetName(), func(*CircuitBreaker) {})
+}
+
+func (a *grpcAdmin) Trip(_ context.Context, req *adminpb.TripRequest) (*adminpb.Breaker, error) {
+	return a.do(req.GetName(), (*CircuitBreaker).Trip)
+}
+
+func (a *grpcAdmin) Reset(_ context.Context, req *adminpb.ResetRequest) (*adminpb.Breaker, error) {
+	return a.do(req.GetName(), (*CircuitBreaker).Reset)
+}
+
+// do выполняет fn над Circuit Breaker name и возвращает его новое состояние.
+func (a *grpcAdmin) do(name string, fn func(cb *CircuitBreaker)) (*adminpb.Breaker, error) {
+	cb, ok := a.registry.Lookup(name)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "circuit breaker %q not found", name)
+	}
+	fn(cb)
+	return newBreakerProto(name, cb), nil
+}
+
+func (a *grpcAdmin) WatchEvents(req *adminpb.WatchEventsRequest, stream adminpb.CircuitBreakerAdmin_WatchEventsServer) error {
+	if a.bus == nil {
+		return status.Error(codes.FailedPrecondition, "event bus is not configured")
+	}
+
+	types := []EventType{EventStateChanged}
+	if len(req.GetTypes()) > 0 {
+		types = types[:0]
+		for _, t := range req.GetTypes() {
+			if t == adminpb.EventType_EVENT_TYPE_UNSPECIFIED {
+				return status.Error(codes.InvalidArgument, "event type is unspecified")
+			}
+			if t < 0 || t > adminpb.EventType_EVENT_TYPE_STATE_CHANGED {
+				return status.Errorf(codes.InvalidArgument, "unknown event type %d", t)
+			}
+			types = append(types, EventType(t-1))
+		}
+	}
+	breakers := make(map[string]bool)
+	for _, name := range req.GetNames() {
+		breakers[name] = true
+	}
+
+	events := make(chan Event, eventStreamBuffer)
+	unsubscribe := a.bus.Subscribe(func(event Event) error {
+		if len(breakers) > 0 && !breakers[event.Name] {
+			return nil
+		}
+		select {
+		case events <- event:
+		default:
+		}
+		return nil
+	}, eventStreamBuffer, types...)
+	defer unsubscribe()
+
+	// заголовки сообщают клиенту, что подписка оформлена
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event := <-events:
+			if err := stream.Send(newEventProto(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func newBreakerProto(name string, cb *CircuitBreaker) *adminpb.Breaker {
+	stats := cb.Stats()
+	b := &adminpb.Breaker{
+		Name:            name,
+		State:           stateProto(stats.State),
+		Mode:            cb.Mode().String(),
+		Since:           timestamppb.New(stats.Since),
+		Counts:          countsProto(stats.Counts),
+		RejectionsTotal: stats.Rejections,
+		FailuresTotal:   stats.Failures,
+	}
+	if stats.LastError != nil {
+		b.LastError = stats.LastError.Error()
+		b.LastFailureAt = timestamppb.New(stats.LastFailureAt)
+	}
+	return b
+}
+
+func newEventProto(event Event) *adminpb.Event {
+	e := &adminpb.Event{
+		Type:  adminpb.EventType(event.Type + 1),
+		Name:  event.Name,
+		At:    timestamppb.New(event.At),
+		State: stateProto(event.State),
+	}
+	if event.Err != nil {
+		e.Error = event.Err.Error()
+	}
+	if event.Type == EventStateChanged {
+		e.Change = &adminpb.StateChange{
+			From:   stateProto(event.Change.From),
+			To:     stateProto(event.Change.To),
+			Reason: string(event.Change.Reason),
+			Counts: countsProto(event.Change.Counts),
+		}
+		if event.Change.Err != nil {
+			e.Change.Error = event.Change.Err.Error()
+		}
+	}
+	return e
+}
+
+// stateProto сопоставляет State значению adminpb.State, в котором ноль не используется.
+func stateProto(state State) adminpb.State {
+	return adminpb.State(state + 1)
+}
+
+func countsProto(counts Counts) *adminpb.Counts {
+	return &adminpb.Counts{
+		Requests:             counts.Requests,
+		TotalSuccess:         counts.TotalSuccess,
+		TotalFailures:        counts.TotalFailures,
+		ConsecutiveSuccesses: counts.ConsecutiveSuccesses,
+		ConsecutiveFailures:  counts.ConsecutiveFailures,
+	}
+}
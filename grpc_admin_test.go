@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/adminpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func grpcAdminClient(t *testing.T, r *Registry, bus *EventBus) adminpb.CircuitBreakerAdminClient {
+	conn := bufconnClient(t, func(server *grpc.Server) {
+		RegisterGRPCAdmin(server, r, bus)
+	})
+	return adminpb.NewCircuitBreakerAdminClient(conn)
+}
+
+// bufconnClient запускает in-memory gRPC-сервер с сервисами register и подключается к нему.
+func bufconnClient(t *testing.T, register func(server *grpc.Server), options ...grpc.DialOption) *grpc.ClientConn {
+	return bufconnServerClient(t, nil, register, options...)
+}
+
+// bufconnServerClient - bufconnClient с настройками сервера serverOptions.
+func bufconnServerClient(t *testing.T, serverOptions []grpc.ServerOption, register func(server *grpc.Server), options ...grpc.DialOption) *grpc.ClientConn {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(serverOptions...)
+	register(server)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn", append([]grpc.DialOption{
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	}, options...)...)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestGRPCAdmin(t *testing.T) {
+	r := NewRegistry()
+	payments := r.Get("payments")
+	assert.NotNil(t, fail(payments))
+	r.Get("search")
+	client := grpcAdminClient(t, r, nil)
+	ctx := context.Background()
+
+	b, err := client.Trip(ctx, &adminpb.TripRequest{Name: "payments"})
+	require.NoError(t, err)
+	assert.Equal(t, adminpb.State_STATE_OPEN, b.GetState())
+	assert.Equal(t, StateOpen, payments.State())
+
+	list, err := client.ListBreakers(ctx, &adminpb.ListBreakersRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.GetBreakers(), 2)
+	assert.Equal(t, "payments", list.GetBreakers()[0].GetName())
+	assert.Equal(t, "search", list.GetBreakers()[1].GetName())
+	assert.Equal(t, adminpb.State_STATE_CLOSED, list.GetBreakers()[1].GetState())
+
+	b, err = client.GetBreaker(ctx, &adminpb.GetBreakerRequest{Name: "payments"})
+	require.NoError(t, err)
+	assert.Equal(t, "fail", b.GetLastError())
+	assert.Equal(t, uint64(1), b.GetFailuresTotal())
+	assert.Equal(t, "off", b.GetMode())
+
+	b, err = client.Reset(ctx, &adminpb.ResetRequest{Name: "payments"})
+	require.NoError(t, err)
+	assert.Equal(t, adminpb.State_STATE_CLOSED, b.GetState())
+
+	_, err = client.Trip(ctx, &adminpb.TripRequest{Name: "orders"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	stream, err := client.WatchEvents(ctx, &adminpb.WatchEventsRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestGRPCAdmin_WatchEvents(t *testing.T) {
+	bus := NewEventBus(nil)
+	r := NewRegistry(WithDefaults(WithEventBus(bus)))
+	payments := r.Get("payments")
+	search := r.Get("search")
+	client := grpcAdminClient(t, r, bus)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.WatchEvents(ctx, &adminpb.WatchEventsRequest{
+		Names: []string{"payments"},
+		Types: []adminpb.EventType{adminpb.EventType_EVENT_TYPE_STATE_CHANGED, adminpb.EventType_EVENT_TYPE_REJECTED},
+	})
+	require.NoError(t, err)
+	// заголовки ответа отправляются после подписки на шину
+	_, err = stream.Header()
+	require.NoError(t, err)
+
+	search.Trip()
+	payments.Trip()
+	assert.Equal(t, ErrOpenState, succeed(payments))
+
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, adminpb.EventType_EVENT_TYPE_STATE_CHANGED, event.GetType())
+	assert.Equal(t, "payments", event.GetName())
+	assert.Equal(t, adminpb.State_STATE_OPEN, event.GetChange().GetTo())
+	assert.Equal(t, "manual", event.GetChange().GetReason())
+
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, adminpb.EventType_EVENT_TYPE_REJECTED, event.GetType())
+	assert.Equal(t, "state is open", event.GetError())
+}
+
+func TestGRPCAdmin_WatchEventsInvalidType(t *testing.T) {
+	bus := NewEventBus(nil)
+	client := grpcAdminClient(t, NewRegistry(), bus)
+	ctx := context.Background()
+
+	for _, eventType := range []adminpb.EventType{
+		adminpb.EventType_EVENT_TYPE_UNSPECIFIED,
+		adminpb.EventType_EVENT_TYPE_STATE_CHANGED + 1,
+		-1,
+	} {
+		stream, err := client.WatchEvents(ctx, &adminpb.WatchEventsRequest{Types: []adminpb.EventType{eventType}})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), eventType)
+	}
+}
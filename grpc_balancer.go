@@ -0,0 +1,98 @@
+package main
+
+import (
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/resolver"
+)
+
+type GRPCBalancerOption func(*grpcPickerBuilder)
+
+// WithGRPCBalancerKey задает ключ Circuit Breaker для адреса сервера.
+// По умолчанию GRPCAddressKey.
+func WithGRPCBalancerKey(key func(addr resolver.Address) string) GRPCBalancerOption {
+	return func(b *grpcPickerBuilder) {
+		b.key = key
+	}
+}
+
+// WithGRPCBalancerFailurePolicy задает, какие коды ответа считаются неуспешными
+// вызовами. По умолчанию DefaultGRPCFailurePolicy.
+func WithGRPCBalancerFailurePolicy(isFailure func(code codes.Code) bool) GRPCBalancerOption {
+	return func(b *grpcPickerBuilder) {
+		b.isFailure = isFailure
+	}
+}
+
+// WithGRPCBalancerHealthCheck включает проверку состояния серверов клиентом gRPC,
+// см. grpc.health.v1, чтобы балансировщик не выбирал сервер, не готовый к вызовам.
+func WithGRPCBalancerHealthCheck() GRPCBalancerOption {
+	return func(b *grpcPickerBuilder) {
+		b.healthCheck = true
+	}
+}
+
+// GRPCAddressKey возвращает адрес сервера, например "10.0.0.1:443".
+func GRPCAddressKey(addr resolver.Address) string {
+	return addr.Addr
+}
+
+// GRPCBalancerBuilder возвращает балансировщик round robin с именем name, который
+// выполняет вызовы через Circuit Breaker группы по адресу сервера и выбирает
+// серверы как Selector: серверы в состоянии Open пропускаются, в состоянии
+// Half-Open выбираются для пробных вызовов, пока Circuit Breaker их допускает.
+// Если все серверы отклоняют вызов, он завершается ошибкой codes.Unavailable с
+// errdetails.ErrorInfo, как у UnaryClientInterceptor. Балансировщик регистрируется
+// при инициализации программы и выбирается конфигурацией сервиса:
+//
+//	func init() {
+//		balancer.Register(GRPCBalancerBuilder("orders_round_robin", group))
+//	}
+//
+//	conn, err := grpc.NewClient(target,
+//		grpc.WithDefaultServiceConfig(`{"loadBalancingConfig": [{"orders_round_robin": {}}]}`))
+func GRPCBalancerBuilder(name string, group *Group, options ...GRPCBalancerOption) balancer.Builder {
+	b := &grpcPickerBuilder{group: group, key: GRPCAddressKey, isFailure: DefaultGRPCFailurePolicy}
+	for _, opt := range options {
+		opt(b)
+	}
+	return base.NewBalancerBuilder(name, b, base.Config{HealthCheck: b.healthCheck})
+}
+
+type grpcPickerBuilder struct {
+	group       *Group
+	key         func(addr resolver.Address) string
+	isFailure   func(code codes.Code) bool
+	healthCheck bool
+}
+
+func (b *grpcPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+	targets := make([]Target, 0, len(info.ReadySCs))
+	for subConn, subConnInfo := range info.ReadySCs {
+		key := b.key(subConnInfo.Address)
+		targets = append(targets, Target{Addr: key, Breaker: b.group.Get(key), Value: subConn})
+	}
+	return &grpcPicker{selector: NewSelector(targets), isFailure: b.isFailure}
+}
+
+type grpcPicker struct {
+	selector  *Selector
+	isFailure func(code codes.Code) bool
+}
+
+func (p *grpcPicker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	target, done, rejected, err := p.selector.selectTarget()
+	if err != nil {
+		return balancer.PickResult{}, rejectionStatus(rejected, err, codes.Unavailable).Err()
+	}
+	return balancer.PickResult{
+		SubConn: target.Value.(balancer.SubConn),
+		Done: func(info balancer.DoneInfo) {
+			done(grpcOutcome(p.isFailure, info.Err))
+		},
+	}, nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/status"
+)
+
+type testSubConn struct {
+	balancer.SubConn
+	addr string
+}
+
+// buildGRPCPicker возвращает picker для серверов addrs и их SubConn по адресу.
+func buildGRPCPicker(group *Group, addrs ...string) (balancer.Picker, map[string]balancer.SubConn) {
+	info := base.PickerBuildInfo{ReadySCs: make(map[balancer.SubConn]base.SubConnInfo)}
+	subConns := make(map[string]balancer.SubConn)
+	for _, addr := range addrs {
+		subConn := &testSubConn{addr: addr}
+		info.ReadySCs[subConn] = base.SubConnInfo{Address: resolver.Address{Addr: addr}}
+		subConns[addr] = subConn
+	}
+	return (&grpcPickerBuilder{group: group, key: GRPCAddressKey, isFailure: DefaultGRPCFailurePolicy}).Build(info), subConns
+}
+
+func TestGRPCPicker(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	group := NewGroup(func(key string) *CircuitBreaker {
+		return NewCircuitBreaker(
+			WithName(key),
+			WithClock(clock),
+			WithTimeout(10*time.Second),
+			WithMaxRequests(1),
+			WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures > 0 }),
+		)
+	})
+	picker, _ := buildGRPCPicker(group, "10.0.0.1:443", "10.0.0.2:443")
+
+	picked := make(map[string]int)
+	for i := 0; i < 4; i++ {
+		result, err := picker.Pick(balancer.PickInfo{})
+		require.NoError(t, err)
+		addr := result.SubConn.(*testSubConn).addr
+		picked[addr]++
+		if addr == "10.0.0.1:443" {
+			result.Done(balancer.DoneInfo{Err: status.Error(codes.Unavailable, "connection reset")})
+		} else {
+			result.Done(balancer.DoneInfo{Err: status.Error(codes.NotFound, "no such order")})
+		}
+	}
+	first := group.Get("10.0.0.1:443")
+	assert.Equal(t, StateOpen, first.State())
+	assert.Equal(t, 1, picked["10.0.0.1:443"])
+	assert.Equal(t, 3, picked["10.0.0.2:443"])
+	// открытый сервер пропускается без учета отказа
+	assert.Equal(t, uint64(0), first.Stats().Rejections)
+
+	// по истечении Open сервер получает один пробный вызов
+	clock.Advance(11 * time.Second)
+	var probes int
+	var results []balancer.PickResult
+	for i := 0; i < 4; i++ {
+		result, err := picker.Pick(balancer.PickInfo{})
+		require.NoError(t, err)
+		if result.SubConn.(*testSubConn).addr == "10.0.0.1:443" {
+			probes++
+		}
+		results = append(results, result)
+	}
+	assert.Equal(t, 1, probes)
+	for _, result := range results {
+		result.Done(balancer.DoneInfo{})
+	}
+	assert.Equal(t, StateClosed, first.State())
+}
+
+func TestGRPCPicker_AllOpen(t *testing.T) {
+	group := tripOnFirstFailure()
+	picker, _ := buildGRPCPicker(group, "10.0.0.1:443", "10.0.0.2:443")
+	group.Get("10.0.0.1:443").trip()
+	group.Get("10.0.0.2:443").trip()
+
+	_, err := picker.Pick(balancer.PickInfo{Ctx: context.Background()})
+	st := status.Convert(err)
+	assert.Equal(t, codes.Unavailable, st.Code())
+	assert.Contains(t, st.Message(), ErrOpenState.Error())
+}
+
+func TestGRPCBalancerBuilder(t *testing.T) {
+	builder := GRPCBalancerBuilder("orders_round_robin", tripOnFirstFailure())
+	assert.Equal(t, "orders_round_robin", builder.Name())
+
+	picker := (&grpcPickerBuilder{}).Build(base.PickerBuildInfo{})
+	_, err := picker.Pick(balancer.PickInfo{})
+	assert.ErrorIs(t, err, balancer.ErrNoSubConnAvailable)
+}
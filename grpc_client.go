@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// GRPCErrorDomain - домен errdetails.ErrorInfo в ошибках отклоненных вызовов.
+const GRPCErrorDomain = "circuitbreaker.raymanovg.github.com"
+
+type GRPCClientOption func(*grpcClient)
+
+// WithGRPCKey задает ключ Circuit Breaker для вызова метода method сервера target,
+// например GRPCMethodKey, чтобы медленный метод не отклонял вызовы остальных методов
+// сервиса. ctx - контекст вызова с исходящими метаданными. По умолчанию GRPCTargetKey.
+func WithGRPCKey(key func(ctx context.Context, target, method string) string) GRPCClientOption {
+	return func(c *grpcClient) {
+		c.key = key
+	}
+}
+
+// GRPCTargetKey возвращает адрес сервера, один Circuit Breaker на соединение.
+func GRPCTargetKey(_ context.Context, target, _ string) string {
+	return target
+}
+
+// GRPCFullMethodKey возвращает полное имя метода, например "/shop.Orders/Get",
+// один Circuit Breaker на метод для всех серверов.
+func GRPCFullMethodKey(_ context.Context, _, method string) string {
+	return method
+}
+
+// GRPCMethodKey возвращает адрес сервера и полное имя метода,
+// например "dns:///orders:443 /shop.Orders/Get".
+func GRPCMethodKey(_ context.Context, target, method string) string {
+	return target + " " + method
+}
+
+type grpcClient struct {
+	group        *Group
+	key          func(ctx context.Context, target, method string) string
+	streamPolicy GRPCStreamPolicy
+	isFailure    func(code codes.Code) bool
+}
+
+func newGRPCClient(group *Group, options []GRPCClientOption) *grpcClient {
+	c := &grpcClient{group: group, key: GRPCTargetKey, streamPolicy: GRPCStreamTerminal, isFailure: DefaultGRPCFailurePolicy}
+	for _, opt := range options {
+		opt(c)
+	}
+	return c
+}
+
+// UnaryClientInterceptor возвращает interceptor, выполняющий исходящие вызовы
+// через Circuit Breaker группы по ключу вызова. Результат вызова учитывается
+// по коду ответа, см. WithGRPCFailurePolicy. Отклоненный вызов завершается
+// ошибкой codes.Unavailable с errdetails.ErrorInfo и, для состояния Open,
+// errdetails.RetryInfo с оставшимся временем состояния.
+func UnaryClientInterceptor(group *Group, options ...GRPCClientOption) grpc.UnaryClientInterceptor {
+	c := newGRPCClient(group, options)
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		cb := c.group.Get(c.key(ctx, cc.Target(), method))
+		var called bool
+		var callErr error
+		_, err := cb.ExecuteContext(ctx, func(ctx context.Context) (interface{}, error) {
+			called = true
+			callErr = invoker(ctx, method, req, reply, cc, opts...)
+			return nil, grpcOutcome(c.isFailure, callErr)
+		})
+		if !called {
+			return notCalledStatus(cb, err, codes.Unavailable)
+		}
+		return callErr
+	}
+}
+
+// notCalledStatus возвращает ошибку вызова, который не был выполнен из-за ошибки err:
+// отказа Circuit Breaker cb с кодом code или отмены ctx.
+func notCalledStatus(cb *CircuitBreaker, err error, code codes.Code) error {
+	if isRejection(err) {
+		return rejectionStatus(cb, err, code).Err()
+	}
+	return status.FromContextError(err).Err()
+}
+
+// rejectionStatus описывает отказ Circuit Breaker cb с ошибкой err в виде статуса gRPC с кодом code.
+func rejectionStatus(cb *CircuitBreaker, err error, code codes.Code) *status.Status {
+	current := cb.current.Load()
+	st := status.Newf(code, "circuit breaker %s: %v", cb.Path(), err)
+
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason:   strings.ToUpper(rejectionReason(err)),
+		Domain:   GRPCErrorDomain,
+		Metadata: map[string]string{"breaker": cb.Path(), "state": current.state.String()},
+	}}
+	if current.state == StateOpen {
+		if remaining := current.expiry.Sub(cb.config().timeProvider.Now()); remaining > 0 {
+			details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(remaining)})
+		}
+	}
+
+	withDetails, detailsErr := st.WithDetails(details...)
+	if detailsErr != nil {
+		return st
+	}
+	return withDetails
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// testService - тестовый сервис. Код ответа выбирается по сервису в запросе:
+// "unavailable" и "missing" - codes.Unavailable и codes.NotFound до отправки сообщений,
+// "fail" - codes.Internal (Watch - после двух сообщений), иначе успешный ответ.
+var testService = grpc.ServiceDesc{
+	ServiceName: "test.Stream",
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Check",
+		Handler: func(_ any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+			var req healthpb.HealthCheckRequest
+			if err := dec(&req); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req any) (any, error) {
+				if err := testServiceError(req.(*healthpb.HealthCheckRequest).GetService()); err != nil {
+					return nil, err
+				}
+				return &healthpb.HealthCheckResponse{}, nil
+			}
+			if interceptor == nil {
+				return handler(ctx, &req)
+			}
+			return interceptor(ctx, &req, &grpc.UnaryServerInfo{FullMethod: "/test.Stream/Check"}, handler)
+		},
+	}},
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Watch",
+		ServerStreams: true,
+		Handler: func(_ any, stream grpc.ServerStream) error {
+			var req healthpb.HealthCheckRequest
+			if err := stream.RecvMsg(&req); err != nil {
+				return err
+			}
+			if req.GetService() != "fail" {
+				if err := testServiceError(req.GetService()); err != nil {
+					return err
+				}
+			}
+			for i := 0; i < 2; i++ {
+				if err := stream.SendMsg(&healthpb.HealthCheckResponse{}); err != nil {
+					return err
+				}
+			}
+			return testServiceError(req.GetService())
+		},
+	}},
+}
+
+func testServiceError(service string) error {
+	switch service {
+	case "unavailable":
+		return status.Error(codes.Unavailable, "unavailable")
+	case "missing":
+		return status.Error(codes.NotFound, "not found")
+	case "fail":
+		return status.Error(codes.Internal, "stream broken")
+	default:
+		return nil
+	}
+}
+
+func testServiceConn(t *testing.T, options ...grpc.DialOption) *grpc.ClientConn {
+	return bufconnClient(t, func(server *grpc.Server) {
+		server.RegisterService(&testService, nil)
+	}, options...)
+}
+
+func checkService(ctx context.Context, conn *grpc.ClientConn, service string) error {
+	return conn.Invoke(ctx, "/test.Stream/Check", &healthpb.HealthCheckRequest{Service: service}, &healthpb.HealthCheckResponse{})
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	group := tripOnFirstFailure()
+	conn := testServiceConn(t, grpc.WithUnaryInterceptor(UnaryClientInterceptor(group)))
+	ctx := context.Background()
+
+	require.NoError(t, checkService(ctx, conn, "ok"))
+
+	// NotFound означает, что сервер доступен
+	assert.Equal(t, codes.NotFound, status.Code(checkService(ctx, conn, "missing")))
+	assert.Equal(t, StateClosed, group.Get("passthrough:///bufconn").State())
+
+	assert.Equal(t, codes.Unavailable, status.Code(checkService(ctx, conn, "unavailable")))
+
+	st := status.Convert(checkService(ctx, conn, "ok"))
+	assert.Equal(t, codes.Unavailable, st.Code())
+	assert.Equal(t, "circuit breaker passthrough:///bufconn: state is open", st.Message())
+	require.Len(t, st.Details(), 2)
+	info := st.Details()[0].(*errdetails.ErrorInfo)
+	assert.Equal(t, "OPEN", info.GetReason())
+	assert.Equal(t, GRPCErrorDomain, info.GetDomain())
+	assert.Equal(t, map[string]string{"breaker": "passthrough:///bufconn", "state": "open"}, info.GetMetadata())
+	retry := st.Details()[1].(*errdetails.RetryInfo)
+	assert.InDelta(t, 10*time.Second, retry.GetRetryDelay().AsDuration(), float64(time.Second))
+
+	assert.Equal(t, 1, group.Len())
+	assert.Equal(t, StateOpen, group.Get("passthrough:///bufconn").State())
+}
+
+func TestUnaryClientInterceptor_FailurePolicy(t *testing.T) {
+	group := tripOnFirstFailure()
+	conn := testServiceConn(t, grpc.WithUnaryInterceptor(UnaryClientInterceptor(group,
+		WithGRPCFailurePolicy(FailOnCodes(codes.NotFound)),
+	)))
+	ctx := context.Background()
+
+	assert.Equal(t, codes.Unavailable, status.Code(checkService(ctx, conn, "unavailable")))
+	assert.Equal(t, StateClosed, group.Get("passthrough:///bufconn").State())
+
+	assert.Equal(t, codes.NotFound, status.Code(checkService(ctx, conn, "missing")))
+	assert.Equal(t, StateOpen, group.Get("passthrough:///bufconn").State())
+}
+
+func TestUnaryClientInterceptor_MethodKey(t *testing.T) {
+	group := tripOnFirstFailure()
+	conn := testServiceConn(t, grpc.WithUnaryInterceptor(UnaryClientInterceptor(group, WithGRPCKey(GRPCMethodKey))))
+
+	assert.Equal(t, codes.Internal, status.Code(checkService(context.Background(), conn, "fail")))
+	assert.Equal(t, StateOpen, group.Get("passthrough:///bufconn /test.Stream/Check").State())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, codes.Canceled, status.Code(checkService(ctx, conn, "ok")))
+}
+
+func TestUnaryClientInterceptor_ContextKey(t *testing.T) {
+	group := tripOnFirstFailure()
+	tenantKey := func(ctx context.Context, _, method string) string {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		return strings.Join(md.Get("tenant"), ",") + " " + method
+	}
+	conn := testServiceConn(t, grpc.WithUnaryInterceptor(UnaryClientInterceptor(group, WithGRPCKey(tenantKey))))
+	acme := metadata.AppendToOutgoingContext(context.Background(), "tenant", "acme")
+	globex := metadata.AppendToOutgoingContext(context.Background(), "tenant", "globex")
+
+	assert.Equal(t, codes.Unavailable, status.Code(checkService(acme, conn, "unavailable")))
+	assert.Equal(t, codes.Unavailable, status.Code(checkService(acme, conn, "ok")))
+	require.NoError(t, checkService(globex, conn, "ok"))
+
+	assert.Equal(t, StateOpen, group.Get("acme /test.Stream/Check").State())
+	assert.Equal(t, StateClosed, group.Get("globex /test.Stream/Check").State())
+}
+
+func TestGRPCKeys(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "dns:///orders:443", GRPCTargetKey(ctx, "dns:///orders:443", "/shop.Orders/Get"))
+	assert.Equal(t, "/shop.Orders/Get", GRPCFullMethodKey(ctx, "dns:///orders:443", "/shop.Orders/Get"))
+	assert.Equal(t, "dns:///orders:443 /shop.Orders/Get", GRPCMethodKey(ctx, "dns:///orders:443", "/shop.Orders/Get"))
+}
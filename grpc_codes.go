@@ -0,0 +1,55 @@
+package main
+
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultGRPCFailurePolicy считает неуспешными вызовы с кодами, означающими
+// недоступность или сбой сервера: Unavailable, DeadlineExceeded, Internal, Unknown
+// и DataLoss. Остальные коды, например NotFound или InvalidArgument, означают,
+// что сервер доступен и обработал вызов, и считаются успешными.
+func DefaultGRPCFailurePolicy(code codes.Code) bool {
+	switch code {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown, codes.DataLoss:
+		return true
+	default:
+		return false
+	}
+}
+
+// FailOnCodes возвращает политику, считающую неуспешными только вызовы с кодами failures.
+func FailOnCodes(failures ...codes.Code) func(code codes.Code) bool {
+	return func(code codes.Code) bool {
+		for _, c := range failures {
+			if code == c {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// WithGRPCFailurePolicy задает, какие коды ответа считаются неуспешными вызовами.
+// По умолчанию DefaultGRPCFailurePolicy.
+func WithGRPCFailurePolicy(isFailure func(code codes.Code) bool) GRPCClientOption {
+	return func(c *grpcClient) {
+		c.isFailure = isFailure
+	}
+}
+
+// WithGRPCServerFailurePolicy задает, какие коды ответа обработчика считаются
+// неуспешными вызовами. По умолчанию DefaultGRPCFailurePolicy.
+func WithGRPCServerFailurePolicy(isFailure func(code codes.Code) bool) GRPCServerOption {
+	return func(s *grpcServer) {
+		s.isFailure = isFailure
+	}
+}
+
+// grpcOutcome возвращает err, если его код считается неуспешным, иначе nil.
+func grpcOutcome(isFailure func(code codes.Code) bool, err error) error {
+	if err == nil || !isFailure(status.Code(err)) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestDefaultGRPCFailurePolicy(t *testing.T) {
+	for _, code := range []codes.Code{codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown, codes.DataLoss} {
+		assert.True(t, DefaultGRPCFailurePolicy(code), code.String())
+	}
+	for _, code := range []codes.Code{codes.OK, codes.NotFound, codes.InvalidArgument, codes.Canceled, codes.PermissionDenied} {
+		assert.False(t, DefaultGRPCFailurePolicy(code), code.String())
+	}
+}
+
+func TestFailOnCodes(t *testing.T) {
+	isFailure := FailOnCodes(codes.NotFound, codes.Unavailable)
+	assert.True(t, isFailure(codes.NotFound))
+	assert.True(t, isFailure(codes.Unavailable))
+	assert.False(t, isFailure(codes.Internal))
+	assert.False(t, isFailure(codes.OK))
+}
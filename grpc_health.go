@@ -0,0 +1,93 @@
+package main
+
+import (
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// GRPCHealth - сервис grpc.health.v1, статусы которого определяются
+// состояниями Circuit Breaker. Подключается к Circuit Breaker через WithGRPCHealth.
+type GRPCHealth struct {
+	server *health.Server
+
+	mu       sync.Mutex
+	services map[string][]string
+	open     map[string]bool
+}
+
+// NewGRPCHealth создает GRPCHealth. services сопоставляет имени gRPC-сервиса
+// имена Circuit Breaker его зависимостей: сервис получает статус NOT_SERVING,
+// пока хотя бы один из них в состоянии Open. Общий статус сервера ("")
+// учитывает все перечисленные Circuit Breaker.
+func NewGRPCHealth(services map[string][]string) *GRPCHealth {
+	h := &GRPCHealth{
+		server:   health.NewServer(),
+		services: make(map[string][]string, len(services)+1),
+		open:     make(map[string]bool),
+	}
+	var all []string
+	for service, names := range services {
+		h.services[service] = names
+		all = append(all, names...)
+	}
+	if _, ok := h.services[""]; !ok {
+		h.services[""] = all
+	}
+	for service := range h.services {
+		h.server.SetServingStatus(service, healthpb.HealthCheckResponse_SERVING)
+	}
+	return h
+}
+
+// WithGRPCHealth включает обновление статусов h при переходах Circuit Breaker.
+// Один GRPCHealth может использоваться несколькими Circuit Breaker.
+func WithGRPCHealth(h *GRPCHealth) Option {
+	return withObserver(h)
+}
+
+// Register регистрирует сервис grpc.health.v1 в server.
+func (h *GRPCHealth) Register(server grpc.ServiceRegistrar) {
+	healthpb.RegisterHealthServer(server, h.server)
+}
+
+// Shutdown переводит все сервисы в статус NOT_SERVING и прекращает их обновление,
+// например перед остановкой сервера.
+func (h *GRPCHealth) Shutdown() {
+	h.server.Shutdown()
+}
+
+func (h *GRPCHealth) observeTransition(_ *CircuitBreaker, change StateChange) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	open := change.To == StateOpen
+	if h.open[change.Name] == open {
+		return
+	}
+	h.open[change.Name] = open
+
+	for service, names := range h.services {
+		for _, name := range names {
+			if name == change.Name {
+				h.server.SetServingStatus(service, h.status(names))
+				break
+			}
+		}
+	}
+}
+
+func (h *GRPCHealth) status(names []string) healthpb.HealthCheckResponse_ServingStatus {
+	for _, name := range names {
+		if h.open[name] {
+			return healthpb.HealthCheckResponse_NOT_SERVING
+		}
+	}
+	return healthpb.HealthCheckResponse_SERVING
+}
+
+func (*GRPCHealth) observeCall(*CircuitBreaker, OutcomeRecord) {}
+
+func (*GRPCHealth) observeRejection(*CircuitBreaker, error) {}
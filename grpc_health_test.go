@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestGRPCHealth(t *testing.T) {
+	h := NewGRPCHealth(map[string][]string{
+		"shop.Orders": {"payments", "stock"},
+		"shop.Search": {"search"},
+	})
+	r := NewRegistry(WithDefaults(WithGRPCHealth(h)))
+	client := healthpb.NewHealthClient(bufconnClient(t, func(server *grpc.Server) {
+		h.Register(server)
+	}))
+	ctx := context.Background()
+
+	check := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		require.NoError(t, err)
+		return resp.GetStatus()
+	}
+
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(""))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check("shop.Orders"))
+
+	r.Get("stock").Trip()
+	r.Get("payments").Trip()
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check(""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check("shop.Orders"))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check("shop.Search"))
+
+	r.Get("stock").Reset()
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check("shop.Orders"))
+	r.Get("payments").Reset()
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check("shop.Orders"))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, check(""))
+
+	h.Shutdown()
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, check("shop.Search"))
+}
+
+func TestGRPCHealth_Watch(t *testing.T) {
+	h := NewGRPCHealth(map[string][]string{"shop.Search": {"search"}})
+	cb := NewCircuitBreaker(WithName("search"), WithGRPCHealth(h))
+	client := healthpb.NewHealthClient(bufconnClient(t, func(server *grpc.Server) {
+		h.Register(server)
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "shop.Search"})
+	require.NoError(t, err)
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.GetStatus())
+
+	cb.Trip()
+	resp, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.GetStatus())
+}
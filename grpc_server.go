@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+type GRPCServerOption func(*grpcServer)
+
+// WithGRPCServerDependencies задает Circuit Breaker нижестоящих сервисов: пока хотя бы
+// один из них в состоянии Open, входящие вызовы отклоняются с codes.Unavailable.
+func WithGRPCServerDependencies(dependencies ...*CircuitBreaker) GRPCServerOption {
+	return func(s *grpcServer) {
+		s.dependencies = append(s.dependencies, dependencies...)
+	}
+}
+
+type grpcServer struct {
+	cb           *CircuitBreaker
+	dependencies []*CircuitBreaker
+	isFailure    func(code codes.Code) bool
+}
+
+func newGRPCServer(cb *CircuitBreaker, options []GRPCServerOption) *grpcServer {
+	s := &grpcServer{cb: cb, isFailure: DefaultGRPCFailurePolicy}
+	for _, opt := range options {
+		opt(s)
+	}
+	return s
+}
+
+// UnaryServerInterceptor возвращает interceptor, выполняющий входящие вызовы через cb,
+// например с WithResourceProbe или WithShedOnQueueDepth, чтобы защитить сервер
+// от накопления очереди. Результат учитывается по коду ответа обработчика,
+// см. WithGRPCServerFailurePolicy. Вызов, отклоненный cb, завершается codes.ResourceExhausted,
+// а при открытой зависимости из WithGRPCServerDependencies - codes.Unavailable.
+func UnaryServerInterceptor(cb *CircuitBreaker, options ...GRPCServerOption) grpc.UnaryServerInterceptor {
+	s := newGRPCServer(cb, options)
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var resp any
+		err := s.admit(ctx, func(ctx context.Context) error {
+			var err error
+			resp, err = handler(ctx, req)
+			return err
+		})
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor возвращает interceptor потоков, аналогичный UnaryServerInterceptor.
+func StreamServerInterceptor(cb *CircuitBreaker, options ...GRPCServerOption) grpc.StreamServerInterceptor {
+	s := newGRPCServer(cb, options)
+	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return s.admit(stream.Context(), func(context.Context) error {
+			return handler(srv, stream)
+		})
+	}
+}
+
+func (s *grpcServer) admit(ctx context.Context, handle func(ctx context.Context) error) error {
+	for _, dependency := range s.dependencies {
+		if dependency.State() == StateOpen {
+			return rejectionStatus(dependency, ErrOpenState, codes.Unavailable).Err()
+		}
+	}
+
+	var called bool
+	var handleErr error
+	_, err := s.cb.ExecuteContext(ctx, func(ctx context.Context) (interface{}, error) {
+		called = true
+		handleErr = handle(ctx)
+		return nil, grpcOutcome(s.isFailure, handleErr)
+	})
+	if !called {
+		return notCalledStatus(s.cb, err, codes.ResourceExhausted)
+	}
+	return handleErr
+}
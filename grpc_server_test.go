@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func admissionClient(t *testing.T, cb *CircuitBreaker, options ...GRPCServerOption) *grpc.ClientConn {
+	serverOptions := []grpc.ServerOption{
+		grpc.UnaryInterceptor(UnaryServerInterceptor(cb, options...)),
+		grpc.StreamInterceptor(StreamServerInterceptor(cb, options...)),
+	}
+	return bufconnServerClient(t, serverOptions, func(s *grpc.Server) {
+		s.RegisterService(&testService, nil)
+	})
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	var pressure atomic.Bool
+	cb := NewCircuitBreaker(WithName("server"), WithResourceProbe(RuntimeResourceProbe{}, func(ResourceUsage) bool { return pressure.Load() }))
+	payments := NewCircuitBreaker(WithName("payments"))
+	conn := admissionClient(t, cb, WithGRPCServerDependencies(payments))
+	ctx := context.Background()
+
+	require.NoError(t, checkService(ctx, conn, "ok"))
+
+	pressure.Store(true)
+	err := checkService(ctx, conn, "ok")
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, "circuit breaker server: process is under resource pressure", status.Convert(err).Message())
+
+	pressure.Store(false)
+	payments.Trip()
+	err = checkService(ctx, conn, "ok")
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, "circuit breaker payments: state is open", status.Convert(err).Message())
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	cb := NewCircuitBreaker(
+		WithTimeout(time.Minute),
+		WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures >= 1 }),
+	)
+	conn := admissionClient(t, cb)
+
+	received, err := watchStream(t, conn, "ok")
+	require.NoError(t, err)
+	assert.Equal(t, 2, received)
+
+	_, err = watchStream(t, conn, "fail")
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, StateOpen, cb.State())
+
+	_, err = watchStream(t, conn, "ok")
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestUnaryServerInterceptor_FailurePolicy(t *testing.T) {
+	cb := NewCircuitBreaker(WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures >= 1 }))
+	conn := admissionClient(t, cb, WithGRPCServerFailurePolicy(FailOnCodes(codes.NotFound)))
+	ctx := context.Background()
+
+	assert.Equal(t, codes.Internal, status.Code(checkService(ctx, conn, "fail")))
+	assert.Equal(t, StateClosed, cb.State())
+
+	assert.Equal(t, codes.NotFound, status.Code(checkService(ctx, conn, "missing")))
+	assert.Equal(t, StateOpen, cb.State())
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCStreamPolicy определяет, какой результат потока учитывается Circuit Breaker.
+type GRPCStreamPolicy int
+
+const (
+	// GRPCStreamTerminal - учитывается итоговый статус потока: ошибка RecvMsg,
+	// в том числе посреди потока, или io.EOF для успешного завершения.
+	GRPCStreamTerminal GRPCStreamPolicy = iota
+	// GRPCStreamEstablishment - учитывается только установка потока: ошибка создания
+	// или первого RecvMsg. Ошибки после первого сообщения не учитываются.
+	GRPCStreamEstablishment
+)
+
+// WithGRPCStreamPolicy задает учет результата потоков. По умолчанию GRPCStreamTerminal.
+func WithGRPCStreamPolicy(policy GRPCStreamPolicy) GRPCClientOption {
+	return func(c *grpcClient) {
+		c.streamPolicy = policy
+	}
+}
+
+// StreamClientInterceptor возвращает interceptor, выполняющий исходящие потоки
+// через Circuit Breaker группы по ключу вызова, аналогично UnaryClientInterceptor.
+// Учитываемый результат потока выбирается WithGRPCStreamPolicy и классифицируется
+// по коду, см. WithGRPCFailurePolicy. Если ctx отменен до завершения потока,
+// учитывается codes.Canceled или codes.DeadlineExceeded.
+func StreamClientInterceptor(group *Group, options ...GRPCClientOption) grpc.StreamClientInterceptor {
+	c := newGRPCClient(group, options)
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, status.FromContextError(err).Err()
+		}
+
+		cb := c.group.Get(c.key(ctx, cc.Target(), method))
+		done, err := cb.allow()
+		if err != nil {
+			return nil, rejectionStatus(cb, err, codes.Unavailable).Err()
+		}
+
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			done(grpcOutcome(c.isFailure, err))
+			return nil, err
+		}
+
+		stop := context.AfterFunc(ctx, func() {
+			done(grpcOutcome(c.isFailure, status.FromContextError(ctx.Err()).Err()))
+		})
+		return &breakerClientStream{
+			ClientStream: stream,
+			policy:       c.streamPolicy,
+			done: func(err error) {
+				stop()
+				done(grpcOutcome(c.isFailure, err))
+			},
+		}, nil
+	}
+}
+
+type breakerClientStream struct {
+	grpc.ClientStream
+	policy   GRPCStreamPolicy
+	received bool
+	done     func(err error)
+}
+
+func (s *breakerClientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch {
+	case errors.Is(err, io.EOF):
+		s.done(nil)
+	case err != nil:
+		if s.policy == GRPCStreamEstablishment && s.received {
+			s.done(nil)
+		} else {
+			s.done(err)
+		}
+	default:
+		s.received = true
+		if s.policy == GRPCStreamEstablishment {
+			s.done(nil)
+		}
+	}
+	return err
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func watchStream(t *testing.T, conn *grpc.ClientConn, service string) (int, error) {
+	stream, err := conn.NewStream(context.Background(), &testService.Streams[0], "/test.Stream/Watch")
+	if err != nil {
+		return 0, err
+	}
+	require.NoError(t, stream.SendMsg(&healthpb.HealthCheckRequest{Service: service}))
+	require.NoError(t, stream.CloseSend())
+
+	var received int
+	for {
+		err := stream.RecvMsg(&healthpb.HealthCheckResponse{})
+		if err == io.EOF {
+			return received, nil
+		}
+		if err != nil {
+			return received, err
+		}
+		received++
+	}
+}
+
+func streamConn(t *testing.T, group *Group, options ...GRPCClientOption) *grpc.ClientConn {
+	return bufconnClient(t, func(server *grpc.Server) {
+		server.RegisterService(&testService, nil)
+	}, grpc.WithStreamInterceptor(StreamClientInterceptor(group, options...)))
+}
+
+func TestStreamClientInterceptor(t *testing.T) {
+	group := tripOnFirstFailure()
+	conn := streamConn(t, group)
+	cb := group.Get("passthrough:///bufconn")
+
+	received, err := watchStream(t, conn, "ok")
+	require.NoError(t, err)
+	assert.Equal(t, 2, received)
+	assert.Equal(t, StateClosed, cb.State())
+
+	// ошибка посреди потока учитывается
+	received, err = watchStream(t, conn, "fail")
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, 2, received)
+	assert.Equal(t, StateOpen, cb.State())
+
+	_, err = watchStream(t, conn, "ok")
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestStreamClientInterceptor_Establishment(t *testing.T) {
+	group := tripOnFirstFailure()
+	conn := streamConn(t, group, WithGRPCStreamPolicy(GRPCStreamEstablishment))
+	cb := group.Get("passthrough:///bufconn")
+
+	// ошибка после первого сообщения не учитывается
+	_, err := watchStream(t, conn, "fail")
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, uint32(1), cb.Counts().TotalSuccess)
+
+	// ошибка установки потока учитывается
+	_, err = watchStream(t, conn, "unavailable")
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, StateOpen, cb.State())
+}
+
+func TestStreamClientInterceptor_Canceled(t *testing.T) {
+	group := tripOnFirstFailure()
+	conn := streamConn(t, group)
+	cb := group.Get("passthrough:///bufconn")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := conn.NewStream(ctx, &testService.Streams[0], "/test.Stream/Watch")
+	require.NoError(t, err)
+	require.NoError(t, stream.SendMsg(&healthpb.HealthCheckRequest{Service: "ok"}))
+	require.NoError(t, stream.RecvMsg(&healthpb.HealthCheckResponse{}))
+
+	// поток брошен без чтения до конца: результат учитывается при отмене ctx,
+	// codes.Canceled по умолчанию не считается ошибкой
+	cancel()
+	assert.Eventually(t, func() bool { return cb.Counts().TotalSuccess == 1 }, time.Second, time.Millisecond)
+
+	group = tripOnFirstFailure()
+	conn = streamConn(t, group, WithGRPCFailurePolicy(FailOnCodes(codes.Canceled)))
+	cb = group.Get("passthrough:///bufconn")
+
+	ctx, cancel = context.WithCancel(context.Background())
+	stream, err = conn.NewStream(ctx, &testService.Streams[0], "/test.Stream/Watch")
+	require.NoError(t, err)
+	require.NoError(t, stream.SendMsg(&healthpb.HealthCheckRequest{Service: "ok"}))
+	cancel()
+	assert.Eventually(t, func() bool { return cb.State() == StateOpen }, time.Second, time.Millisecond)
+}
+
+func TestStreamClientInterceptor_FullMethodKey(t *testing.T) {
+	group := tripOnFirstFailure()
+	conn := bufconnClient(t, func(server *grpc.Server) {
+		server.RegisterService(&testService, nil)
+	},
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor(group, WithGRPCKey(GRPCFullMethodKey))),
+		grpc.WithStreamInterceptor(StreamClientInterceptor(group, WithGRPCKey(GRPCFullMethodKey))),
+	)
+
+	// сбой потокового метода не отклоняет вызовы других методов
+	_, err := watchStream(t, conn, "unavailable")
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	require.NoError(t, checkService(context.Background(), conn, "ok"))
+
+	assert.Equal(t, StateOpen, group.Get("/test.Stream/Watch").State())
+	assert.Equal(t, StateClosed, group.Get("/test.Stream/Check").State())
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clock"
+)
+
+// HealthCheck проверяет доступность нижестоящего сервиса.
+type HealthCheck func(ctx context.Context) error
+
+// WithHealthCheck включает фоновую проверку доступности в состоянии Open.
+// Каждые interval вызывается check, и при первом успешном вызове
+// Circuit Breaker переходит в Half-Open, не дожидаясь окончания timeout
+// и не используя для проверки реальные запросы.
+func WithHealthCheck(check HealthCheck, interval time.Duration) Option {
+	return func(s *settings) {
+		s.healthCheck = check
+		s.healthCheckInterval = interval
+	}
+}
+
+// WithHalfOpenProbe задает синтетический запрос, которым оценивается восстановление
+// в состоянии Half-Open. Probe вызывается каждые interval, и его результаты
+// учитываются как результаты запросов в Half-Open, а реальные запросы
+// в этом состоянии отклоняются с ErrTooManyRequests.
+func WithHalfOpenProbe(probe HealthCheck, interval time.Duration) Option {
+	return func(s *settings) {
+		s.halfOpenProbe = probe
+		s.halfOpenProbeInterval = interval
+	}
+}
+
+// startHealthCheck запускает проверку доступности для состояния Open с номером generation.
+// Горутина завершается после успешной проверки, смены состояния или вызова Close.
+func (cb *CircuitBreaker) startHealthCheck(generation uint64) {
+	s := cb.config()
+
+	cb.background.Add(1)
+	go func() {
+		defer cb.background.Done()
+
+		timer := cb.clock().NewTimer(s.healthCheckInterval)
+		defer timer.Stop()
+
+		for tick(cb.lifetime, timer, s.healthCheckInterval) {
+			if !cb.inGeneration(generation) {
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(cb.lifetime, s.healthCheckInterval)
+			err := s.healthCheck(ctx)
+			cancel()
+
+			if err != nil {
+				cb.log(LogDebug, "health check failed", "error", err)
+				continue
+			}
+			cb.log(LogInfo, "health check succeeded")
+
+			cb.mu.Lock()
+			if cb.current.Load().generation == generation {
+				cb.transition(StateHalfOpen, ReasonHealthCheck, nil)
+			}
+			cb.mu.Unlock()
+
+			return
+		}
+	}()
+}
+
+// startHalfOpenProbe запускает синтетические запросы для состояния Half-Open
+// с номером generation. Горутина завершается при смене состояния или вызове Close.
+func (cb *CircuitBreaker) startHalfOpenProbe(generation uint64) {
+	s := cb.config()
+
+	cb.background.Add(1)
+	go func() {
+		defer cb.background.Done()
+
+		timer := cb.clock().NewTimer(s.halfOpenProbeInterval)
+		defer timer.Stop()
+
+		for tick(cb.lifetime, timer, s.halfOpenProbeInterval) {
+			if !cb.inGeneration(generation) {
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(cb.lifetime, s.halfOpenProbeInterval)
+			err := s.halfOpenProbe(ctx)
+			cancel()
+
+			if err != nil {
+				cb.log(LogDebug, "half-open probe failed", "error", err)
+			} else {
+				cb.log(LogDebug, "half-open probe succeeded")
+			}
+
+			cb.mu.Lock()
+			if cb.current.Load().generation != generation {
+				cb.mu.Unlock()
+				return
+			}
+			cb.counts.onRequest()
+			if err != nil {
+				cb.onFailure(err)
+			} else {
+				cb.onSuccess()
+			}
+			cb.mu.Unlock()
+		}
+	}()
+}
+
+func (cb *CircuitBreaker) probesHalfOpen() bool {
+	s := cb.config()
+	return s.halfOpenProbe != nil && s.halfOpenProbeInterval > 0
+}
+
+func (cb *CircuitBreaker) inGeneration(generation uint64) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.current.Load().generation == generation
+}
+
+// tick дожидается срабатывания timer и перезапускает его на interval.
+// Возвращает false после отмены ctx.
+func tick(ctx context.Context, timer clock.Timer, interval time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C():
+		timer.Reset(interval)
+		return true
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker_HealthCheck(t *testing.T) {
+	var calls atomic.Int32
+
+	cb := NewCircuitBreaker(
+		WithTimeout(time.Hour),
+		WithReadyToTrip(func(counts Counts) bool {
+			return counts.ConsecutiveFailures > 0
+		}),
+		WithHealthCheck(func(ctx context.Context) error {
+			// первые две проверки неуспешны
+			if calls.Add(1) <= 2 {
+				return errors.New("unhealthy")
+			}
+			return nil
+		}, 5*time.Millisecond),
+	)
+
+	assert.NotNil(t, fail(cb))
+	assert.Equal(t, StateOpen, cb.State())
+
+	// переход в Half-Open задолго до окончания timeout
+	assert.Eventually(t, func() bool {
+		return cb.State() == StateHalfOpen
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int32(3), calls.Load())
+
+	assert.Nil(t, succeed(cb))
+}
+
+func TestCircuitBreaker_HealthCheckStopsOnStateChange(t *testing.T) {
+	var calls atomic.Int32
+
+	cb := NewCircuitBreaker(
+		WithTimeout(time.Hour),
+		WithHealthCheck(func(ctx context.Context) error {
+			calls.Add(1)
+			return errors.New("unhealthy")
+		}, 5*time.Millisecond),
+	)
+
+	cb.trip()
+	assert.Eventually(t, func() bool {
+		return calls.Load() > 0
+	}, time.Second, time.Millisecond)
+
+	// состояние сменилось - проверка больше не влияет на Circuit Breaker
+	cb.mu.Lock()
+	cb.setState(StateClosed)
+	cb.mu.Unlock()
+
+	time.Sleep(20 * time.Millisecond)
+	stopped := calls.Load()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, stopped, calls.Load())
+	assert.Equal(t, StateClosed, cb.State())
+}
+
+func TestCircuitBreaker_HalfOpenProbe(t *testing.T) {
+	var healthy atomic.Bool
+
+	cb := NewCircuitBreaker(
+		WithTimeout(time.Hour),
+		WithMaxRequests(3),
+		WithHalfOpenProbe(func(ctx context.Context) error {
+			if !healthy.Load() {
+				return errors.New("unhealthy")
+			}
+			return nil
+		}, 5*time.Millisecond),
+	)
+
+	cb.mu.Lock()
+	cb.setState(StateHalfOpen)
+	cb.mu.Unlock()
+
+	// реальные запросы в Half-Open не пропускаются
+	assert.ErrorIs(t, succeed(cb), ErrTooManyRequests)
+
+	// неуспешный синтетический запрос возвращает в Open
+	assert.Eventually(t, func() bool {
+		return cb.State() == StateOpen
+	}, time.Second, time.Millisecond)
+
+	healthy.Store(true)
+	cb.mu.Lock()
+	cb.setState(StateHalfOpen)
+	cb.mu.Unlock()
+
+	// MaxRequests успешных синтетических запросов подряд закрывают Circuit Breaker
+	assert.Eventually(t, func() bool {
+		return cb.State() == StateClosed
+	}, time.Second, time.Millisecond)
+	assert.Nil(t, succeed(cb))
+}
+
+func TestCircuitBreaker_HealthCheckClock(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	var calls atomic.Int32
+
+	cb := NewCircuitBreaker(
+		WithTimeout(time.Hour),
+		WithClock(clock),
+		WithHealthCheck(func(ctx context.Context) error {
+			calls.Add(1)
+			return nil
+		}, time.Minute),
+	)
+	defer cb.Close(context.Background())
+
+	cb.trip()
+	assert.Eventually(t, func() bool {
+		return clock.Timers() == 1
+	}, time.Second, time.Millisecond)
+	assert.Zero(t, calls.Load())
+
+	// проверка выполняется только по времени clock
+	clock.Advance(time.Minute)
+	assert.Eventually(t, func() bool {
+		return cb.State() == StateHalfOpen
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int32(1), calls.Load())
+}
@@ -0,0 +1,98 @@
+package main
+
+import "strings"
+
+// WithName задает имя Circuit Breaker, которое попадает в уведомления о смене состояния.
+func WithName(name string) Option {
+	return func(s *settings) {
+		s.name = name
+	}
+}
+
+// WithParent делает Circuit Breaker дочерним для parent. Запросы дочернего
+// Circuit Breaker сначала проходят через родителя: открытый родитель отклоняет
+// запросы всех дочерних, а результаты запросов учитываются и в счетчиках родителя.
+// Учитывается только непосредственный родитель. Задается только при создании,
+// UpdateConfig родителя не меняет.
+func WithParent(parent *CircuitBreaker) Option {
+	return func(s *settings) {
+		s.parent = parent
+	}
+}
+
+// WithChildTripThreshold переводит родительский Circuit Breaker в Open,
+// когда в состоянии Open находятся не менее threshold дочерних.
+func WithChildTripThreshold(threshold int) Option {
+	return func(s *settings) {
+		s.childTripThreshold = threshold
+	}
+}
+
+func (cb *CircuitBreaker) Name() string {
+	return cb.config().name
+}
+
+func (cb *CircuitBreaker) Parent() *CircuitBreaker {
+	return cb.config().parent
+}
+
+func (cb *CircuitBreaker) Children() []*CircuitBreaker {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return append([]*CircuitBreaker(nil), cb.children...)
+}
+
+// Path возвращает имена Circuit Breaker от корня иерархии, разделенные "/".
+func (cb *CircuitBreaker) Path() string {
+	var names []string
+	for node := cb; node != nil; node = node.Parent() {
+		names = append(names, node.Name())
+	}
+	for i, j := 0, len(names)-1; i < j; i, j = i+1, j-1 {
+		names[i], names[j] = names[j], names[i]
+	}
+	return strings.Join(names, "/")
+}
+
+func (cb *CircuitBreaker) addChild(child *CircuitBreaker) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.children = append(cb.children, child)
+}
+
+// removeChild удаляет child из дочерних, например при его закрытии.
+func (cb *CircuitBreaker) removeChild(child *CircuitBreaker) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	for i, c := range cb.children {
+		if c == child {
+			cb.children = append(cb.children[:i:i], cb.children[i+1:]...)
+			return
+		}
+	}
+}
+
+// onChildOpen вызывается при переходе дочернего Circuit Breaker в Open.
+func (cb *CircuitBreaker) onChildOpen() {
+	threshold := cb.config().childTripThreshold
+	if threshold <= 0 {
+		return
+	}
+
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	// истекшее состояние Open без запросов не считается открытым
+	open := 0
+	for _, child := range cb.children {
+		if child.isOpen() {
+			open++
+		}
+	}
+	if open >= threshold && cb.current.Load().state != StateOpen {
+		cb.transition(StateOpen, ReasonChildren, nil)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+)
+
+func TestCircuitBreaker_Hierarchy(t *testing.T) {
+	parent := NewCircuitBreaker(
+		WithName("payments"),
+		WithTimeout(time.Hour),
+		WithChildTripThreshold(2),
+		WithReadyToTrip(func(Counts) bool { return false }),
+	)
+
+	newChild := func(name string) *CircuitBreaker {
+		return NewCircuitBreaker(
+			WithName(name),
+			WithParent(parent),
+			WithTimeout(time.Hour),
+			WithReadyToTrip(func(counts Counts) bool {
+				return counts.ConsecutiveFailures >= 1
+			}),
+		)
+	}
+	charge, refund, status := newChild("charge"), newChild("refund"), newChild("status")
+
+	assert.Equal(t, "payments/charge", charge.Path())
+	assert.Equal(t, []*CircuitBreaker{charge, refund, status}, parent.Children())
+
+	// результаты дочерних запросов учитываются и в родителе
+	assert.Nil(t, succeed(status))
+	assert.NotNil(t, fail(charge))
+	assert.Equal(t, StateOpen, charge.State())
+	assert.Equal(t, StateClosed, parent.State())
+	assert.Equal(t, Counts{2, 1, 1, 0, 1}, parent.Counts())
+
+	// отказ дочернего Circuit Breaker не считается ошибкой родителя
+	assert.ErrorIs(t, succeed(charge), ErrOpenState)
+	assert.Equal(t, Counts{2, 1, 1, 0, 1}, parent.Counts())
+
+	// второй открытый дочерний Circuit Breaker открывает родителя
+	assert.NotNil(t, fail(refund))
+	assert.Equal(t, StateOpen, parent.State())
+
+	// открытый родитель отклоняет запросы всех дочерних
+	assert.ErrorIs(t, succeed(status), ErrOpenState)
+	assert.Equal(t, StateClosed, status.State())
+}
+
+func TestCircuitBreaker_HierarchyStateChangeName(t *testing.T) {
+	var changes []StateChange
+
+	parent := NewCircuitBreaker(WithName("payments"))
+	child := NewCircuitBreaker(WithName("charge"), WithParent(parent))
+	child.onTransition = func(change StateChange) {
+		changes = append(changes, change)
+	}
+
+	child.trip()
+	assert.Len(t, changes, 1)
+	assert.Equal(t, "payments/charge", changes[0].Name)
+}
+
+func TestCircuitBreaker_HierarchyExpiredChild(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	parent := NewCircuitBreaker(
+		WithName("payments"),
+		WithClock(clock),
+		WithChildTripThreshold(2),
+		WithReadyToTrip(func(Counts) bool { return false }),
+	)
+	newChild := func(name string) *CircuitBreaker {
+		return NewCircuitBreaker(WithName(name), WithParent(parent), WithClock(clock), WithTimeout(10*time.Second))
+	}
+	charge, refund := newChild("charge"), newChild("refund")
+
+	// Open первого истек без запросов и не учитывается
+	charge.trip()
+	clock.Advance(11 * time.Second)
+	assert.Equal(t, StateOpen, charge.State())
+	refund.trip()
+	assert.Equal(t, StateClosed, parent.State())
+}
+
+func TestCircuitBreaker_HierarchyClose(t *testing.T) {
+	parent := NewCircuitBreaker(WithName("payments"))
+	charge := NewCircuitBreaker(WithName("charge"), WithParent(parent))
+	refund := NewCircuitBreaker(WithName("refund"), WithParent(parent))
+
+	require.NoError(t, charge.Close(context.Background()))
+	require.NoError(t, charge.Close(context.Background()))
+	assert.Equal(t, []*CircuitBreaker{refund}, parent.Children())
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// WithHistory включает хранение последних size событий Circuit Breaker
+// (см. Event) для History и Dump.
+func WithHistory(size int) Option {
+	return func(s *settings) {
+		s.historySize = size
+	}
+}
+
+// history - кольцевой буфер последних событий.
+type history struct {
+	mu     sync.Mutex
+	events []Event
+	// Индекс самого старого события, если буфер заполнен.
+	next int
+	full bool
+}
+
+func (h *history) record(event Event, size int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if cap(h.events) != size {
+		h.resize(size)
+	}
+	if !h.full {
+		h.events = append(h.events, event)
+		h.full = len(h.events) == size
+		return
+	}
+	h.events[h.next] = event
+	h.next = (h.next + 1) % size
+}
+
+// resize меняет размер буфера, сохраняя последние события.
+func (h *history) resize(size int) {
+	events := h.ordered()
+	if len(events) > size {
+		events = events[len(events)-size:]
+	}
+	h.events = append(make([]Event, 0, size), events...)
+	h.next = 0
+	h.full = len(h.events) == size
+}
+
+// ordered возвращает события от старых к новым.
+func (h *history) ordered() []Event {
+	events := make([]Event, 0, len(h.events))
+	events = append(events, h.events[h.next:]...)
+	return append(events, h.events[:h.next]...)
+}
+
+// History возвращает сохраненные события от старых к новым.
+func (cb *CircuitBreaker) History() []Event {
+	cb.history.mu.Lock()
+	defer cb.history.mu.Unlock()
+
+	return cb.history.ordered()
+}
+
+// Dump пишет сохраненные события в w по одному на строку.
+func (cb *CircuitBreaker) Dump(w io.Writer) error {
+	for _, event := range cb.History() {
+		line := fmt.Sprintf("%s %s %s state=%s", event.At.Format(time.RFC3339Nano), event.Name, event.Type, event.State)
+		if event.Type == EventStateChanged {
+			line += fmt.Sprintf(" from=%s to=%s", event.Change.From, event.Change.To)
+		}
+		if event.Err != nil {
+			line += fmt.Sprintf(" err=%q", event.Err.Error())
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
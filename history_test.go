@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithHistory(t *testing.T) {
+	clock := clocktest.New(time.Date(2024, 3, 1, 3, 0, 0, 0, time.UTC))
+	cb := NewCircuitBreaker(
+		WithName("payments"),
+		WithClock(clock),
+		WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures > 0 }),
+		WithHistory(4),
+	)
+
+	assert.Nil(t, succeed(cb))
+	assert.NotNil(t, fail(cb))
+	clock.Advance(time.Second)
+	assert.Equal(t, ErrOpenState, succeed(cb))
+
+	var types []EventType
+	for _, event := range cb.History() {
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []EventType{EventAdmitted, EventFailure, EventStateChanged, EventRejected}, types)
+
+	var buf strings.Builder
+	require.NoError(t, cb.Dump(&buf))
+	assert.Equal(t, `2024-03-01T03:00:00Z payments admitted state=closed
+2024-03-01T03:00:00Z payments failure state=closed err="fail"
+2024-03-01T03:00:00Z payments state-changed state=open from=closed to=open
+2024-03-01T03:00:01Z payments rejected state=open err="state is open"
+`, buf.String())
+}
+
+func TestWithHistory_Resize(t *testing.T) {
+	cb := NewCircuitBreaker(WithHistory(3))
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, succeed(cb))
+	}
+
+	cb.UpdateConfig(WithHistory(2))
+	assert.NotNil(t, fail(cb))
+
+	var types []EventType
+	for _, event := range cb.History() {
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []EventType{EventAdmitted, EventFailure}, types)
+	assert.Empty(t, NewCircuitBreaker().History())
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/http"
+	"time"
+)
+
+type HTTPClientOption func(*httpClientConfig)
+
+type httpClientConfig struct {
+	next      http.RoundTripper
+	timeout   time.Duration
+	registry  *Registry
+	breaker   []Option
+	group     []GroupOption
+	transport []TransportOption
+}
+
+// WithHTTPClientTransport задает транспорт, через который выполняются запросы.
+// По умолчанию http.DefaultTransport.
+func WithHTTPClientTransport(next http.RoundTripper) HTTPClientOption {
+	return func(c *httpClientConfig) {
+		c.next = next
+	}
+}
+
+// WithHTTPClientTimeout задает http.Client.Timeout. По умолчанию без ограничения.
+func WithHTTPClientTimeout(timeout time.Duration) HTTPClientOption {
+	return func(c *httpClientConfig) {
+		c.timeout = timeout
+	}
+}
+
+// WithHTTPClientRegistry создает Circuit Breaker хостов в реестре r, чтобы они
+// попадали в его статистику и метрики, например NewPrometheusCollector.
+// Хосты, вытесненные из группы, удаляются из r, см. WithGroupRegistry.
+func WithHTTPClientRegistry(r *Registry) HTTPClientOption {
+	return func(c *httpClientConfig) {
+		c.registry = r
+	}
+}
+
+// WithHTTPClientBreaker задает настройки Circuit Breaker каждого хоста.
+func WithHTTPClientBreaker(options ...Option) HTTPClientOption {
+	return func(c *httpClientConfig) {
+		c.breaker = append(c.breaker, options...)
+	}
+}
+
+// WithHTTPClientGroup задает настройки группы Circuit Breaker хостов,
+// например WithGroupCapacity.
+func WithHTTPClientGroup(options ...GroupOption) HTTPClientOption {
+	return func(c *httpClientConfig) {
+		c.group = append(c.group, options...)
+	}
+}
+
+// WithHTTPClientTransportOptions задает настройки Transport, например ключ
+// WithTransportKey или политику WithTransportStatusPolicy.
+func WithHTTPClientTransportOptions(options ...TransportOption) HTTPClientOption {
+	return func(c *httpClientConfig) {
+		c.transport = append(c.transport, options...)
+	}
+}
+
+// NewHTTPClient создает http.Client, выполняющий запросы через Transport
+// с отдельным Circuit Breaker на каждый хост и классификацией ответов по статусу.
+func NewHTTPClient(options ...HTTPClientOption) *http.Client {
+	c := &httpClientConfig{}
+	for _, opt := range options {
+		opt(c)
+	}
+
+	factory := func(key string) *CircuitBreaker {
+		if c.registry != nil {
+			return c.registry.Get(key, c.breaker...)
+		}
+		return NewCircuitBreaker(append([]Option{WithName(key)}, c.breaker...)...)
+	}
+	groupOptions := c.group
+	if c.registry != nil {
+		groupOptions = append([]GroupOption{WithGroupRegistry(c.registry)}, c.group...)
+	}
+	group := NewGroup(factory, groupOptions...)
+
+	return &http.Client{
+		Transport: NewTransport(c.next, group, c.transport...),
+		Timeout:   c.timeout,
+	}
+}
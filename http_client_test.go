@@ -0,0 +1,75 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+	host := mustParseURL(t, server.URL).Host
+
+	r := NewRegistry()
+	client := NewHTTPClient(
+		WithHTTPClientRegistry(r),
+		WithHTTPClientTimeout(5*time.Second),
+		WithHTTPClientBreaker(WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures >= 2 })),
+	)
+	assert.Equal(t, 5*time.Second, client.Timeout)
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	}
+	_, err := client.Get(server.URL)
+	assert.ErrorIs(t, err, ErrOpenState)
+
+	cb, ok := r.Lookup(host)
+	require.True(t, ok)
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Equal(t, uint64(2), cb.Stats().Failures)
+}
+
+func TestNewHTTPClient_Options(t *testing.T) {
+	next := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusTooManyRequests, Body: http.NoBody, Request: req}, nil
+	})
+	var evicted []string
+	client := NewHTTPClient(
+		WithHTTPClientTransport(next),
+		WithHTTPClientGroup(WithGroupCapacity(1), WithGroupEviction(func(key string, _ *CircuitBreaker) {
+			evicted = append(evicted, key)
+		})),
+		WithHTTPClientTransportOptions(
+			WithTransportKey(HostMethodKey),
+			WithTransportStatusPolicy(FailOnStatus(http.StatusTooManyRequests)),
+		),
+		WithHTTPClientBreaker(WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures >= 1 })),
+	)
+
+	_, err := client.Get("http://a.example.com/")
+	require.NoError(t, err)
+	_, err = client.Get("http://a.example.com/")
+	assert.ErrorIs(t, err, ErrOpenState)
+
+	_, err = client.Get("http://b.example.com/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GET a.example.com"}, evicted)
+}
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	return u
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type TransportOption func(*Transport)
+
+// WithTransportKey задает ключ Circuit Breaker для запроса. По умолчанию HostKey.
+func WithTransportKey(key func(req *http.Request) string) TransportOption {
+	return func(t *Transport) {
+		t.key = key
+	}
+}
+
+// WithTransportStatusPolicy задает, какие статусы ответа считаются неуспешными
+// запросами. По умолчанию DefaultStatusPolicy.
+func WithTransportStatusPolicy(isFailure func(status int) bool) TransportOption {
+	return func(t *Transport) {
+		t.isFailure = isFailure
+	}
+}
+
+// DefaultStatusPolicy считает неуспешными ответы со статусом 5xx.
+// Ответы 4xx, включая 429, считаются успешными: сервер доступен и ответил.
+func DefaultStatusPolicy(status int) bool {
+	return status >= http.StatusInternalServerError
+}
+
+// FailOnStatus возвращает политику, считающую неуспешными ответы 5xx
+// и ответы со статусами statuses, например http.StatusTooManyRequests.
+func FailOnStatus(statuses ...int) func(status int) bool {
+	return func(status int) bool {
+		for _, s := range statuses {
+			if status == s {
+				return true
+			}
+		}
+		return DefaultStatusPolicy(status)
+	}
+}
+
+// StatusError - ошибка, которой Circuit Breaker учитывает ответ с неуспешным
+// статусом, например в LastError. Клиенту ответ возвращается без ошибки.
+type StatusError struct {
+	StatusCode int
+	// Значение заголовка Retry-After ответа 429 или 503, если он был.
+	// Если ответ переводит Circuit Breaker в Open, состояние длится
+	// RetryAfter вместо WithTimeout, с учетом WithMinOpenDuration и WithMaxOpenDuration.
+	Retry time.Duration
+}
+
+func (e *StatusError) RetryAfter() time.Duration {
+	return e.Retry
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// HostKey возвращает хост назначения запроса вместе с портом, если он указан.
+func HostKey(req *http.Request) string {
+	return req.URL.Host
+}
+
+// HostMethodKey возвращает метод и хост назначения запроса, например "POST api.example.com".
+func HostMethodKey(req *http.Request) string {
+	return req.Method + " " + req.URL.Host
+}
+
+// Transport - http.RoundTripper, выполняющий запросы через Circuit Breaker группы
+// по ключу запроса, чтобы недоступность одного хоста не блокировала запросы к остальным.
+// Ошибки next и ответы с неуспешным статусом, см. WithTransportStatusPolicy,
+// учитываются как неуспешные запросы.
+type Transport struct {
+	next      http.RoundTripper
+	group     *Group
+	key       func(req *http.Request) string
+	isFailure func(status int) bool
+}
+
+var _ http.RoundTripper = (*Transport)(nil)
+
+// NewTransport создает Transport поверх next. Если next равен nil,
+// используется http.DefaultTransport.
+func NewTransport(next http.RoundTripper, group *Group, options ...TransportOption) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	t := &Transport{next: next, group: group, key: HostKey, isFailure: DefaultStatusPolicy}
+	for _, opt := range options {
+		opt(t)
+	}
+	return t
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var sent bool
+	cb := t.group.Get(t.key(req))
+	resp, err := cb.ExecuteContext(req.Context(), func(context.Context) (interface{}, error) {
+		sent = true
+		resp, err := t.next.RoundTrip(req)
+		if err == nil && t.isFailure(resp.StatusCode) {
+			return resp, newStatusError(resp.StatusCode, resp.Header.Get("Retry-After"), cb.clock().Now())
+		}
+		return resp, err
+	})
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && resp != nil {
+		return resp.(*http.Response), nil
+	}
+	if err != nil {
+		// RoundTripper закрывает тело запроса, даже если запрос не был отправлен
+		if !sent && req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+	return resp.(*http.Response), nil
+}
+
+// newStatusError возвращает ошибку статуса status. HTTP-дата в retryAfter
+// отсчитывается от now, времени Circuit Breaker.
+func newStatusError(status int, retryAfter string, now time.Time) *StatusError {
+	err := &StatusError{StatusCode: status}
+	if status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable {
+		err.Retry = parseRetryAfter(retryAfter, now)
+	}
+	return err
+}
+
+// parseRetryAfter разбирает значение Retry-After в секундах или HTTP-дату.
+// Для некорректного или прошедшего значения возвращает 0.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	at, err := http.ParseTime(value)
+	if err != nil || !at.After(now) {
+		return 0
+	}
+	return at.Sub(now)
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// trackedBody запоминает, что тело запроса было закрыто.
+type trackedBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *trackedBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func tripOnFirstFailure() *Group {
+	return NewGroup(func(key string) *CircuitBreaker {
+		return NewCircuitBreaker(WithName(key), WithReadyToTrip(func(counts Counts) bool {
+			return counts.ConsecutiveFailures >= 1
+		}))
+	})
+}
+
+func TestTransport(t *testing.T) {
+	next := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host == "a.example.com" {
+			return nil, errors.New("connection refused")
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	})
+	group := tripOnFirstFailure()
+	client := &http.Client{Transport: NewTransport(next, group)}
+
+	_, err := client.Get("http://a.example.com/")
+	assert.ErrorContains(t, err, "connection refused")
+	_, err = client.Get("http://a.example.com/")
+	assert.ErrorIs(t, err, ErrOpenState)
+
+	// недоступность одного хоста не влияет на другой
+	resp, err := client.Get("http://b.example.com/")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, group.Len())
+
+	body := &trackedBody{Reader: strings.NewReader("payload")}
+	req, err := http.NewRequest(http.MethodPost, "http://a.example.com/", body)
+	require.NoError(t, err)
+	_, err = client.Do(req)
+	assert.ErrorIs(t, err, ErrOpenState)
+	assert.True(t, body.closed)
+}
+
+func TestTransport_HostMethodKey(t *testing.T) {
+	next := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Method == http.MethodPost {
+			return nil, errors.New("connection reset")
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	})
+	group := tripOnFirstFailure()
+	client := &http.Client{Transport: NewTransport(next, group, WithTransportKey(HostMethodKey))}
+
+	_, err := client.Post("http://a.example.com/", "text/plain", nil)
+	assert.Error(t, err)
+	assert.Equal(t, StateOpen, group.Get("POST a.example.com").State())
+
+	_, err = client.Get("http://a.example.com/")
+	assert.NoError(t, err)
+	assert.Equal(t, StateClosed, group.Get("GET a.example.com").State())
+}
+
+func TestTransport_StatusPolicy(t *testing.T) {
+	next := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		status := map[string]int{
+			"/unavailable": http.StatusServiceUnavailable,
+			"/limited":     http.StatusTooManyRequests,
+			"/missing":     http.StatusNotFound,
+		}[req.URL.Path]
+		return &http.Response{StatusCode: status, Body: http.NoBody, Request: req}, nil
+	})
+
+	tests := []struct {
+		name     string
+		options  []TransportOption
+		path     string
+		failures uint64
+	}{
+		{"5xx", nil, "/unavailable", 1},
+		{"4xx", nil, "/missing", 0},
+		{"429 default", nil, "/limited", 0},
+		{"429 configured", []TransportOption{WithTransportStatusPolicy(FailOnStatus(http.StatusTooManyRequests))}, "/limited", 1},
+		{"custom", []TransportOption{WithTransportStatusPolicy(func(status int) bool { return status == http.StatusNotFound })}, "/missing", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := tripOnFirstFailure()
+			client := &http.Client{Transport: NewTransport(next, group, tt.options...)}
+
+			// ответ возвращается клиенту без ошибки, даже если учтен как неуспешный
+			resp, err := client.Get("http://a.example.com" + tt.path)
+			require.NoError(t, err)
+			assert.NotZero(t, resp.StatusCode)
+			assert.Equal(t, tt.failures, group.Get("a.example.com").Stats().Failures)
+		})
+	}
+
+	group := tripOnFirstFailure()
+	client := &http.Client{Transport: NewTransport(next, group)}
+	_, err := client.Get("http://a.example.com/unavailable")
+	require.NoError(t, err)
+	var statusErr *StatusError
+	require.ErrorAs(t, group.Get("a.example.com").LastError(), &statusErr)
+	assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+	assert.EqualError(t, statusErr, "unexpected status 503 Service Unavailable")
+}
+
+func TestTransport_RetryAfter(t *testing.T) {
+	next := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		header := http.Header{"Retry-After": {"30"}}
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: header, Body: http.NoBody, Request: req}, nil
+	})
+	clock := clocktest.New(time.Now())
+	group := NewGroup(func(string) *CircuitBreaker {
+		return NewCircuitBreaker(
+			WithTimeProvider(clock),
+			WithMaxOpenDuration(time.Minute),
+			WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures >= 1 }),
+		)
+	})
+	client := &http.Client{Transport: NewTransport(next, group)}
+
+	_, err := client.Get("http://a.example.com/")
+	require.NoError(t, err)
+	cb := group.Get("a.example.com")
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Equal(t, clock.Now().Add(30*time.Second), cb.Snapshot().Expiry)
+}
+
+func TestTransport_RetryAfterDate(t *testing.T) {
+	clock := clocktest.New(time.Date(2024, 3, 1, 3, 0, 0, 0, time.UTC))
+	next := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		// дата отсчитывается от времени Circuit Breaker, а не от системного
+		header := http.Header{"Retry-After": {"Fri, 01 Mar 2024 03:00:45 GMT"}}
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: header, Body: http.NoBody, Request: req}, nil
+	})
+	group := NewGroup(func(string) *CircuitBreaker {
+		return NewCircuitBreaker(
+			WithClock(clock),
+			WithMaxOpenDuration(time.Minute),
+			WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures >= 1 }),
+		)
+	})
+	client := &http.Client{Transport: NewTransport(next, group)}
+
+	_, err := client.Get("http://a.example.com/")
+	require.NoError(t, err)
+	assert.Equal(t, clock.Now().Add(45*time.Second), group.Get("a.example.com").Snapshot().Expiry)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 3, 1, 3, 0, 0, 0, time.UTC)
+	assert.Equal(t, 120*time.Second, parseRetryAfter("120", now))
+	assert.Equal(t, 90*time.Second, parseRetryAfter("Fri, 01 Mar 2024 03:01:30 GMT", now))
+	assert.Zero(t, parseRetryAfter("Fri, 01 Mar 2024 02:59:00 GMT", now))
+	assert.Zero(t, parseRetryAfter("", now))
+	assert.Zero(t, parseRetryAfter("-5", now))
+	assert.Zero(t, parseRetryAfter("soon", now))
+}
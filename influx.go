@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clock"
+)
+
+// InfluxExporter периодически записывает метрики Circuit Breaker реестра
+// в формате InfluxDB line protocol: одна строка на Circuit Breaker,
+// все строки одного экспорта - одной записью в io.Writer.
+type InfluxExporter struct {
+	registry    *Registry
+	w           io.Writer
+	measurement string
+	tags        map[string]string
+	interval    time.Duration
+	clock       clock.Clock
+	onError     func(err error)
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type InfluxOption func(*InfluxExporter)
+
+// WithInfluxMeasurement задает имя measurement. По умолчанию "circuit_breaker".
+func WithInfluxMeasurement(measurement string) InfluxOption {
+	return func(e *InfluxExporter) {
+		e.measurement = measurement
+	}
+}
+
+// WithInfluxTags задает теги, добавляемые ко всем строкам, например host.
+func WithInfluxTags(tags map[string]string) InfluxOption {
+	return func(e *InfluxExporter) {
+		e.tags = tags
+	}
+}
+
+// WithInfluxInterval задает период экспорта. По умолчанию 10s.
+func WithInfluxInterval(interval time.Duration) InfluxOption {
+	return func(e *InfluxExporter) {
+		e.interval = interval
+	}
+}
+
+// WithInfluxClock задает источник времени для таймера и меток времени строк.
+func WithInfluxClock(c clock.Clock) InfluxOption {
+	return func(e *InfluxExporter) {
+		e.clock = c
+	}
+}
+
+// WithInfluxOnError задает обработчик ошибок записи. По умолчанию ошибки игнорируются.
+func WithInfluxOnError(onError func(err error)) InfluxOption {
+	return func(e *InfluxExporter) {
+		e.onError = onError
+	}
+}
+
+// NewInfluxExporter создает экспортер метрик реестра r в w, например
+// в TCP/UDP socket_listener Telegraf или NewInfluxHTTPWriter.
+// Экспорт запускается через Start.
+func NewInfluxExporter(r *Registry, w io.Writer, options ...InfluxOption) *InfluxExporter {
+	e := &InfluxExporter{
+		registry:    r,
+		w:           w,
+		measurement: "circuit_breaker",
+		interval:    10 * time.Second,
+		clock:       clock.Real{},
+	}
+	for _, opt := range options {
+		opt(e)
+	}
+	return e
+}
+
+// Start запускает периодический экспорт. Повторный вызов ничего не делает.
+func (e *InfluxExporter) Start() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.done = make(chan struct{})
+
+	go func() {
+		defer close(e.done)
+		e.run(ctx)
+	}()
+}
+
+// Stop останавливает периодический экспорт и дожидается его завершения.
+func (e *InfluxExporter) Stop() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.cancel == nil {
+		return
+	}
+
+	e.cancel()
+	<-e.done
+	e.cancel = nil
+	e.done = nil
+}
+
+func (e *InfluxExporter) run(ctx context.Context) {
+	timer := e.clock.NewTimer(e.interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C():
+			if err := e.Export(); err != nil && e.onError != nil {
+				e.onError(err)
+			}
+			timer.Reset(e.interval)
+		}
+	}
+}
+
+// Export записывает текущие метрики всех Circuit Breaker реестра.
+// Поля: state, requests, failure_rate, failures_total, rejections_total,
+// trips_total и, если включен WithLatencyStats, перцентили latency_*_ms.
+func (e *InfluxExporter) Export() error {
+	var buf bytes.Buffer
+	ts := strconv.FormatInt(e.clock.Now().UnixNano(), 10)
+	e.registry.Range(func(name string, cb *CircuitBreaker) bool {
+		e.writeLine(&buf, name, cb.Stats(), ts)
+		return true
+	})
+	if buf.Len() == 0 {
+		return nil
+	}
+	_, err := e.w.Write(buf.Bytes())
+	return err
+}
+
+func (e *InfluxExporter) writeLine(buf *bytes.Buffer, name string, stats Stats, ts string) {
+	tags := make(map[string]string, len(e.tags)+len(stats.Labels)+1)
+	for k, v := range stats.Labels {
+		tags[k] = v
+	}
+	for k, v := range e.tags {
+		tags[k] = v
+	}
+	tags["name"] = name
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	buf.WriteString(influxMeasurementEscaper.Replace(e.measurement))
+	for _, k := range keys {
+		if tags[k] == "" {
+			continue
+		}
+		buf.WriteString("," + influxTagEscaper.Replace(k) + "=" + influxTagEscaper.Replace(tags[k]))
+	}
+
+	var failureRate float64
+	if stats.Counts.Requests > 0 {
+		failureRate = float64(stats.Counts.TotalFailures) / float64(stats.Counts.Requests)
+	}
+	fmt.Fprintf(buf, ` state="%s",requests=%di,failure_rate=%s,failures_total=%di,rejections_total=%di,trips_total=%di`,
+		stats.State, stats.Counts.Requests, influxFloat(failureRate), stats.Failures, stats.Rejections, stats.Transitions.Trips)
+	if latency := stats.Latency; latency.Samples > 0 {
+		fmt.Fprintf(buf, ",latency_p50_ms=%s,latency_p90_ms=%s,latency_p99_ms=%s",
+			influxMillis(latency.P50), influxMillis(latency.P90), influxMillis(latency.P99))
+	}
+	buf.WriteString(" " + ts + "\n")
+}
+
+var (
+	influxMeasurementEscaper = strings.NewReplacer(",", `\,`, " ", `\ `)
+	influxTagEscaper         = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `)
+)
+
+func influxFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func influxMillis(d time.Duration) string {
+	return influxFloat(float64(d) / float64(time.Millisecond))
+}
+
+// NewInfluxHTTPWriter возвращает io.Writer, отправляющий каждую запись
+// POST-запросом на url, например "http://localhost:8086/api/v2/write?org=o&bucket=b"
+// или адрес http_listener_v2 Telegraf. Непустой token передается в заголовке Authorization.
+func NewInfluxHTTPWriter(url, token string) io.Writer {
+	return &influxHTTPWriter{url: url, token: token, client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+type influxHTTPWriter struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func (w *influxHTTPWriter) Write(p []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(p))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if w.token != "" {
+		req.Header.Set("Authorization", "Token "+w.token)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("influx: unexpected status %s", resp.Status)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer - bytes.Buffer, безопасный для записи из горутины экспортера.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestInfluxExporter_Export(t *testing.T) {
+	clock := clocktest.New(time.Date(2024, 3, 1, 3, 0, 0, 0, time.UTC))
+	r := NewRegistry(WithDefaults(WithClock(clock)))
+	payments := r.Get("payments", WithLabels(map[string]string{"team": "bil ling"}), WithLatencyStats())
+	for _, d := range []time.Duration{10, 20} {
+		_, err := payments.Execute(func() (interface{}, error) {
+			clock.Advance(d * time.Millisecond)
+			return nil, nil
+		})
+		require.NoError(t, err)
+	}
+	r.Get("search").Trip()
+
+	var buf bytes.Buffer
+	e := NewInfluxExporter(r, &buf, WithInfluxClock(clock), WithInfluxTags(map[string]string{"host": "a1"}))
+	require.NoError(t, e.Export())
+	assert.Equal(t, `circuit_breaker,host=a1,name=payments,team=bil\ ling state="closed",requests=2i,failure_rate=0,failures_total=0i,rejections_total=0i,trips_total=0i,latency_p50_ms=10,latency_p90_ms=10,latency_p99_ms=10 1709262000030000000
+circuit_breaker,host=a1,name=search state="open",requests=0i,failure_rate=0,failures_total=0i,rejections_total=0i,trips_total=1i 1709262000030000000
+`, buf.String())
+}
+
+func TestInfluxExporter_Start(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	r := NewRegistry()
+	r.Get("payments")
+
+	var buf syncBuffer
+	e := NewInfluxExporter(r, &buf, WithInfluxClock(clock), WithInfluxInterval(time.Second), WithInfluxMeasurement("cb"))
+	e.Start()
+	defer e.Stop()
+
+	assert.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+	assert.Empty(t, buf.String())
+
+	clock.Advance(time.Second)
+	assert.Eventually(t, func() bool { return buf.String() != "" }, time.Second, time.Millisecond)
+	assert.Contains(t, buf.String(), "cb,name=payments state=\"closed\"")
+}
+
+func TestInfluxHTTPWriter(t *testing.T) {
+	var body, auth string
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		data, _ := io.ReadAll(req.Body)
+		body, auth = string(data), req.Header.Get("Authorization")
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	w := NewInfluxHTTPWriter(server.URL+"/api/v2/write?bucket=b", "secret")
+	n, err := w.Write([]byte("cb value=1i\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 12, n)
+	assert.Equal(t, "cb value=1i\n", body)
+	assert.Equal(t, "Token secret", auth)
+
+	status = http.StatusBadRequest
+	_, err = w.Write([]byte("bad"))
+	assert.EqualError(t, err, "influx: unexpected status 400 Bad Request")
+}
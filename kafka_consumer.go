@@ -0,0 +1,198 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+// KafkaPauser - потребитель, поддерживающий остановку чтения разделов, например
+// sarama.Consumer или sarama.ConsumerGroup.
+type KafkaPauser interface {
+	Pause(partitions map[string][]int32)
+	Resume(partitions map[string][]int32)
+}
+
+type KafkaPauseOption func(*KafkaPauseController)
+
+// WithKafkaPartitionKey задает имя Circuit Breaker обработки сообщений раздела
+// partition топика topic. По умолчанию KafkaPartitionTopicKey.
+func WithKafkaPartitionKey(key func(topic string, partition int32) string) KafkaPauseOption {
+	return func(c *KafkaPauseController) {
+		c.key = key
+	}
+}
+
+// KafkaPartitionTopicKey возвращает топик, один Circuit Breaker на топик.
+func KafkaPartitionTopicKey(topic string, _ int32) string {
+	return topic
+}
+
+// KafkaPauseController останавливает чтение разделов, обработка сообщений
+// которых защищена Circuit Breaker в состоянии Open, чтобы потребитель не
+// получал сообщения, которые не может обработать. Чтение возобновляется при
+// выходе из Open или по истечении времени состояния Open, чтобы пробные
+// сообщения перевели Circuit Breaker в Half-Open. Сообщения, полученные до
+// остановки, по-прежнему доставляются обработчику.
+//
+//	pauses := NewKafkaPauseController()
+//	group := NewGroup(func(topic string) *CircuitBreaker {
+//		return NewCircuitBreaker(WithName(topic), WithKafkaPause(pauses))
+//	})
+//	err := consumerGroup.Consume(ctx, topics, pauses.Handler(consumerGroup, handler))
+type KafkaPauseController struct {
+	key func(topic string, partition int32) string
+
+	mu         sync.Mutex
+	pauser     KafkaPauser
+	partitions map[string][]int32
+	// Остановленные Circuit Breaker по имени.
+	paused map[string]*kafkaPause
+}
+
+// kafkaPause - остановка чтения разделов на время состояния Open.
+type kafkaPause struct {
+	// Закрывается при возобновлении чтения.
+	stop chan struct{}
+}
+
+func NewKafkaPauseController(options ...KafkaPauseOption) *KafkaPauseController {
+	c := &KafkaPauseController{key: KafkaPartitionTopicKey, paused: make(map[string]*kafkaPause)}
+	for _, opt := range options {
+		opt(c)
+	}
+	return c
+}
+
+// WithKafkaPause сообщает c о переходах Circuit Breaker. Имя Circuit Breaker
+// должно совпадать с ключом разделов, см. WithKafkaPartitionKey.
+func WithKafkaPause(c *KafkaPauseController) Option {
+	return withObserver(c)
+}
+
+// Assign задает потребителя pauser и его разделы partitions. Разделы, Circuit
+// Breaker которых находится в состоянии Open, сразу останавливаются.
+func (c *KafkaPauseController) Assign(pauser KafkaPauser, partitions map[string][]int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pauser, c.partitions = pauser, partitions
+	for name := range c.paused {
+		if matching := c.matching(name); len(matching) > 0 {
+			pauser.Pause(matching)
+		}
+	}
+}
+
+// Revoke сбрасывает разделы, заданные Assign, не возобновляя их чтение.
+func (c *KafkaPauseController) Revoke() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pauser, c.partitions = nil, nil
+}
+
+// Handler возвращает обработчик группы потребителей consumer, передающий
+// разделы каждой сессии в Assign и сбрасывающий их по ее завершении.
+func (c *KafkaPauseController) Handler(consumer sarama.ConsumerGroup, handler sarama.ConsumerGroupHandler) sarama.ConsumerGroupHandler {
+	return &kafkaPauseHandler{ConsumerGroupHandler: handler, consumer: consumer, controller: c}
+}
+
+type kafkaPauseHandler struct {
+	sarama.ConsumerGroupHandler
+	consumer   sarama.ConsumerGroup
+	controller *KafkaPauseController
+}
+
+func (h *kafkaPauseHandler) Setup(session sarama.ConsumerGroupSession) error {
+	h.controller.Assign(h.consumer, session.Claims())
+	return h.ConsumerGroupHandler.Setup(session)
+}
+
+func (h *kafkaPauseHandler) Cleanup(session sarama.ConsumerGroupSession) error {
+	h.controller.Revoke()
+	return h.ConsumerGroupHandler.Cleanup(session)
+}
+
+func (c *KafkaPauseController) observeTransition(cb *CircuitBreaker, change StateChange) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name := cb.Name()
+	switch {
+	case change.To == StateOpen:
+		if _, ok := c.paused[name]; ok || cb.lifetime.Err() != nil {
+			return
+		}
+		p := &kafkaPause{stop: make(chan struct{})}
+		c.paused[name] = p
+		if matching := c.matching(name); len(matching) > 0 && c.pauser != nil {
+			c.pauser.Pause(matching)
+		}
+		cb.background.Add(1)
+		go c.wait(cb, name, p, cb.current.Load().expiry.Sub(change.At))
+	case change.From == StateOpen:
+		c.resume(name)
+	}
+}
+
+func (*KafkaPauseController) observeCall(*CircuitBreaker, OutcomeRecord) {}
+
+func (*KafkaPauseController) observeRejection(*CircuitBreaker, error) {}
+
+// wait возобновляет чтение по истечении времени состояния Open, если
+// Circuit Breaker не вышел из него раньше.
+func (c *KafkaPauseController) wait(cb *CircuitBreaker, name string, p *kafkaPause, open time.Duration) {
+	defer cb.background.Done()
+
+	timer := cb.clock().NewTimer(open)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+	case <-p.stop:
+		return
+	case <-cb.lifetime.Done():
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.paused[name] == p {
+		c.resume(name)
+	}
+}
+
+// resume возобновляет чтение разделов Circuit Breaker name.
+// Вызывается под блокировкой c.mu.
+func (c *KafkaPauseController) resume(name string) {
+	p, ok := c.paused[name]
+	if !ok {
+		return
+	}
+	delete(c.paused, name)
+	close(p.stop)
+	if matching := c.matching(name); len(matching) > 0 && c.pauser != nil {
+		c.pauser.Resume(matching)
+	}
+}
+
+// matching возвращает разделы, ключ которых совпадает с name.
+// Вызывается под блокировкой c.mu.
+func (c *KafkaPauseController) matching(name string) map[string][]int32 {
+	var matching map[string][]int32
+	for topic, partitions := range c.partitions {
+		for _, partition := range partitions {
+			if c.key(topic, partition) != name {
+				continue
+			}
+			if matching == nil {
+				matching = make(map[string][]int32)
+			}
+			matching[topic] = append(matching[topic], partition)
+		}
+	}
+	return matching
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/raymanovg/circuit-breaker/clocktest"
+	"github.com/stretchr/testify/assert"
+)
+
+// testPauser записывает остановленные и возобновленные разделы.
+type testPauser struct {
+	sarama.ConsumerGroup
+
+	mu      sync.Mutex
+	paused  []map[string][]int32
+	resumed []map[string][]int32
+}
+
+func (p *testPauser) Pause(partitions map[string][]int32) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paused = append(p.paused, partitions)
+}
+
+func (p *testPauser) Resume(partitions map[string][]int32) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.resumed = append(p.resumed, partitions)
+}
+
+func (p *testPauser) calls() (paused, resumed []map[string][]int32) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]map[string][]int32(nil), p.paused...), append([]map[string][]int32(nil), p.resumed...)
+}
+
+type testSession struct {
+	sarama.ConsumerGroupSession
+	claims map[string][]int32
+}
+
+func (s *testSession) Claims() map[string][]int32 {
+	return s.claims
+}
+
+type testConsumerHandler struct {
+	setup, cleanup int
+}
+
+func (h *testConsumerHandler) Setup(sarama.ConsumerGroupSession) error {
+	h.setup++
+	return nil
+}
+
+func (h *testConsumerHandler) Cleanup(sarama.ConsumerGroupSession) error {
+	h.cleanup++
+	return nil
+}
+
+func (*testConsumerHandler) ConsumeClaim(sarama.ConsumerGroupSession, sarama.ConsumerGroupClaim) error {
+	return nil
+}
+
+func TestKafkaPauseController(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	pauses := NewKafkaPauseController()
+	cb := NewCircuitBreaker(
+		WithName("orders"),
+		WithClock(clock),
+		WithTimeout(10*time.Second),
+		WithMaxRequests(1),
+		WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures > 0 }),
+		WithKafkaPause(pauses),
+	)
+	defer cb.Close(context.Background())
+
+	pauser := &testPauser{}
+	pauses.Assign(pauser, map[string][]int32{"orders": {0, 1}, "payments": {0}})
+
+	assert.NotNil(t, fail(cb))
+	paused, resumed := pauser.calls()
+	assert.Equal(t, []map[string][]int32{{"orders": {0, 1}}}, paused)
+	assert.Empty(t, resumed)
+
+	// по истечении времени Open чтение возобновляется для пробных сообщений
+	assert.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+	clock.Advance(11 * time.Second)
+	assert.Eventually(t, func() bool {
+		_, resumed := pauser.calls()
+		return len(resumed) == 1
+	}, time.Second, time.Millisecond)
+
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateClosed, cb.State())
+	paused, resumed = pauser.calls()
+	assert.Len(t, paused, 1)
+	assert.Equal(t, []map[string][]int32{{"orders": {0, 1}}}, resumed)
+}
+
+func TestKafkaPauseController_Handler(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	pauses := NewKafkaPauseController(WithKafkaPartitionKey(func(topic string, partition int32) string {
+		if partition == 0 {
+			return topic + "-leader"
+		}
+		return topic
+	}))
+	cb := NewCircuitBreaker(WithName("orders-leader"), WithClock(clock), WithKafkaPause(pauses))
+	defer cb.Close(context.Background())
+
+	// разделы, назначенные в состоянии Open, останавливаются сразу
+	cb.trip()
+	consumer := &testPauser{}
+	handler := &testConsumerHandler{}
+	session := &testSession{claims: map[string][]int32{"orders": {0, 1}}}
+	wrapped := pauses.Handler(consumer, handler)
+	assert.NoError(t, wrapped.Setup(session))
+	assert.NoError(t, wrapped.Cleanup(session))
+	assert.Equal(t, 1, handler.setup)
+	assert.Equal(t, 1, handler.cleanup)
+
+	paused, _ := consumer.calls()
+	assert.Equal(t, []map[string][]int32{{"orders": {0}}}, paused)
+
+	// после Cleanup разделы не возобновляются
+	assert.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+	clock.Advance(time.Minute)
+	assert.Eventually(t, func() bool { return clock.Timers() == 0 }, time.Second, time.Millisecond)
+	_, resumed := consumer.calls()
+	assert.Empty(t, resumed)
+}
+
+func TestKafkaPauseController_Parent(t *testing.T) {
+	pauses := NewKafkaPauseController()
+	parent := NewCircuitBreaker(WithName("kafka"))
+	cb := NewCircuitBreaker(WithName("orders"), WithParent(parent), WithKafkaPause(pauses))
+	defer cb.Close(context.Background())
+
+	// разделы сопоставляются с именем, а не с путем "kafka/orders"
+	pauser := &testPauser{}
+	pauses.Assign(pauser, map[string][]int32{"orders": {0}})
+	cb.trip()
+	cb.Reset()
+
+	paused, resumed := pauser.calls()
+	assert.Equal(t, []map[string][]int32{{"orders": {0}}}, paused)
+	assert.Equal(t, []map[string][]int32{{"orders": {0}}}, resumed)
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/IBM/sarama"
+)
+
+type KafkaProducerOption func(*KafkaProducer)
+
+// WithKafkaProducerKey задает ключ Circuit Breaker для сообщения, например
+// KafkaBrokerKey. По умолчанию KafkaTopicKey.
+func WithKafkaProducerKey(key func(msg *sarama.ProducerMessage) string) KafkaProducerOption {
+	return func(p *KafkaProducer) {
+		p.key = key
+	}
+}
+
+// WithKafkaProducerFailurePolicy задает, какие ошибки отправки считаются
+// неуспешными запросами. По умолчанию DefaultKafkaFailurePolicy.
+func WithKafkaProducerFailurePolicy(isFailure func(err error) bool) KafkaProducerOption {
+	return func(p *KafkaProducer) {
+		p.isFailure = isFailure
+	}
+}
+
+// WithKafkaProducerFallback задает обработчик сообщений, отклоненных Circuit
+// Breaker, например запись во внешний буфер для повторной отправки. Если
+// fallback возвращает nil, сообщения считаются принятыми: SendMessage возвращает
+// partition и offset -1 без ошибки. По умолчанию сообщения не отправляются и
+// завершаются ошибкой отказа.
+func WithKafkaProducerFallback(fallback func(msgs []*sarama.ProducerMessage, err error) error) KafkaProducerOption {
+	return func(p *KafkaProducer) {
+		p.fallback = fallback
+	}
+}
+
+// KafkaTopicKey возвращает топик сообщения, один Circuit Breaker на топик.
+func KafkaTopicKey(msg *sarama.ProducerMessage) string {
+	return msg.Topic
+}
+
+// KafkaBrokerKey возвращает ключ по адресу брокера - лидера раздела сообщения,
+// один Circuit Breaker на брокер. Раздел выбирается partitioner, который должен
+// быть детерминированным и совпадать с Producer.Partitioner производителя,
+// например sarama.NewHashPartitioner. Если лидер неизвестен, возвращается топик.
+func KafkaBrokerKey(client sarama.Client, partitioner sarama.PartitionerConstructor) func(msg *sarama.ProducerMessage) string {
+	return func(msg *sarama.ProducerMessage) string {
+		partitions, err := client.Partitions(msg.Topic)
+		if err != nil || len(partitions) == 0 {
+			return msg.Topic
+		}
+		partition, err := partitioner(msg.Topic).Partition(msg, int32(len(partitions)))
+		if err != nil {
+			return msg.Topic
+		}
+		leader, err := client.Leader(msg.Topic, partition)
+		if err != nil {
+			return msg.Topic
+		}
+		return leader.Addr()
+	}
+}
+
+// DefaultKafkaFailurePolicy считает неуспешными ошибки соединения и ошибки
+// брокеров, означающие недоступность кластера. Ошибки самого сообщения,
+// например sarama.ErrMessageSizeTooLarge, и ошибки доступа считаются успешными
+// запросами.
+func DefaultKafkaFailurePolicy(err error) bool {
+	for _, clientErr := range []error{
+		sarama.ErrMessageSizeTooLarge, sarama.ErrInvalidMessage, sarama.ErrInvalidMessageSize,
+		sarama.ErrInvalidTopic, sarama.ErrUnknownTopicOrPartition, sarama.ErrTopicAuthorizationFailed,
+		sarama.ErrClusterAuthorizationFailed, sarama.ErrSASLAuthenticationFailed, sarama.ErrClosedClient,
+	} {
+		if errors.Is(err, clientErr) {
+			return false
+		}
+	}
+	return true
+}
+
+// KafkaProducer выполняет синхронную отправку сообщений sarama.SyncProducer
+// через Circuit Breaker группы по ключу сообщения. Остальные методы, например
+// транзакции, выполняются без Circuit Breaker.
+type KafkaProducer struct {
+	sarama.SyncProducer
+	group     *Group
+	key       func(msg *sarama.ProducerMessage) string
+	isFailure func(err error) bool
+	fallback  func(msgs []*sarama.ProducerMessage, err error) error
+}
+
+func NewKafkaProducer(producer sarama.SyncProducer, group *Group, options ...KafkaProducerOption) *KafkaProducer {
+	p := &KafkaProducer{SyncProducer: producer, group: group, key: KafkaTopicKey, isFailure: DefaultKafkaFailurePolicy}
+	for _, opt := range options {
+		opt(p)
+	}
+	return p
+}
+
+func (p *KafkaProducer) SendMessage(msg *sarama.ProducerMessage) (partition int32, offset int64, err error) {
+	cb := p.group.Get(p.key(msg))
+	done, err := cb.allow()
+	if err != nil {
+		return -1, -1, p.reject(cb, []*sarama.ProducerMessage{msg}, err)
+	}
+
+	partition, offset, err = p.SyncProducer.SendMessage(msg)
+	done(p.outcome(err))
+	return partition, offset, err
+}
+
+// SendMessages отправляет сообщения одним запросом. Каждый Circuit Breaker
+// учитывает результат своих сообщений; сообщения, отклоненные Circuit Breaker,
+// не отправляются и возвращаются в sarama.ProducerErrors вместе с ошибками отправки.
+func (p *KafkaProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
+	type batch struct {
+		cb   *CircuitBreaker
+		done func(err error)
+		err  error
+		msgs []*sarama.ProducerMessage
+	}
+	batches := make(map[string]*batch)
+	var keys []string
+	var admitted []*sarama.ProducerMessage
+
+	for _, msg := range msgs {
+		key := p.key(msg)
+		b, ok := batches[key]
+		if !ok {
+			b = &batch{cb: p.group.Get(key)}
+			b.done, b.err = b.cb.allow()
+			batches[key] = b
+			keys = append(keys, key)
+		}
+		b.msgs = append(b.msgs, msg)
+		if b.err == nil {
+			admitted = append(admitted, msg)
+		}
+	}
+
+	var errs sarama.ProducerErrors
+	for _, key := range keys {
+		b := batches[key]
+		if b.err == nil {
+			continue
+		}
+		if err := p.reject(b.cb, b.msgs, b.err); err != nil {
+			for _, msg := range b.msgs {
+				errs = append(errs, &sarama.ProducerError{Msg: msg, Err: err})
+			}
+		}
+	}
+
+	if len(admitted) > 0 {
+		err := p.SyncProducer.SendMessages(admitted)
+		failed := make(map[*sarama.ProducerMessage]error)
+		var producerErrs sarama.ProducerErrors
+		if errors.As(err, &producerErrs) {
+			for _, producerErr := range producerErrs {
+				failed[producerErr.Msg] = producerErr.Err
+			}
+			errs = append(errs, producerErrs...)
+		} else if err != nil {
+			for _, msg := range admitted {
+				failed[msg] = err
+				errs = append(errs, &sarama.ProducerError{Msg: msg, Err: err})
+			}
+		}
+
+		for _, b := range batches {
+			if b.err != nil {
+				continue
+			}
+			var outcome error
+			for _, msg := range b.msgs {
+				if outcome = p.outcome(failed[msg]); outcome != nil {
+					break
+				}
+			}
+			b.done(outcome)
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// outcome возвращает err, если она считается неуспешным запросом, иначе nil.
+func (p *KafkaProducer) outcome(err error) error {
+	if err == nil || !p.isFailure(err) {
+		return nil
+	}
+	return err
+}
+
+// reject передает отклоненные Circuit Breaker cb сообщения fallback и возвращает
+// его ошибку, а без fallback - ошибку отказа err.
+func (p *KafkaProducer) reject(cb *CircuitBreaker, msgs []*sarama.ProducerMessage, err error) error {
+	err = fmt.Errorf("circuit breaker %s: %w", cb.Path(), err)
+	if p.fallback == nil {
+		return err
+	}
+	return p.fallback(msgs, err)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKafkaProducer(t *testing.T) {
+	mock := mocks.NewSyncProducer(t, nil)
+	group := tripOnFirstFailure()
+	producer := NewKafkaProducer(mock, group)
+
+	mock.ExpectSendMessageAndSucceed()
+	mock.ExpectSendMessageAndFail(sarama.ErrMessageSizeTooLarge)
+	_, _, err := producer.SendMessage(&sarama.ProducerMessage{Topic: "orders"})
+	require.NoError(t, err)
+	_, _, err = producer.SendMessage(&sarama.ProducerMessage{Topic: "orders"})
+	assert.ErrorIs(t, err, sarama.ErrMessageSizeTooLarge)
+	cb := group.Get("orders")
+	assert.Equal(t, uint32(2), cb.Counts().TotalSuccess)
+
+	mock.ExpectSendMessageAndFail(sarama.ErrOutOfBrokers)
+	_, _, err = producer.SendMessage(&sarama.ProducerMessage{Topic: "orders"})
+	assert.ErrorIs(t, err, sarama.ErrOutOfBrokers)
+	assert.Equal(t, StateOpen, cb.State())
+
+	partition, offset, err := producer.SendMessage(&sarama.ProducerMessage{Topic: "orders"})
+	assert.ErrorIs(t, err, ErrOpenState)
+	assert.EqualError(t, err, "circuit breaker orders: "+ErrOpenState.Error())
+	assert.Equal(t, int32(-1), partition)
+	assert.Equal(t, int64(-1), offset)
+
+	mock.ExpectSendMessageAndSucceed()
+	_, _, err = producer.SendMessage(&sarama.ProducerMessage{Topic: "payments"})
+	assert.NoError(t, err)
+	require.NoError(t, mock.Close())
+}
+
+func TestKafkaProducer_SendMessages(t *testing.T) {
+	mock := mocks.NewSyncProducer(t, nil)
+	group := tripOnFirstFailure()
+	producer := NewKafkaProducer(mock, group)
+
+	mock.ExpectSendMessageAndFail(sarama.ErrNotLeaderForPartition)
+	require.Error(t, producer.SendMessages([]*sarama.ProducerMessage{{Topic: "orders"}}))
+	require.Equal(t, StateOpen, group.Get("orders").State())
+
+	mock.ExpectSendMessageAndSucceed()
+	mock.ExpectSendMessageAndSucceed()
+	msgs := []*sarama.ProducerMessage{{Topic: "orders"}, {Topic: "payments"}, {Topic: "orders"}, {Topic: "payments"}}
+	err := producer.SendMessages(msgs)
+
+	var errs sarama.ProducerErrors
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 2)
+	for _, producerErr := range errs {
+		assert.Equal(t, "orders", producerErr.Msg.Topic)
+		assert.ErrorIs(t, producerErr.Err, ErrOpenState)
+	}
+	assert.Equal(t, uint32(1), group.Get("payments").Counts().TotalSuccess)
+	require.NoError(t, mock.Close())
+}
+
+func TestKafkaProducer_Fallback(t *testing.T) {
+	mock := mocks.NewSyncProducer(t, nil)
+	group := tripOnFirstFailure()
+	var buffered []*sarama.ProducerMessage
+	producer := NewKafkaProducer(mock, group, WithKafkaProducerFallback(func(msgs []*sarama.ProducerMessage, err error) error {
+		if !errors.Is(err, ErrOpenState) {
+			return err
+		}
+		buffered = append(buffered, msgs...)
+		return nil
+	}))
+
+	mock.ExpectSendMessageAndFail(sarama.ErrRequestTimedOut)
+	_, _, err := producer.SendMessage(&sarama.ProducerMessage{Topic: "orders"})
+	require.ErrorIs(t, err, sarama.ErrRequestTimedOut)
+
+	_, offset, err := producer.SendMessage(&sarama.ProducerMessage{Topic: "orders", Key: sarama.StringEncoder("a")})
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1), offset)
+	require.NoError(t, producer.SendMessages([]*sarama.ProducerMessage{{Topic: "orders"}, {Topic: "orders"}}))
+	assert.Len(t, buffered, 3)
+	require.NoError(t, mock.Close())
+}
+
+func TestKafkaProducer_PolicyAndKey(t *testing.T) {
+	mock := mocks.NewSyncProducer(t, nil)
+	group := tripOnFirstFailure()
+	producer := NewKafkaProducer(mock, group,
+		WithKafkaProducerKey(func(*sarama.ProducerMessage) string { return "cluster" }),
+		WithKafkaProducerFailurePolicy(func(err error) bool { return errors.Is(err, sarama.ErrMessageSizeTooLarge) }))
+
+	mock.ExpectSendMessageAndFail(sarama.ErrOutOfBrokers)
+	_, _, err := producer.SendMessage(&sarama.ProducerMessage{Topic: "orders"})
+	require.Error(t, err)
+	assert.Equal(t, StateClosed, group.Get("cluster").State())
+
+	mock.ExpectSendMessageAndFail(sarama.ErrMessageSizeTooLarge)
+	_, _, err = producer.SendMessage(&sarama.ProducerMessage{Topic: "payments"})
+	require.Error(t, err)
+	assert.Equal(t, StateOpen, group.Get("cluster").State())
+	require.NoError(t, mock.Close())
+}
+
+func TestKafkaBrokerKey(t *testing.T) {
+	seed := sarama.NewMockBroker(t, 1)
+	defer seed.Close()
+	leader := sarama.NewMockBroker(t, 2)
+	defer leader.Close()
+	seed.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(seed.Addr(), seed.BrokerID()).
+			SetBroker(leader.Addr(), leader.BrokerID()).
+			SetLeader("orders", 0, leader.BrokerID()),
+	})
+
+	client, err := sarama.NewClient([]string{seed.Addr()}, sarama.NewConfig())
+	require.NoError(t, err)
+	defer client.Close()
+
+	key := KafkaBrokerKey(client, sarama.NewHashPartitioner)
+	assert.Equal(t, leader.Addr(), key(&sarama.ProducerMessage{Topic: "orders", Key: sarama.StringEncoder("a")}))
+}
+
+func TestDefaultKafkaFailurePolicy(t *testing.T) {
+	assert.True(t, DefaultKafkaFailurePolicy(sarama.ErrOutOfBrokers))
+	assert.True(t, DefaultKafkaFailurePolicy(sarama.ErrNotLeaderForPartition))
+	assert.True(t, DefaultKafkaFailurePolicy(errors.New("dial tcp: connection refused")))
+	assert.False(t, DefaultKafkaFailurePolicy(sarama.ErrMessageSizeTooLarge))
+	assert.False(t, DefaultKafkaFailurePolicy(sarama.ErrTopicAuthorizationFailed))
+}
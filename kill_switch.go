@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// KillSwitchMode - принудительный режим работы Circuit Breaker.
+type KillSwitchMode int32
+
+const (
+	// KillSwitchOff - обычная работа.
+	KillSwitchOff KillSwitchMode = iota
+	// KillSwitchForceOpen - все запросы отклоняются с ErrOpenState, состояние не меняется.
+	KillSwitchForceOpen
+	// KillSwitchForceClosed - все запросы выполняются независимо от состояния,
+	// но их результаты учитываются как обычно.
+	KillSwitchForceClosed
+	// KillSwitchDisabled - запросы выполняются напрямую, Circuit Breaker
+	// и его родитель их не учитывают.
+	KillSwitchDisabled
+)
+
+// KillSwitch сообщает принудительный режим Circuit Breaker по имени.
+// Обычно это адаптер к системе feature-флагов.
+type KillSwitch interface {
+	Mode(name string) KillSwitchMode
+}
+
+// WithKillSwitch подключает принудительное управление режимом через ks.
+// При нулевом pollInterval режим запрашивается у ks при каждом запросе,
+// иначе - в фоне раз в pollInterval до вызова Close.
+func WithKillSwitch(ks KillSwitch, pollInterval time.Duration) Option {
+	return func(s *settings) {
+		s.killSwitch = ks
+		s.killSwitchPollInterval = pollInterval
+	}
+}
+
+func (m KillSwitchMode) String() string {
+	switch m {
+	case KillSwitchOff:
+		return "off"
+	case KillSwitchForceOpen:
+		return "force-open"
+	case KillSwitchForceClosed:
+		return "force-closed"
+	case KillSwitchDisabled:
+		return "disabled"
+	default:
+		return fmt.Sprintf("KillSwitchMode(%d)", int32(m))
+	}
+}
+
+// killSwitchState хранит последний режим, полученный опросом,
+// и режим, заданный вручную через SetMode.
+type killSwitchState struct {
+	polled   atomic.Int32
+	override atomic.Int32
+}
+
+// SetMode вручную задает принудительный режим. Режим, отличный от KillSwitchOff,
+// имеет приоритет над WithKillSwitch.
+func (cb *CircuitBreaker) SetMode(mode KillSwitchMode) {
+	cb.killSwitch.override.Store(int32(mode))
+}
+
+// mode возвращает текущий принудительный режим.
+func (cb *CircuitBreaker) mode() KillSwitchMode {
+	if override := KillSwitchMode(cb.killSwitch.override.Load()); override != KillSwitchOff {
+		return override
+	}
+
+	s := cb.config()
+	switch {
+	case s.killSwitch == nil:
+		return KillSwitchOff
+	case s.killSwitchPollInterval > 0:
+		return KillSwitchMode(cb.killSwitch.polled.Load())
+	default:
+		return s.killSwitch.Mode(s.name)
+	}
+}
+
+// startKillSwitchPolling опрашивает KillSwitch из настроек s до вызова Close
+// или изменения настроек опроса, см. restartPollers.
+func (cb *CircuitBreaker) startKillSwitchPolling(s *settings) {
+	cb.killSwitch.polled.Store(int32(s.killSwitch.Mode(s.name)))
+
+	ctx, cancel := context.WithCancel(cb.lifetime)
+	cb.pollers.killSwitch = cancel
+
+	cb.background.Add(1)
+	go func() {
+		defer cb.background.Done()
+
+		timer := cb.clock().NewTimer(s.killSwitchPollInterval)
+		defer timer.Stop()
+
+		for tick(ctx, timer, s.killSwitchPollInterval) {
+			// KillSwitch и имя могли измениться через UpdateConfig
+			if s := cb.config(); s.killSwitch != nil {
+				cb.killSwitch.polled.Store(int32(s.killSwitch.Mode(s.name)))
+			}
+		}
+	}()
+}
+
+// admit допускает запрос с учетом принудительного режима.
+func (cb *CircuitBreaker) admit(mode KillSwitchMode) (uint64, error) {
+	switch mode {
+	case KillSwitchForceOpen:
+		return 0, cb.reject(ErrOpenState)
+	case KillSwitchForceClosed:
+		cb.counts.onRequest()
+		return cb.current.Load().generation, nil
+	}
+	return cb.beforeRequest()
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testKillSwitch struct {
+	mu    sync.Mutex
+	modes map[string]KillSwitchMode
+}
+
+func (ks *testKillSwitch) Mode(name string) KillSwitchMode {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	return ks.modes[name]
+}
+
+func (ks *testKillSwitch) set(name string, mode KillSwitchMode) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.modes[name] = mode
+}
+
+func TestWithKillSwitch(t *testing.T) {
+	ks := &testKillSwitch{modes: map[string]KillSwitchMode{}}
+	cb := NewCircuitBreaker(
+		WithName("payments"),
+		WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures >= 2 }),
+		WithKillSwitch(ks, 0),
+	)
+
+	ks.set("payments", KillSwitchForceOpen)
+	_, err := cb.Execute(func() (interface{}, error) { return nil, nil })
+	assert.Equal(t, ErrOpenState, err)
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, uint64(1), cb.Stats().Rejections)
+
+	ks.set("payments", KillSwitchDisabled)
+	assert.NotNil(t, fail(cb))
+	assert.NotNil(t, fail(cb))
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Zero(t, cb.Counts().Requests)
+
+	// В режиме ForceClosed результаты учитываются и состояние меняется,
+	// но запросы не отклоняются.
+	ks.set("payments", KillSwitchForceClosed)
+	assert.NotNil(t, fail(cb))
+	assert.NotNil(t, fail(cb))
+	assert.Equal(t, StateOpen, cb.State())
+	assert.Nil(t, succeed(cb))
+
+	ks.set("payments", KillSwitchOff)
+	assert.Equal(t, ErrOpenState, succeed(cb))
+}
+
+func TestWithKillSwitch_Parent(t *testing.T) {
+	ks := &testKillSwitch{modes: map[string]KillSwitchMode{"parent": KillSwitchForceOpen}}
+	parent := NewCircuitBreaker(WithName("parent"), WithKillSwitch(ks, 0))
+	child := NewCircuitBreaker(WithName("child"), WithParent(parent))
+
+	assert.Equal(t, ErrOpenState, succeed(child))
+	assert.Zero(t, child.Counts().Requests)
+
+	ks.set("parent", KillSwitchDisabled)
+	assert.Nil(t, succeed(child))
+	assert.Equal(t, uint32(1), child.Counts().Requests)
+	assert.Zero(t, parent.Counts().Requests)
+}
+
+func TestWithKillSwitch_Polling(t *testing.T) {
+	ks := &testKillSwitch{modes: map[string]KillSwitchMode{"payments": KillSwitchForceOpen}}
+	cb := NewCircuitBreaker(WithName("payments"), WithKillSwitch(ks, time.Millisecond))
+	defer cb.Close(context.Background())
+
+	assert.Equal(t, ErrOpenState, succeed(cb))
+
+	ks.set("payments", KillSwitchOff)
+	assert.Eventually(t, func() bool {
+		return succeed(cb) == nil
+	}, time.Second, time.Millisecond)
+}
+
+func TestWithKillSwitch_UpdatePollInterval(t *testing.T) {
+	ks := &testKillSwitch{modes: map[string]KillSwitchMode{}}
+	cb := NewCircuitBreaker(WithName("payments"), WithKillSwitch(ks, 0))
+	defer cb.Close(context.Background())
+
+	// опрос запускается при включении интервала, а не только при создании
+	ks.set("payments", KillSwitchForceOpen)
+	cb.UpdateConfig(WithKillSwitch(ks, time.Millisecond))
+	assert.Equal(t, ErrOpenState, succeed(cb))
+
+	ks.set("payments", KillSwitchOff)
+	assert.Eventually(t, func() bool {
+		return succeed(cb) == nil
+	}, time.Second, time.Millisecond)
+
+	// и останавливается при его выключении
+	cb.UpdateConfig(WithKillSwitch(ks, 0))
+	ks.set("payments", KillSwitchForceOpen)
+	assert.Equal(t, ErrOpenState, succeed(cb))
+	assert.Nil(t, cb.pollers.killSwitch)
+}
+
+func TestCircuitBreaker_SetMode(t *testing.T) {
+	ks := &testKillSwitch{modes: map[string]KillSwitchMode{"payments": KillSwitchForceOpen}}
+	cb := NewCircuitBreaker(WithName("payments"), WithKillSwitch(ks, 0))
+	assert.Equal(t, KillSwitchForceOpen, cb.Mode())
+	assert.Equal(t, ErrOpenState, succeed(cb))
+
+	cb.SetMode(KillSwitchForceClosed)
+	assert.Equal(t, "force-closed", cb.Mode().String())
+	assert.Nil(t, succeed(cb))
+
+	cb.Reset()
+	assert.Equal(t, KillSwitchForceOpen, cb.Mode())
+}
@@ -0,0 +1,17 @@
+package main
+
+import "maps"
+
+// WithLabels задает произвольные метки Circuit Breaker (команда, уровень, тип зависимости).
+// Метки передаются в уведомления о смене состояния и в экспорт метрик.
+func WithLabels(labels map[string]string) Option {
+	labels = maps.Clone(labels)
+	return func(s *settings) {
+		s.labels = labels
+	}
+}
+
+// Labels возвращает копию меток Circuit Breaker.
+func (cb *CircuitBreaker) Labels() map[string]string {
+	return maps.Clone(cb.config().labels)
+}
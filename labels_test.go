@@ -0,0 +1,23 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker_Labels(t *testing.T) {
+	labels := map[string]string{"team": "billing", "tier": "1"}
+	cb := NewCircuitBreaker(WithLabels(labels))
+
+	// изменение исходной карты не влияет на Circuit Breaker
+	labels["tier"] = "2"
+	assert.Equal(t, map[string]string{"team": "billing", "tier": "1"}, cb.Labels())
+
+	var changes []StateChange
+	cb.onTransition = func(change StateChange) {
+		changes = append(changes, change)
+	}
+	cb.trip()
+	assert.Equal(t, "billing", changes[0].Labels["team"])
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencySampleSize - кол-во последних длительностей запросов, по которым
+// считается Stats.Latency.
+const latencySampleSize = 256
+
+// Latency - длительности последних выполненных запросов.
+type Latency struct {
+	// Кол-во учтенных запросов, не больше 256.
+	Samples int
+	Mean    time.Duration
+	P50     time.Duration
+	P90     time.Duration
+	P99     time.Duration
+	Max     time.Duration
+}
+
+// WithLatencyStats включает учет длительностей запросов в Stats.Latency.
+// Повторный вызов заменяет уже добавленный учет, а не дублирует его.
+func WithLatencyStats() Option {
+	return func(s *settings) {
+		for i, o := range s.observers {
+			if _, ok := o.(latencyObserver); ok {
+				s.observers = append([]observer(nil), s.observers...)
+				s.observers[i] = latencyObserver{}
+				return
+			}
+		}
+		withObserver(latencyObserver{})(s)
+	}
+}
+
+type latencyObserver struct{}
+
+func (latencyObserver) observeCall(cb *CircuitBreaker, record OutcomeRecord) {
+	cb.latency.record(record.Duration)
+}
+
+func (latencyObserver) observeTransition(*CircuitBreaker, StateChange) {}
+
+func (latencyObserver) observeRejection(*CircuitBreaker, error) {}
+
+// latencySamples - кольцевой буфер длительностей последних запросов.
+type latencySamples struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+func (l *latencySamples) record(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.samples) < latencySampleSize {
+		l.samples = append(l.samples, d)
+		return
+	}
+	l.samples[l.next] = d
+	l.next = (l.next + 1) % latencySampleSize
+}
+
+func (l *latencySamples) summary() Latency {
+	l.mu.Lock()
+	samples := append([]time.Duration(nil), l.samples...)
+	l.mu.Unlock()
+
+	if len(samples) == 0 {
+		return Latency{}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+	var total time.Duration
+	for _, d := range samples {
+		total += d
+	}
+	percentile := func(p int) time.Duration {
+		return samples[(len(samples)-1)*p/100]
+	}
+	return Latency{
+		Samples: len(samples),
+		Mean:    total / time.Duration(len(samples)),
+		P50:     percentile(50),
+		P90:     percentile(90),
+		P99:     percentile(99),
+		Max:     samples[len(samples)-1],
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+)
+
+// Close останавливает фоновую работу Circuit Breaker и сбрасывает накопленные данные:
+//   - останавливает проверки доступности и синтетические запросы,
+//     отменяя контекст выполняющихся проверок;
+//   - удаляет Circuit Breaker из дочерних у родителя, см. WithParent;
+//   - дожидается доставки уведомлений о смене состояния из очереди;
+//   - дожидается фонового сохранения и сохраняет текущее состояние в StateStore,
+//     см. PersistContext.
+//
+// Если ctx истекает раньше, Close возвращает ошибку ctx, не дожидаясь остального.
+// После Close Circuit Breaker продолжает обрабатывать запросы, но фоновые задачи
+// больше не запускаются. Повторный вызов безопасен.
+func (cb *CircuitBreaker) Close(ctx context.Context) error {
+	cb.mu.Lock()
+	cb.cancel()
+	cb.mu.Unlock()
+
+	if parent := cb.Parent(); parent != nil {
+		parent.removeChild(cb)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		cb.background.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if err := cb.waitNotified(ctx); err != nil {
+		return err
+	}
+
+	return cb.PersistContext(ctx)
+}
+
+// Close останавливает фоновую горутину реестра и закрывает все его Circuit Breaker.
+// Возвращает все ошибки закрытия.
+func (r *Registry) Close(ctx context.Context) error {
+	r.Stop()
+
+	r.mu.RLock()
+	breakers := make([]*CircuitBreaker, 0, len(r.breakers))
+	for _, cb := range r.breakers {
+		breakers = append(breakers, cb)
+	}
+	r.mu.RUnlock()
+
+	var errs []error
+	for _, cb := range breakers {
+		if err := cb.Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker_Close(t *testing.T) {
+	goroutines := runtime.NumGoroutine()
+
+	store, err := NewFileStateStore(t.TempDir())
+	require.NoError(t, err)
+
+	var (
+		checks    atomic.Int32
+		delivered atomic.Int32
+	)
+	cb := NewCircuitBreaker(
+		WithName("payments"),
+		WithTimeout(time.Hour),
+		WithStateStore(store, nil),
+		WithOnStateChange(func(StateChange) {
+			time.Sleep(10 * time.Millisecond)
+			delivered.Add(1)
+		}),
+		WithHealthCheck(func(ctx context.Context) error {
+			checks.Add(1)
+			return errors.New("unhealthy")
+		}, time.Millisecond),
+	)
+	cb.trip()
+	assert.Eventually(t, func() bool {
+		return checks.Load() > 0
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, cb.Close(context.Background()))
+	require.NoError(t, cb.Close(context.Background()))
+
+	// уведомления доставлены, состояние сохранено, проверки остановлены
+	assert.Equal(t, int32(1), delivered.Load())
+	snapshot, ok, err := store.Load("payments")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, StateOpen, snapshot.State)
+
+	stopped := checks.Load()
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, stopped, checks.Load())
+	assert.LessOrEqual(t, runtime.NumGoroutine(), goroutines)
+
+	// после Close фоновые задачи не запускаются
+	cb.expire(cb.current.Load().generation)
+	cb.trip()
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, stopped, checks.Load())
+}
+
+func TestCircuitBreaker_CloseDeadline(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	cb := NewCircuitBreaker(WithOnStateChange(func(StateChange) {
+		<-release
+	}))
+	cb.trip()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, cb.Close(ctx), context.DeadlineExceeded)
+}
+
+// contextStore - ContextStateStore, запоминающий контекст последнего сохранения.
+// Пока release не закрыт, Save блокируется.
+type contextStore struct {
+	release chan struct{}
+	ctx     context.Context
+}
+
+func (*contextStore) Load(string) (Snapshot, bool, error) {
+	return Snapshot{}, false, nil
+}
+
+func (s *contextStore) Save(string, Snapshot) error {
+	<-s.release
+	return nil
+}
+
+func (s *contextStore) SaveContext(ctx context.Context, _ string, _ Snapshot) error {
+	s.ctx = ctx
+	return nil
+}
+
+func TestCircuitBreaker_ClosePersistContext(t *testing.T) {
+	store := &contextStore{release: make(chan struct{})}
+	cb := NewCircuitBreaker(WithStateStore(store, nil))
+	cb.trip()
+
+	// фоновое сохранение заблокировано, Close ограничен ctx
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, cb.Close(ctx), context.DeadlineExceeded)
+
+	close(store.release)
+	type ctxKey struct{}
+	ctx = context.WithValue(context.Background(), ctxKey{}, "shutdown")
+	require.NoError(t, cb.Close(ctx))
+	assert.Equal(t, "shutdown", store.ctx.Value(ctxKey{}))
+}
+
+func TestRegistry_Close(t *testing.T) {
+	r := NewRegistry(WithExpiryModel(ExpiryBackground))
+	r.Start()
+
+	cb := r.Get("payments", WithHealthCheck(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, time.Millisecond))
+	cb.trip()
+
+	require.NoError(t, r.Close(context.Background()))
+	assert.ErrorIs(t, cb.lifetime.Err(), context.Canceled)
+}
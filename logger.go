@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+type LogLevel int
+
+const (
+	LogDebug LogLevel = iota
+	LogInfo
+	LogWarn
+	LogError
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogDebug:
+		return "debug"
+	case LogInfo:
+		return "info"
+	case LogWarn:
+		return "warn"
+	case LogError:
+		return "error"
+	default:
+		return fmt.Sprintf("LogLevel(%d)", int(l))
+	}
+}
+
+// Logger - минимальный интерфейс структурированного логирования.
+// keyvals - чередующиеся ключи и значения.
+type Logger interface {
+	Log(level LogLevel, msg string, keyvals ...any)
+}
+
+// rejectionLogInterval - минимальный интервал между записями об отклоненных запросах.
+const rejectionLogInterval = time.Second
+
+// WithLogger включает запись в logger смены состояний, результатов проверок
+// доступности и синтетических запросов, а также отклоненных запросов
+// не чаще раза в секунду с их кол-вом за это время.
+func WithLogger(logger Logger) Option {
+	return func(s *settings) {
+		s.logger = logger
+	}
+}
+
+// WithLogSummary включает периодическую запись в Logger сводки о состоянии,
+// счетчиках и кол-ве отклоненных и неуспешных запросов. Сводка пишется
+// раз в interval до вызова Close.
+func WithLogSummary(interval time.Duration) Option {
+	return func(s *settings) {
+		s.logSummaryInterval = interval
+	}
+}
+
+// rejectionLog накапливает отклоненные запросы между записями в лог.
+type rejectionLog struct {
+	pending atomic.Uint64
+	last    atomic.Int64
+}
+
+// log пишет запись с именем Circuit Breaker, если задан Logger.
+func (cb *CircuitBreaker) log(level LogLevel, msg string, keyvals ...any) {
+	if logger := cb.config().logger; logger != nil {
+		logger.Log(level, msg, append([]any{"name", cb.Path()}, keyvals...)...)
+	}
+}
+
+func (cb *CircuitBreaker) logRejection(err error) {
+	rl := &cb.rejectionLog
+	rl.pending.Add(1)
+
+	now := cb.config().timeProvider.Now().UnixNano()
+	last := rl.last.Load()
+	if last != 0 && now-last < int64(rejectionLogInterval) || !rl.last.CompareAndSwap(last, now) {
+		return
+	}
+
+	cb.log(LogWarn, "requests rejected", "count", rl.pending.Swap(0), "error", err)
+}
+
+// startLogSummary пишет сводку в лог раз в интервал из настроек s до вызова
+// Close или изменения настроек сводки, см. restartPollers.
+func (cb *CircuitBreaker) startLogSummary(s *settings) {
+	interval := s.logSummaryInterval
+
+	ctx, cancel := context.WithCancel(cb.lifetime)
+	cb.pollers.logSummary = cancel
+
+	cb.background.Add(1)
+	go func() {
+		defer cb.background.Done()
+
+		timer := cb.clock().NewTimer(interval)
+		defer timer.Stop()
+
+		for tick(ctx, timer, interval) {
+			stats := cb.Stats()
+			cb.log(LogInfo, "summary",
+				"state", stats.State.String(),
+				"counts", stats.Counts,
+				"rejections", stats.Rejections,
+				"failures", stats.Failures,
+			)
+		}
+	}()
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+	"github.com/stretchr/testify/assert"
+)
+
+type testLogger struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (l *testLogger) Log(level LogLevel, msg string, keyvals ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, fmt.Sprint(level, " ", msg, " ", keyvals))
+}
+
+func (l *testLogger) Entries() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.entries...)
+}
+
+func TestWithLogger(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	logger := &testLogger{}
+	cb := NewCircuitBreaker(
+		WithName("payments"),
+		WithTimeout(time.Minute),
+		WithClock(clock),
+		WithLogger(logger),
+	)
+
+	cb.trip()
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, ErrOpenState, succeed(cb))
+	}
+	clock.Advance(time.Second)
+	assert.Equal(t, ErrOpenState, succeed(cb))
+
+	assert.Equal(t, []string{
+		"info state changed [name payments from closed to open counts {0 0 0 0 0}]",
+		"warn requests rejected [name payments count 1 error state is open]",
+		"warn requests rejected [name payments count 3 error state is open]",
+	}, logger.Entries())
+}
+
+func TestWithLogger_HealthCheck(t *testing.T) {
+	logger := &testLogger{}
+	var calls int
+	cb := NewCircuitBreaker(
+		WithName("payments"),
+		WithTimeout(time.Hour),
+		WithLogger(logger),
+		WithHealthCheck(func(ctx context.Context) error {
+			if calls++; calls == 1 {
+				return errors.New("unhealthy")
+			}
+			return nil
+		}, time.Millisecond),
+	)
+	defer cb.Close(context.Background())
+
+	cb.trip()
+	assert.Eventually(t, func() bool {
+		return cb.State() == StateHalfOpen
+	}, time.Second, time.Millisecond)
+
+	assert.Equal(t, []string{
+		"info state changed [name payments from closed to open counts {0 0 0 0 0}]",
+		"debug health check failed [name payments error unhealthy]",
+		"info health check succeeded [name payments]",
+		"info state changed [name payments from open to half-open counts {0 0 0 0 0}]",
+	}, logger.Entries())
+}
+
+func TestWithLogSummary_Update(t *testing.T) {
+	logger := &testLogger{}
+	cb := NewCircuitBreaker(WithName("payments"), WithLogger(logger))
+	defer cb.Close(context.Background())
+
+	cb.UpdateConfig(WithLogSummary(time.Millisecond))
+	assert.Eventually(t, func() bool {
+		entries := logger.Entries()
+		return len(entries) > 0 && strings.Contains(entries[0], "info summary [name payments state closed")
+	}, time.Second, time.Millisecond)
+}
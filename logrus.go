@@ -0,0 +1,35 @@
+package main
+
+import "github.com/sirupsen/logrus"
+
+// WithLogrus пишет в logger запись о каждой смене состояния с полями name,
+// from, to и error - ошибкой запроса, вызвавшего переход. Переход в Open
+// записывается с уровнем Warn, остальные - с уровнем Info.
+func WithLogrus(logger logrus.FieldLogger) Option {
+	return withObserver(logrusObserver{logger})
+}
+
+type logrusObserver struct {
+	logger logrus.FieldLogger
+}
+
+func (o logrusObserver) observeTransition(_ *CircuitBreaker, change StateChange) {
+	entry := o.logger.WithFields(logrus.Fields{
+		"name": change.Name,
+		"from": change.From.String(),
+		"to":   change.To.String(),
+	})
+	if change.Err != nil {
+		entry = entry.WithError(change.Err)
+	}
+
+	if change.To == StateOpen {
+		entry.Warn("circuit breaker state changed")
+	} else {
+		entry.Info("circuit breaker state changed")
+	}
+}
+
+func (logrusObserver) observeCall(*CircuitBreaker, OutcomeRecord) {}
+
+func (logrusObserver) observeRejection(*CircuitBreaker, error) {}
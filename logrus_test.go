@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithLogrus(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	cb := NewCircuitBreaker(
+		WithName("payments"),
+		WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures > 0 }),
+		WithLogrus(logger),
+	)
+
+	assert.NotNil(t, fail(cb))
+	cb.mu.Lock()
+	cb.setState(StateHalfOpen)
+	cb.mu.Unlock()
+
+	entries := hook.AllEntries()
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, logrus.WarnLevel, entries[0].Level)
+	assert.Equal(t, "circuit breaker state changed", entries[0].Message)
+	assert.Equal(t, "payments", entries[0].Data["name"])
+	assert.Equal(t, "closed", entries[0].Data["from"])
+	assert.Equal(t, "open", entries[0].Data["to"])
+	assert.EqualError(t, entries[0].Data[logrus.ErrorKey].(error), "fail")
+
+	assert.Equal(t, logrus.InfoLevel, entries[1].Level)
+	assert.Equal(t, "half-open", entries[1].Data["to"])
+	assert.NotContains(t, entries[1].Data, logrus.ErrorKey)
+}
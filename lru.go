@@ -0,0 +1,73 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+)
+
+// lru - кэш значений по ключу, вытесняющий давно использованные значения
+// сверх capacity. При capacity <= 0 значения не вытесняются.
+type lru[K comparable, V any] struct {
+	capacity int
+
+	mu      sync.Mutex
+	entries map[K]*list.Element
+	order   *list.List
+}
+
+type lruEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+func newLRU[K comparable, V any](capacity int) *lru[K, V] {
+	return &lru[K, V]{capacity: capacity, entries: make(map[K]*list.Element), order: list.New()}
+}
+
+// get возвращает значение key и отмечает его использованным.
+func (c *lru[K, V]) get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*lruEntry[K, V]).value, true
+}
+
+// add сохраняет value для key и вытесняет значения сверх capacity.
+func (c *lru[K, V]) add(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*lruEntry[K, V]).value = value
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value})
+	for c.capacity > 0 && c.order.Len() > c.capacity {
+		oldest := c.order.Remove(c.order.Back()).(*lruEntry[K, V])
+		delete(c.entries, oldest.key)
+	}
+}
+
+func (c *lru[K, V]) remove(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.order.Remove(el)
+		delete(c.entries, key)
+	}
+}
+
+func (c *lru[K, V]) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
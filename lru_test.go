@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	cache := newLRU[string, int](2)
+	cache.add("a", 1)
+	cache.add("b", 2)
+
+	// использованное значение вытесняется последним
+	v, ok := cache.get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	cache.add("c", 3)
+	_, ok = cache.get("b")
+	assert.False(t, ok)
+
+	cache.add("a", 4)
+	v, _ = cache.get("a")
+	assert.Equal(t, 4, v)
+	assert.Equal(t, 2, cache.len())
+
+	cache.remove("a")
+	cache.remove("missing")
+	_, ok = cache.get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 1, cache.len())
+}
+
+func TestLRU_Unbounded(t *testing.T) {
+	cache := newLRU[int, string](0)
+	for i := 0; i < 100; i++ {
+		cache.add(i, "v")
+	}
+	assert.Equal(t, 100, cache.len())
+}
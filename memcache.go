@@ -0,0 +1,288 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bradfitz/gomemcache/memcache"
+)
+
+// MemcacheBackend - клиент gomemcache, например *memcache.Client.
+type MemcacheBackend interface {
+	Get(key string) (*memcache.Item, error)
+	GetMulti(keys []string) (map[string]*memcache.Item, error)
+	Set(item *memcache.Item) error
+	Add(item *memcache.Item) error
+	Replace(item *memcache.Item) error
+	CompareAndSwap(item *memcache.Item) error
+	Delete(key string) error
+	Increment(key string, delta uint64) (uint64, error)
+	Decrement(key string, delta uint64) (uint64, error)
+	Touch(key string, seconds int32) error
+}
+
+// MemcacheFallback определяет результат чтения, отклоненного Circuit Breaker
+// или завершившегося неуспешно.
+type MemcacheFallback int
+
+const (
+	// MemcacheFallbackError возвращает ошибку чтения.
+	MemcacheFallbackError MemcacheFallback = iota
+	// MemcacheFallbackMiss возвращает memcache.ErrCacheMiss, как для отсутствующего
+	// ключа, чтобы вызывающая сторона обратилась к источнику данных.
+	MemcacheFallbackMiss
+	// MemcacheFallbackStale возвращает последнее прочитанное или записанное значение
+	// ключа из локальной копии, а если его нет - memcache.ErrCacheMiss.
+	MemcacheFallbackStale
+)
+
+type MemcacheOption func(*MemcacheClient)
+
+// WithMemcacheFallback задает результат неуспешного чтения. По умолчанию MemcacheFallbackError.
+func WithMemcacheFallback(fallback MemcacheFallback) MemcacheOption {
+	return func(c *MemcacheClient) {
+		c.fallback = fallback
+	}
+}
+
+// WithMemcacheStaleCapacity задает число ключей локальной копии для
+// MemcacheFallbackStale. Вытесняются давно использованные ключи. По умолчанию 1024.
+func WithMemcacheStaleCapacity(capacity int) MemcacheOption {
+	return func(c *MemcacheClient) {
+		c.staleCapacity = capacity
+	}
+}
+
+// WithMemcacheFailurePolicy задает, какие ошибки считаются неуспешными запросами.
+// По умолчанию DefaultMemcacheFailurePolicy.
+func WithMemcacheFailurePolicy(isFailure func(err error) bool) MemcacheOption {
+	return func(c *MemcacheClient) {
+		c.isFailure = isFailure
+	}
+}
+
+// DefaultMemcacheFailurePolicy считает неуспешными ошибки соединения и сервера.
+// Промах, конфликт CAS, отказ записи и некорректный ключ означают, что сервер
+// доступен, и считаются успешными запросами.
+func DefaultMemcacheFailurePolicy(err error) bool {
+	switch {
+	case errors.Is(err, memcache.ErrCacheMiss), errors.Is(err, memcache.ErrCASConflict),
+		errors.Is(err, memcache.ErrNotStored), errors.Is(err, memcache.ErrMalformedKey):
+		return false
+	default:
+		return true
+	}
+}
+
+// MemcacheClient выполняет запросы к memcached через Circuit Breaker.
+// Отклоненные запросы не отправляются и завершаются ошибкой, оборачивающей
+// ErrOpenState или ErrTooManyRequests; для чтения результат определяет
+// WithMemcacheFallback.
+type MemcacheClient struct {
+	client        MemcacheBackend
+	cb            *CircuitBreaker
+	fallback      MemcacheFallback
+	staleCapacity int
+	isFailure     func(err error) bool
+	stale         *lru[string, *memcache.Item]
+}
+
+func NewMemcacheClient(client MemcacheBackend, cb *CircuitBreaker, options ...MemcacheOption) *MemcacheClient {
+	c := &MemcacheClient{
+		client:        client,
+		cb:            cb,
+		staleCapacity: 1024,
+		isFailure:     DefaultMemcacheFailurePolicy,
+	}
+	for _, opt := range options {
+		opt(c)
+	}
+	c.stale = newLRU[string, *memcache.Item](c.staleCapacity)
+	return c
+}
+
+func (c *MemcacheClient) Get(key string) (*memcache.Item, error) {
+	var item *memcache.Item
+	failed, err := c.execute(func() (err error) {
+		item, err = c.client.Get(key)
+		return err
+	})
+	if err == nil {
+		c.remember(item)
+		return item, nil
+	}
+	if errors.Is(err, memcache.ErrCacheMiss) {
+		c.forget(key)
+	}
+	if !failed {
+		return nil, err
+	}
+
+	switch c.fallback {
+	case MemcacheFallbackMiss:
+		return nil, memcache.ErrCacheMiss
+	case MemcacheFallbackStale:
+		if item, ok := c.staleItem(key); ok {
+			return item, nil
+		}
+		return nil, memcache.ErrCacheMiss
+	default:
+		return nil, err
+	}
+}
+
+// GetMulti читает ключи keys. При неуспешном чтении с MemcacheFallbackMiss или
+// MemcacheFallbackStale возвращаются прочитанные значения, дополненные
+// значениями локальной копии для MemcacheFallbackStale, без ошибки.
+func (c *MemcacheClient) GetMulti(keys []string) (map[string]*memcache.Item, error) {
+	var items map[string]*memcache.Item
+	failed, err := c.execute(func() (err error) {
+		items, err = c.client.GetMulti(keys)
+		return err
+	})
+	for _, item := range items {
+		c.remember(item)
+	}
+	if err == nil {
+		for _, key := range keys {
+			if _, ok := items[key]; !ok {
+				c.forget(key)
+			}
+		}
+		return items, nil
+	}
+	if !failed || c.fallback == MemcacheFallbackError {
+		return items, err
+	}
+
+	if items == nil {
+		items = make(map[string]*memcache.Item)
+	}
+	if c.fallback == MemcacheFallbackStale {
+		for _, key := range keys {
+			if _, ok := items[key]; ok {
+				continue
+			}
+			if item, ok := c.staleItem(key); ok {
+				items[key] = item
+			}
+		}
+	}
+	return items, nil
+}
+
+func (c *MemcacheClient) Set(item *memcache.Item) error {
+	return c.store(item, c.client.Set)
+}
+
+func (c *MemcacheClient) Add(item *memcache.Item) error {
+	return c.store(item, c.client.Add)
+}
+
+func (c *MemcacheClient) Replace(item *memcache.Item) error {
+	return c.store(item, c.client.Replace)
+}
+
+func (c *MemcacheClient) CompareAndSwap(item *memcache.Item) error {
+	return c.store(item, c.client.CompareAndSwap)
+}
+
+func (c *MemcacheClient) Delete(key string) error {
+	c.forget(key)
+	_, err := c.execute(func() error {
+		return c.client.Delete(key)
+	})
+	return err
+}
+
+func (c *MemcacheClient) Increment(key string, delta uint64) (uint64, error) {
+	c.forget(key)
+	var value uint64
+	_, err := c.execute(func() (err error) {
+		value, err = c.client.Increment(key, delta)
+		return err
+	})
+	return value, err
+}
+
+func (c *MemcacheClient) Decrement(key string, delta uint64) (uint64, error) {
+	c.forget(key)
+	var value uint64
+	_, err := c.execute(func() (err error) {
+		value, err = c.client.Decrement(key, delta)
+		return err
+	})
+	return value, err
+}
+
+func (c *MemcacheClient) Touch(key string, seconds int32) error {
+	_, err := c.execute(func() error {
+		return c.client.Touch(key, seconds)
+	})
+	return err
+}
+
+// store выполняет запись item. Локальная копия ключа обновляется только после
+// успешной записи, иначе удаляется, чтобы не возвращать значение, которое
+// могло быть перезаписано.
+func (c *MemcacheClient) store(item *memcache.Item, write func(item *memcache.Item) error) error {
+	_, err := c.execute(func() error {
+		return write(item)
+	})
+	if err == nil {
+		c.remember(item)
+	} else {
+		c.forget(item.Key)
+	}
+	return err
+}
+
+// execute выполняет call через Circuit Breaker и сообщает, был ли запрос
+// отклонен или учтен как неуспешный.
+func (c *MemcacheClient) execute(call func() error) (failed bool, err error) {
+	var called bool
+	var callErr error
+	_, err = c.cb.Execute(func() (interface{}, error) {
+		called = true
+		callErr = call()
+		if callErr != nil && c.isFailure(callErr) {
+			return nil, callErr
+		}
+		return nil, nil
+	})
+	if called {
+		return err != nil, callErr
+	}
+	if isRejection(err) {
+		err = fmt.Errorf("circuit breaker %s: %w", c.cb.Path(), err)
+	}
+	return true, err
+}
+
+// remember сохраняет копию item для MemcacheFallbackStale.
+func (c *MemcacheClient) remember(item *memcache.Item) {
+	if c.fallback != MemcacheFallbackStale || item == nil {
+		return
+	}
+	stale := *item
+	stale.Value = append([]byte(nil), item.Value...)
+
+	c.stale.add(item.Key, &stale)
+}
+
+func (c *MemcacheClient) forget(key string) {
+	if c.fallback != MemcacheFallbackStale {
+		return
+	}
+	c.stale.remove(key)
+}
+
+func (c *MemcacheClient) staleItem(key string) (*memcache.Item, bool) {
+	stale, ok := c.stale.get(key)
+	if !ok {
+		return nil, false
+	}
+	item := *stale
+	item.Value = append([]byte(nil), item.Value...)
+	return &item, true
+}
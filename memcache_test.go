@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errMemcacheDown = errors.New("dial tcp 127.0.0.1:11211: connection refused")
+
+// testMemcache - memcached в памяти. Пока down, все запросы завершаются errMemcacheDown.
+type testMemcache struct {
+	items map[string]*memcache.Item
+	down  bool
+	calls int
+}
+
+func newTestMemcache() *testMemcache {
+	return &testMemcache{items: make(map[string]*memcache.Item)}
+}
+
+func (m *testMemcache) call() error {
+	m.calls++
+	if m.down {
+		return errMemcacheDown
+	}
+	return nil
+}
+
+func (m *testMemcache) Get(key string) (*memcache.Item, error) {
+	if err := m.call(); err != nil {
+		return nil, err
+	}
+	item, ok := m.items[key]
+	if !ok {
+		return nil, memcache.ErrCacheMiss
+	}
+	return item, nil
+}
+
+func (m *testMemcache) GetMulti(keys []string) (map[string]*memcache.Item, error) {
+	if err := m.call(); err != nil {
+		return nil, err
+	}
+	items := make(map[string]*memcache.Item)
+	for _, key := range keys {
+		if item, ok := m.items[key]; ok {
+			items[key] = item
+		}
+	}
+	return items, nil
+}
+
+func (m *testMemcache) Set(item *memcache.Item) error {
+	if err := m.call(); err != nil {
+		return err
+	}
+	m.items[item.Key] = item
+	return nil
+}
+
+func (m *testMemcache) Add(item *memcache.Item) error {
+	if _, ok := m.items[item.Key]; ok {
+		return memcache.ErrNotStored
+	}
+	return m.Set(item)
+}
+
+func (m *testMemcache) Replace(item *memcache.Item) error {
+	if _, ok := m.items[item.Key]; !ok {
+		return memcache.ErrNotStored
+	}
+	return m.Set(item)
+}
+
+func (m *testMemcache) CompareAndSwap(item *memcache.Item) error {
+	return m.Set(item)
+}
+
+func (m *testMemcache) Delete(key string) error {
+	if err := m.call(); err != nil {
+		return err
+	}
+	delete(m.items, key)
+	return nil
+}
+
+func (m *testMemcache) Increment(string, uint64) (uint64, error) {
+	return 0, m.call()
+}
+
+func (m *testMemcache) Decrement(string, uint64) (uint64, error) {
+	return 0, m.call()
+}
+
+func (m *testMemcache) Touch(string, int32) error {
+	return m.call()
+}
+
+func TestMemcacheClient(t *testing.T) {
+	backend := newTestMemcache()
+	cb := NewCircuitBreaker(WithName("memcache"), WithReadyToTrip(func(counts Counts) bool {
+		return counts.ConsecutiveFailures >= 1
+	}))
+	client := NewMemcacheClient(backend, cb)
+
+	require.NoError(t, client.Set(&memcache.Item{Key: "key", Value: []byte("value")}))
+	assert.ErrorIs(t, client.Add(&memcache.Item{Key: "key", Value: []byte("other")}), memcache.ErrNotStored)
+	_, err := client.Get("missing")
+	assert.ErrorIs(t, err, memcache.ErrCacheMiss)
+	assert.Equal(t, uint32(3), cb.Counts().TotalSuccess)
+
+	backend.down = true
+	_, err = client.Get("key")
+	assert.ErrorIs(t, err, errMemcacheDown)
+	assert.Equal(t, StateOpen, cb.State())
+
+	_, err = client.Get("key")
+	assert.ErrorIs(t, err, ErrOpenState)
+	assert.EqualError(t, err, "circuit breaker memcache: "+ErrOpenState.Error())
+	assert.ErrorIs(t, client.Delete("key"), ErrOpenState)
+	assert.Equal(t, 3, backend.calls)
+}
+
+func TestMemcacheClient_FallbackMiss(t *testing.T) {
+	backend := newTestMemcache()
+	client := NewMemcacheClient(backend, NewCircuitBreaker(), WithMemcacheFallback(MemcacheFallbackMiss))
+	require.NoError(t, client.Set(&memcache.Item{Key: "key", Value: []byte("value")}))
+
+	backend.down = true
+	_, err := client.Get("key")
+	assert.ErrorIs(t, err, memcache.ErrCacheMiss)
+	items, err := client.GetMulti([]string{"key"})
+	require.NoError(t, err)
+	assert.Empty(t, items)
+	assert.ErrorIs(t, client.Set(&memcache.Item{Key: "key"}), errMemcacheDown)
+}
+
+func TestMemcacheClient_FallbackStale(t *testing.T) {
+	backend := newTestMemcache()
+	cb := NewCircuitBreaker(WithReadyToTrip(func(counts Counts) bool {
+		return counts.ConsecutiveFailures >= 1
+	}))
+	client := NewMemcacheClient(backend, cb, WithMemcacheFallback(MemcacheFallbackStale), WithMemcacheStaleCapacity(2))
+
+	require.NoError(t, client.Set(&memcache.Item{Key: "a", Value: []byte("1")}))
+	backend.items["b"] = &memcache.Item{Key: "b", Value: []byte("2")}
+	backend.items["c"] = &memcache.Item{Key: "c", Value: []byte("3")}
+	_, err := client.GetMulti([]string{"c"})
+	require.NoError(t, err)
+	require.NoError(t, client.Set(&memcache.Item{Key: "d", Value: []byte("4")}))
+	require.NoError(t, client.Delete("d"))
+
+	backend.down = true
+	item, err := client.Get("c")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("3"), item.Value)
+	assert.Equal(t, StateOpen, cb.State())
+
+	item.Value[0] = 'x'
+	items, err := client.GetMulti([]string{"a", "b", "c", "d"})
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, []byte("3"), items["c"].Value)
+
+	_, err = client.Get("a")
+	assert.ErrorIs(t, err, memcache.ErrCacheMiss)
+}
+
+func TestDefaultMemcacheFailurePolicy(t *testing.T) {
+	assert.True(t, DefaultMemcacheFailurePolicy(errMemcacheDown))
+	assert.True(t, DefaultMemcacheFailurePolicy(memcache.ErrServerError))
+	assert.False(t, DefaultMemcacheFailurePolicy(memcache.ErrCacheMiss))
+	assert.False(t, DefaultMemcacheFailurePolicy(memcache.ErrCASConflict))
+	assert.False(t, DefaultMemcacheFailurePolicy(memcache.ErrNotStored))
+}
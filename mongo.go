@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoOption func(*mongoMonitor)
+
+// WithMongoFailurePolicy задает, какие ошибки команд считаются неуспешными
+// запросами. failure - текст ошибки из event.CommandFailedEvent.
+// По умолчанию DefaultMongoFailurePolicy.
+func WithMongoFailurePolicy(isFailure func(failure string) bool) MongoOption {
+	return func(m *mongoMonitor) {
+		m.isFailure = isFailure
+	}
+}
+
+// WithMongoCommandMonitor задает monitor, которому передаются события команд
+// после учета, так как клиент Mongo поддерживает только один monitor.
+func WithMongoCommandMonitor(next *event.CommandMonitor) MongoOption {
+	return func(m *mongoMonitor) {
+		m.next = next
+	}
+}
+
+// mongoUnavailable - имена ошибок сервера, означающие недоступность узла
+// или смену его роли в наборе реплик.
+var mongoUnavailable = []string{
+	"(NotWritablePrimary)", "(NotPrimaryNoSecondaryOk)", "(NotPrimaryOrSecondary)",
+	"(InterruptedAtShutdown)", "(InterruptedDueToReplStateChange)", "(ShutdownInProgress)",
+	"(PrimarySteppedDown)", "(HostUnreachable)", "(HostNotFound)", "(NetworkTimeout)",
+	"(SocketException)", "(ExceededTimeLimit)",
+}
+
+// DefaultMongoFailurePolicy считает неуспешными сетевые ошибки, истечение
+// времени ожидания и ошибки сервера, означающие недоступность узла.
+// Остальные ошибки команд, например DuplicateKey, и отмена контекста
+// вызывающей стороной считаются успешными запросами.
+func DefaultMongoFailurePolicy(failure string) bool {
+	if strings.Contains(failure, context.Canceled.Error()) {
+		return false
+	}
+	if strings.Contains(failure, "connection(") || strings.Contains(failure, context.DeadlineExceeded.Error()) {
+		return true
+	}
+	for _, name := range mongoUnavailable {
+		if strings.Contains(failure, name) {
+			return true
+		}
+	}
+	return false
+}
+
+type mongoFailFastKey struct{}
+
+// MongoFailFast возвращает контекст операции Mongo, которая не отправляется,
+// если Circuit Breaker выбранного для нее узла отказывает в выполнении:
+// операция завершается ошибкой context.Canceled, а context.Cause возвращает
+// ошибку, оборачивающую ErrOpenState или ErrTooManyRequests.
+// Без MongoFailFast команды отправляются независимо от состояния Circuit Breaker.
+// Контекст отменяется при первом отказе, поэтому создается для каждой операции.
+func MongoFailFast(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	ctx = context.WithValue(ctx, mongoFailFastKey{}, cancel)
+	return ctx, func() { cancel(nil) }
+}
+
+type mongoMonitor struct {
+	group     *Group
+	isFailure func(failure string) bool
+	next      *event.CommandMonitor
+
+	mu      sync.Mutex
+	pending map[int64]func(err error)
+}
+
+// MongoCommandMonitor возвращает event.CommandMonitor, учитывающий результаты
+// команд в Circuit Breaker группы по адресу узла, например "mongo-1:27017".
+// Команды, отправленные с контекстом MongoFailFast, отклоняются до отправки.
+func MongoCommandMonitor(group *Group, options ...MongoOption) *event.CommandMonitor {
+	m := &mongoMonitor{group: group, isFailure: DefaultMongoFailurePolicy, pending: make(map[int64]func(err error))}
+	for _, opt := range options {
+		opt(m)
+	}
+	return &event.CommandMonitor{
+		Started:   m.started,
+		Succeeded: m.succeeded,
+		Failed:    m.failed,
+	}
+}
+
+// MongoClientOptions возвращает настройки клиента с MongoCommandMonitor,
+// которые передаются в mongo.Connect после остальных:
+//
+//	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri), MongoClientOptions(group))
+func MongoClientOptions(group *Group, opts ...MongoOption) *options.ClientOptions {
+	return options.Client().SetMonitor(MongoCommandMonitor(group, opts...))
+}
+
+// mongoNode возвращает адрес узла по идентификатору соединения вида "host:port[-n]".
+func mongoNode(connectionID string) string {
+	if i := strings.LastIndex(connectionID, "[-"); i >= 0 {
+		return connectionID[:i]
+	}
+	return connectionID
+}
+
+func (m *mongoMonitor) started(ctx context.Context, evt *event.CommandStartedEvent) {
+	cb := m.group.Get(mongoNode(evt.ConnectionID))
+	done, err := cb.allow()
+	if err != nil {
+		if cancel, ok := ctx.Value(mongoFailFastKey{}).(context.CancelCauseFunc); ok {
+			cancel(fmt.Errorf("circuit breaker %s: %w", cb.Path(), err))
+		}
+	} else {
+		m.mu.Lock()
+		m.pending[evt.RequestID] = done
+		m.mu.Unlock()
+	}
+
+	if m.next != nil && m.next.Started != nil {
+		m.next.Started(ctx, evt)
+	}
+}
+
+func (m *mongoMonitor) succeeded(ctx context.Context, evt *event.CommandSucceededEvent) {
+	if done, ok := m.finish(evt.RequestID); ok {
+		done(nil)
+	}
+	if m.next != nil && m.next.Succeeded != nil {
+		m.next.Succeeded(ctx, evt)
+	}
+}
+
+func (m *mongoMonitor) failed(ctx context.Context, evt *event.CommandFailedEvent) {
+	if done, ok := m.finish(evt.RequestID); ok {
+		if m.isFailure(evt.Failure) {
+			done(fmt.Errorf("mongo %s: %s", evt.CommandName, evt.Failure))
+		} else {
+			done(nil)
+		}
+	}
+	if m.next != nil && m.next.Failed != nil {
+		m.next.Failed(ctx, evt)
+	}
+}
+
+func (m *mongoMonitor) finish(requestID int64) (func(err error), bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	done, ok := m.pending[requestID]
+	delete(m.pending, requestID)
+	return done, ok
+}
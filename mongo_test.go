@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// runMongoCommand передает monitor события команды requestID на соединении
+// connectionID: успешной, если failure пусто, иначе завершившейся ошибкой failure.
+func runMongoCommand(ctx context.Context, monitor *event.CommandMonitor, requestID int64, connectionID, failure string) {
+	monitor.Started(ctx, &event.CommandStartedEvent{CommandName: "find", RequestID: requestID, ConnectionID: connectionID})
+	finished := event.CommandFinishedEvent{CommandName: "find", RequestID: requestID, ConnectionID: connectionID}
+	if failure == "" {
+		monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: finished})
+	} else {
+		monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: finished, Failure: failure})
+	}
+}
+
+func TestMongoCommandMonitor(t *testing.T) {
+	group := tripOnFirstFailure()
+	var started, failed int
+	monitor := MongoCommandMonitor(group, WithMongoCommandMonitor(&event.CommandMonitor{
+		Started: func(context.Context, *event.CommandStartedEvent) { started++ },
+		Failed:  func(context.Context, *event.CommandFailedEvent) { failed++ },
+	}))
+	ctx := context.Background()
+
+	runMongoCommand(ctx, monitor, 1, "mongo-1:27017[-1]", "")
+	runMongoCommand(ctx, monitor, 2, "mongo-1:27017[-2]", "(DuplicateKey) E11000 duplicate key error")
+	primary := group.Get("mongo-1:27017")
+	assert.Equal(t, uint32(2), primary.Counts().TotalSuccess)
+
+	runMongoCommand(ctx, monitor, 3, "mongo-1:27017[-1]", "connection(mongo-1:27017[-1]) incomplete read of message header: EOF")
+	assert.Equal(t, StateOpen, primary.State())
+
+	runMongoCommand(ctx, monitor, 4, "mongo-2:27017[-1]", "")
+	assert.Equal(t, StateClosed, group.Get("mongo-2:27017").State())
+	assert.Equal(t, 4, started)
+	assert.Equal(t, 2, failed)
+}
+
+func TestMongoFailFast(t *testing.T) {
+	group := tripOnFirstFailure()
+	monitor := MongoCommandMonitor(group)
+	runMongoCommand(context.Background(), monitor, 1, "mongo-1:27017[-1]", "(NotWritablePrimary) not primary")
+	cb := group.Get("mongo-1:27017")
+	require.Equal(t, StateOpen, cb.State())
+
+	runMongoCommand(context.Background(), monitor, 2, "mongo-1:27017[-1]", "")
+
+	ctx, cancel := MongoFailFast(context.Background())
+	defer cancel()
+	runMongoCommand(ctx, monitor, 3, "mongo-1:27017[-1]", "context canceled")
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	assert.ErrorIs(t, context.Cause(ctx), ErrOpenState)
+	assert.EqualError(t, context.Cause(ctx), "circuit breaker mongo-1:27017: "+ErrOpenState.Error())
+
+	other, cancelOther := MongoFailFast(context.Background())
+	runMongoCommand(other, monitor, 4, "mongo-2:27017[-1]", "")
+	assert.NoError(t, other.Err())
+	cancelOther()
+	assert.ErrorIs(t, context.Cause(other), context.Canceled)
+	assert.Equal(t, uint32(0), cb.Counts().Requests)
+}
+
+func TestMongoClientOptions(t *testing.T) {
+	opts := MongoClientOptions(tripOnFirstFailure())
+	require.NotNil(t, opts.Monitor)
+	assert.NotNil(t, opts.Monitor.Started)
+}
+
+func TestDefaultMongoFailurePolicy(t *testing.T) {
+	assert.True(t, DefaultMongoFailurePolicy("connection(mongo-1:27017[-1]) socket was unexpectedly closed: EOF"))
+	assert.True(t, DefaultMongoFailurePolicy("context deadline exceeded"))
+	assert.True(t, DefaultMongoFailurePolicy("(PrimarySteppedDown) primary stepped down"))
+	assert.False(t, DefaultMongoFailurePolicy("(DuplicateKey) E11000 duplicate key error"))
+	assert.False(t, DefaultMongoFailurePolicy("connection(mongo-1:27017[-1]) failed to write: context canceled"))
+}
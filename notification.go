@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// StateChange описывает переход Circuit Breaker между состояниями.
+type StateChange struct {
+	// Путь Circuit Breaker в иерархии, см. Path.
+	Name string
+	// Метки Circuit Breaker, см. WithLabels. Не должны изменяться получателем.
+	Labels map[string]string
+	From   State
+	To     State
+	At     time.Time
+	Reason TransitionReason
+	// Счетчики прошлого состояния на момент перехода.
+	Counts Counts
+	// Ошибка запроса, вызвавшего переход, если переход вызван запросом.
+	Err error
+}
+
+// defaultNotificationQueueSize - размер очереди уведомлений по умолчанию.
+const defaultNotificationQueueSize = 64
+
+// WithOnStateChange задает обработчик смены состояния. Обработчик вызывается
+// асинхронно, поэтому медленный обработчик не задерживает Execute.
+func WithOnStateChange(onStateChange func(change StateChange)) Option {
+	return func(s *settings) {
+		s.onStateChange = onStateChange
+	}
+}
+
+// WithNotificationLimits ограничивает очередь уведомлений о смене состояния
+// размером queueSize и задает минимальный интервал между вызовами обработчика.
+// При queueSize <= 0 используется размер по умолчанию, 64. При переполнении
+// очереди новый переход объединяется с последним в очереди, а счетчик
+// DroppedNotifications увеличивается.
+func WithNotificationLimits(queueSize int, minInterval time.Duration) Option {
+	if queueSize <= 0 {
+		queueSize = defaultNotificationQueueSize
+	}
+	return func(s *settings) {
+		s.notificationQueueSize = queueSize
+		s.notificationInterval = minInterval
+	}
+}
+
+// notifier доставляет уведомления о смене состояния через ограниченную очередь.
+// Горутина доставки запускается, когда в очереди появляются уведомления,
+// и завершается, когда очередь пуста.
+type notifier struct {
+	mu    sync.Mutex
+	queue []StateChange
+	// Закрывается при завершении горутины доставки. nil, если горутина не запущена.
+	done    chan struct{}
+	dropped atomic.Uint64
+}
+
+// DroppedNotifications возвращает кол-во уведомлений, объединенных
+// с другими из-за переполнения очереди.
+func (cb *CircuitBreaker) DroppedNotifications() uint64 {
+	return cb.notifier.dropped.Load()
+}
+
+// notifyStateChange ставит уведомление в очередь, не блокируя вызывающего.
+func (cb *CircuitBreaker) notifyStateChange(change StateChange) {
+	s := cb.config()
+	if s.onStateChange == nil {
+		return
+	}
+
+	n := &cb.notifier
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if len(n.queue) >= s.notificationQueueSize {
+		last := &n.queue[len(n.queue)-1]
+		last.To = change.To
+		last.At = change.At
+		n.dropped.Add(1)
+	} else {
+		n.queue = append(n.queue, change)
+	}
+
+	if n.done == nil {
+		n.done = make(chan struct{})
+		go cb.deliverNotifications()
+	}
+}
+
+func (cb *CircuitBreaker) deliverNotifications() {
+	n := &cb.notifier
+
+	for {
+		n.mu.Lock()
+		if len(n.queue) == 0 {
+			close(n.done)
+			n.done = nil
+			n.mu.Unlock()
+			return
+		}
+		change := n.queue[0]
+		n.queue = n.queue[1:]
+		n.mu.Unlock()
+
+		s := cb.config()
+		if s.onStateChange != nil {
+			s.onStateChange(change)
+		}
+		if s.notificationInterval > 0 {
+			cb.pauseNotifications(s.notificationInterval)
+		}
+	}
+}
+
+// pauseNotifications выдерживает интервал между уведомлениями по часам
+// Circuit Breaker. После Close оставшиеся уведомления доставляются без пауз.
+func (cb *CircuitBreaker) pauseNotifications(interval time.Duration) {
+	timer := cb.clock().NewTimer(interval)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+	case <-cb.lifetime.Done():
+	}
+}
+
+// waitNotified дожидается доставки всех уведомлений из очереди.
+func (cb *CircuitBreaker) waitNotified(ctx context.Context) error {
+	n := &cb.notifier
+	n.mu.Lock()
+	done := n.done
+	n.mu.Unlock()
+
+	if done == nil {
+		return nil
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+)
+
+type stateChangeRecorder struct {
+	mu      sync.Mutex
+	changes []StateChange
+	release chan struct{}
+}
+
+func (r *stateChangeRecorder) record(change StateChange) {
+	if r.release != nil {
+		<-r.release
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.changes = append(r.changes, change)
+}
+
+func (r *stateChangeRecorder) transitions() [][2]State {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var transitions [][2]State
+	for _, change := range r.changes {
+		transitions = append(transitions, [2]State{change.From, change.To})
+	}
+	return transitions
+}
+
+func TestCircuitBreaker_OnStateChange(t *testing.T) {
+	recorder := &stateChangeRecorder{}
+	cb := NewCircuitBreaker(WithTimeout(time.Hour), WithOnStateChange(recorder.record))
+
+	cb.trip()
+	cb.expire(cb.current.Load().generation)
+
+	assert.Eventually(t, func() bool {
+		return len(recorder.transitions()) == 2
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, [][2]State{
+		{StateClosed, StateOpen},
+		{StateOpen, StateHalfOpen},
+	}, recorder.transitions())
+}
+
+func TestCircuitBreaker_SlowOnStateChange(t *testing.T) {
+	recorder := &stateChangeRecorder{release: make(chan struct{})}
+	cb := NewCircuitBreaker(
+		WithTimeout(time.Hour),
+		WithOnStateChange(recorder.record),
+		WithNotificationLimits(1, 0),
+	)
+
+	// обработчик заблокирован, но смена состояний не ждет его
+	cb.trip()
+	assert.Eventually(t, func() bool {
+		cb.notifier.mu.Lock()
+		defer cb.notifier.mu.Unlock()
+		return len(cb.notifier.queue) == 0
+	}, time.Second, time.Millisecond)
+
+	cb.expire(cb.current.Load().generation)
+	cb.trip()
+	cb.expire(cb.current.Load().generation)
+
+	// Open -> HalfOpen -> Open -> HalfOpen объединены в одно уведомление
+	assert.Equal(t, uint64(2), cb.DroppedNotifications())
+
+	close(recorder.release)
+	assert.Eventually(t, func() bool {
+		return len(recorder.transitions()) == 2
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, [][2]State{
+		{StateClosed, StateOpen},
+		{StateOpen, StateHalfOpen},
+	}, recorder.transitions())
+}
+
+func TestCircuitBreaker_NotificationQueueDefault(t *testing.T) {
+	recorder := &stateChangeRecorder{release: make(chan struct{})}
+	cb := NewCircuitBreaker(WithTimeout(time.Hour), WithOnStateChange(recorder.record))
+
+	// обработчик заблокирован на первом уведомлении, очередь ограничена по умолчанию
+	cb.trip()
+	assert.Eventually(t, func() bool {
+		cb.notifier.mu.Lock()
+		defer cb.notifier.mu.Unlock()
+		return len(cb.notifier.queue) == 0
+	}, time.Second, time.Millisecond)
+	for i := 0; i < defaultNotificationQueueSize; i++ {
+		cb.expire(cb.current.Load().generation)
+		cb.trip()
+	}
+	cb.notifier.mu.Lock()
+	assert.Len(t, cb.notifier.queue, defaultNotificationQueueSize)
+	cb.notifier.mu.Unlock()
+	assert.Equal(t, uint64(defaultNotificationQueueSize), cb.DroppedNotifications())
+
+	close(recorder.release)
+	assert.NoError(t, cb.Close(context.Background()))
+	assert.Len(t, recorder.transitions(), defaultNotificationQueueSize+1)
+}
+
+func TestCircuitBreaker_NotificationInterval(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	recorder := &stateChangeRecorder{}
+	cb := NewCircuitBreaker(
+		WithClock(clock),
+		WithTimeout(time.Hour),
+		WithOnStateChange(recorder.record),
+		WithNotificationLimits(0, time.Minute),
+	)
+
+	cb.trip()
+	cb.expire(cb.current.Load().generation)
+	assert.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+	assert.Len(t, recorder.transitions(), 1)
+
+	clock.Advance(time.Minute)
+	assert.Eventually(t, func() bool {
+		return len(recorder.transitions()) == 2
+	}, time.Second, time.Millisecond)
+
+	// после Close оставшиеся уведомления доставляются без пауз
+	assert.NoError(t, cb.Close(context.Background()))
+}
@@ -0,0 +1,46 @@
+package main
+
+// observer получает события Circuit Breaker для экспорта метрик.
+// Методы вызываются синхронно, поэтому должны быть быстрыми.
+type observer interface {
+	observeCall(cb *CircuitBreaker, record OutcomeRecord)
+	observeTransition(cb *CircuitBreaker, change StateChange)
+	observeRejection(cb *CircuitBreaker, err error)
+}
+
+// withObserver добавляет o к наблюдателям Circuit Breaker.
+func withObserver(o observer) Option {
+	return func(s *settings) {
+		s.observers = append(s.observers[:len(s.observers):len(s.observers)], o)
+	}
+}
+
+// reject учитывает отклоненный запрос, сообщает о нем логу, наблюдателям
+// и подписчикам и возвращает err.
+func (cb *CircuitBreaker) reject(err error) error {
+	cb.totals.rejections.Add(1)
+	s := cb.config()
+	cb.rejectionBuckets.add(s.timeProvider.Now())
+	if s.logger != nil {
+		cb.logRejection(err)
+	}
+	for _, o := range s.observers {
+		o.observeRejection(cb, err)
+	}
+	cb.publish(Event{Type: EventRejected, State: cb.State(), Err: err})
+	return err
+}
+
+// rejectionReason возвращает причину отклонения запроса для метрик и трассировки.
+func rejectionReason(err error) string {
+	switch err {
+	case ErrOpenState:
+		return "open"
+	case ErrTooManyRequests:
+		return "too_many_requests"
+	case ErrResourcePressure:
+		return "resource_pressure"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// openMetricsFamily - семейство метрик OpenMetrics с одним значением на Circuit Breaker,
+// кроме state, у которого по значению на каждое состояние.
+type openMetricsFamily struct {
+	name  string
+	typ   string
+	help  string
+	value func(cb *CircuitBreaker, stats Stats) float64
+}
+
+var openMetricsFamilies = []openMetricsFamily{
+	{"time_in_state_seconds", "gauge", "Time since the last state change.", func(cb *CircuitBreaker, _ Stats) float64 {
+		return cb.TimeInCurrentState().Seconds()
+	}},
+	{"window_requests", "gauge", "Requests in the current state.", func(_ *CircuitBreaker, stats Stats) float64 {
+		return float64(stats.Counts.Requests)
+	}},
+	{"window_successes", "gauge", "Successful requests in the current state.", func(_ *CircuitBreaker, stats Stats) float64 {
+		return float64(stats.Counts.TotalSuccess)
+	}},
+	{"window_failures", "gauge", "Failed requests in the current state.", func(_ *CircuitBreaker, stats Stats) float64 {
+		return float64(stats.Counts.TotalFailures)
+	}},
+	{"consecutive_failures", "gauge", "Consecutive failed requests.", func(_ *CircuitBreaker, stats Stats) float64 {
+		return float64(stats.Counts.ConsecutiveFailures)
+	}},
+	{"rejections", "counter", "Requests rejected by the breaker.", func(_ *CircuitBreaker, stats Stats) float64 {
+		return float64(stats.Rejections)
+	}},
+	{"successes", "counter", "Successful requests.", func(_ *CircuitBreaker, stats Stats) float64 {
+		return float64(stats.Successes)
+	}},
+	{"failures", "counter", "Failed requests.", func(_ *CircuitBreaker, stats Stats) float64 {
+		return float64(stats.Failures)
+	}},
+	{"trips", "counter", "Transitions from closed to open.", func(_ *CircuitBreaker, stats Stats) float64 {
+		return float64(stats.Transitions.Trips)
+	}},
+	{"reopens", "counter", "Transitions from half-open to open.", func(_ *CircuitBreaker, stats Stats) float64 {
+		return float64(stats.Transitions.Reopens)
+	}},
+	{"recoveries", "counter", "Transitions from half-open to closed.", func(_ *CircuitBreaker, stats Stats) float64 {
+		return float64(stats.Transitions.Recoveries)
+	}},
+}
+
+// OpenMetricsHandler возвращает http.Handler, отдающий метрики Circuit Breaker реестра
+// в текстовом формате OpenMetrics без зависимости от клиента Prometheus.
+// Метки и имена метрик совпадают с NewPrometheusCollector, кроме gauge
+// requests, successes и failures текущего окна: в OpenMetrics имена их семейств
+// совпали бы с counter successes и failures, поэтому они экспортируются
+// с префиксом window_, например circuit_breaker_window_failures.
+func OpenMetricsHandler(r *Registry, labelKeys ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", openMetricsContentType)
+		if req.Method == http.MethodHead {
+			return
+		}
+
+		bw := bufio.NewWriter(w)
+		writeOpenMetrics(bw, r, labelKeys)
+		_ = bw.Flush()
+	})
+}
+
+type openMetricsBreaker struct {
+	cb     *CircuitBreaker
+	labels string
+	stats  Stats
+}
+
+func writeOpenMetrics(w *bufio.Writer, r *Registry, labelKeys []string) {
+	var breakers []openMetricsBreaker
+	r.Range(func(name string, cb *CircuitBreaker) bool {
+		var labels strings.Builder
+		writeOpenMetricsLabel(&labels, "name", name)
+		values := cb.Labels()
+		for _, key := range labelKeys {
+			labels.WriteByte(',')
+			label := key
+			if key == "name" || key == "state" {
+				label = prometheusLabelPrefix + key
+			}
+			writeOpenMetricsLabel(&labels, label, values[key])
+		}
+		breakers = append(breakers, openMetricsBreaker{cb: cb, labels: labels.String(), stats: cb.Stats()})
+		return true
+	})
+
+	writeOpenMetricsHeader(w, "state", "gauge", "Current state: 1 for the active state, 0 otherwise.")
+	for _, b := range breakers {
+		for _, state := range []State{StateClosed, StateOpen, StateHalfOpen} {
+			var v float64
+			if state == b.stats.State {
+				v = 1
+			}
+			var labels strings.Builder
+			labels.WriteString(b.labels)
+			labels.WriteByte(',')
+			writeOpenMetricsLabel(&labels, "state", state.String())
+			writeOpenMetricsSample(w, "circuit_breaker_state", labels.String(), v)
+		}
+	}
+
+	for _, family := range openMetricsFamilies {
+		writeOpenMetricsHeader(w, family.name, family.typ, family.help)
+		name := "circuit_breaker_" + family.name
+		if family.typ == "counter" {
+			name += "_total"
+		}
+		for _, b := range breakers {
+			writeOpenMetricsSample(w, name, b.labels, family.value(b.cb, b.stats))
+		}
+	}
+	w.WriteString("# EOF\n")
+}
+
+func writeOpenMetricsHeader(w *bufio.Writer, name, typ, help string) {
+	w.WriteString("# TYPE circuit_breaker_" + name + " " + typ + "\n")
+	w.WriteString("# HELP circuit_breaker_" + name + " " + help + "\n")
+}
+
+func writeOpenMetricsSample(w *bufio.Writer, name, labels string, v float64) {
+	w.WriteString(name + "{" + labels + "} " + strconv.FormatFloat(v, 'g', -1, 64) + "\n")
+}
+
+var openMetricsEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func writeOpenMetricsLabel(b *strings.Builder, key, value string) {
+	b.WriteString(key + `="` + openMetricsEscaper.Replace(value) + `"`)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenMetricsHandler(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	r := NewRegistry(WithDefaults(WithClock(clock)))
+	payments := r.Get("payments", WithLabels(map[string]string{"team": `bil"ling`}))
+	search := r.Get("search")
+
+	assert.Nil(t, succeed(payments))
+	assert.NotNil(t, fail(payments))
+	search.trip()
+	assert.Equal(t, ErrOpenState, succeed(search))
+	clock.Advance(3 * time.Second)
+
+	rec := httptest.NewRecorder()
+	OpenMetricsHandler(r, "team").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, openMetricsContentType, rec.Header().Get("Content-Type"))
+	assert.Equal(t, `# TYPE circuit_breaker_state gauge
+# HELP circuit_breaker_state Current state: 1 for the active state, 0 otherwise.
+circuit_breaker_state{name="payments",team="bil\"ling",state="closed"} 1
+circuit_breaker_state{name="payments",team="bil\"ling",state="open"} 0
+circuit_breaker_state{name="payments",team="bil\"ling",state="half-open"} 0
+circuit_breaker_state{name="search",team="",state="closed"} 0
+circuit_breaker_state{name="search",team="",state="open"} 1
+circuit_breaker_state{name="search",team="",state="half-open"} 0
+# TYPE circuit_breaker_time_in_state_seconds gauge
+# HELP circuit_breaker_time_in_state_seconds Time since the last state change.
+circuit_breaker_time_in_state_seconds{name="payments",team="bil\"ling"} 3
+circuit_breaker_time_in_state_seconds{name="search",team=""} 3
+# TYPE circuit_breaker_window_requests gauge
+# HELP circuit_breaker_window_requests Requests in the current state.
+circuit_breaker_window_requests{name="payments",team="bil\"ling"} 2
+circuit_breaker_window_requests{name="search",team=""} 0
+# TYPE circuit_breaker_window_successes gauge
+# HELP circuit_breaker_window_successes Successful requests in the current state.
+circuit_breaker_window_successes{name="payments",team="bil\"ling"} 1
+circuit_breaker_window_successes{name="search",team=""} 0
+# TYPE circuit_breaker_window_failures gauge
+# HELP circuit_breaker_window_failures Failed requests in the current state.
+circuit_breaker_window_failures{name="payments",team="bil\"ling"} 1
+circuit_breaker_window_failures{name="search",team=""} 0
+# TYPE circuit_breaker_consecutive_failures gauge
+# HELP circuit_breaker_consecutive_failures Consecutive failed requests.
+circuit_breaker_consecutive_failures{name="payments",team="bil\"ling"} 1
+circuit_breaker_consecutive_failures{name="search",team=""} 0
+# TYPE circuit_breaker_rejections counter
+# HELP circuit_breaker_rejections Requests rejected by the breaker.
+circuit_breaker_rejections_total{name="payments",team="bil\"ling"} 0
+circuit_breaker_rejections_total{name="search",team=""} 1
+# TYPE circuit_breaker_successes counter
+# HELP circuit_breaker_successes Successful requests.
+circuit_breaker_successes_total{name="payments",team="bil\"ling"} 1
+circuit_breaker_successes_total{name="search",team=""} 0
+# TYPE circuit_breaker_failures counter
+# HELP circuit_breaker_failures Failed requests.
+circuit_breaker_failures_total{name="payments",team="bil\"ling"} 1
+circuit_breaker_failures_total{name="search",team=""} 0
+# TYPE circuit_breaker_trips counter
+# HELP circuit_breaker_trips Transitions from closed to open.
+circuit_breaker_trips_total{name="payments",team="bil\"ling"} 0
+circuit_breaker_trips_total{name="search",team=""} 1
+# TYPE circuit_breaker_reopens counter
+# HELP circuit_breaker_reopens Transitions from half-open to open.
+circuit_breaker_reopens_total{name="payments",team="bil\"ling"} 0
+circuit_breaker_reopens_total{name="search",team=""} 0
+# TYPE circuit_breaker_recoveries counter
+# HELP circuit_breaker_recoveries Transitions from half-open to closed.
+circuit_breaker_recoveries_total{name="payments",team="bil\"ling"} 0
+circuit_breaker_recoveries_total{name="search",team=""} 0
+# EOF
+`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	OpenMetricsHandler(r).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestOpenMetricsHandler_ReservedLabels(t *testing.T) {
+	r := NewRegistry()
+	r.Get("payments", WithLabels(map[string]string{"name": "billing-api"}))
+
+	rec := httptest.NewRecorder()
+	OpenMetricsHandler(r, "name").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, rec.Body.String(), "\ncircuit_breaker_failures_total{name=\"payments\",label_name=\"billing-api\"} 0\n")
+}
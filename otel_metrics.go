@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const instrumentationName = "github.com/raymanovg/circuit-breaker"
+
+// WithOTelMetrics включает экспорт метрик OpenTelemetry через provider:
+//   - circuit_breaker.call.duration - длительность выполненных запросов по результату;
+//   - circuit_breaker.transitions - переходы между состояниями;
+//   - circuit_breaker.rejections - отклоненные запросы по причине.
+//
+// Если provider равен nil, используется глобальный MeterProvider. Ошибки создания
+// инструментов передаются в otel.Handle.
+func WithOTelMetrics(provider metric.MeterProvider) Option {
+	if provider == nil {
+		provider = otel.GetMeterProvider()
+	}
+	meter := provider.Meter(instrumentationName)
+
+	var m otelMetrics
+	var err error
+	if m.duration, err = meter.Float64Histogram("circuit_breaker.call.duration",
+		metric.WithDescription("Duration of calls executed through the circuit breaker."),
+		metric.WithUnit("s"),
+	); err != nil {
+		otel.Handle(err)
+	}
+	if m.transitions, err = meter.Int64Counter("circuit_breaker.transitions",
+		metric.WithDescription("Circuit breaker state transitions."),
+		metric.WithUnit("{transition}"),
+	); err != nil {
+		otel.Handle(err)
+	}
+	if m.rejections, err = meter.Int64Counter("circuit_breaker.rejections",
+		metric.WithDescription("Requests rejected by the circuit breaker."),
+		metric.WithUnit("{request}"),
+	); err != nil {
+		otel.Handle(err)
+	}
+
+	return withObserver(&m)
+}
+
+type otelMetrics struct {
+	duration    metric.Float64Histogram
+	transitions metric.Int64Counter
+	rejections  metric.Int64Counter
+}
+
+func (m *otelMetrics) observeCall(cb *CircuitBreaker, record OutcomeRecord) {
+	m.duration.Record(context.Background(), record.Duration.Seconds(), metric.WithAttributes(
+		attribute.String("circuit_breaker.name", cb.Path()),
+		attribute.String("circuit_breaker.outcome", record.Outcome.String()),
+	))
+}
+
+func (m *otelMetrics) observeTransition(cb *CircuitBreaker, change StateChange) {
+	m.transitions.Add(context.Background(), 1, metric.WithAttributes(
+		attribute.String("circuit_breaker.name", change.Name),
+		attribute.String("circuit_breaker.state.from", change.From.String()),
+		attribute.String("circuit_breaker.state.to", change.To.String()),
+	))
+}
+
+func (m *otelMetrics) observeRejection(cb *CircuitBreaker, err error) {
+	m.rejections.Add(context.Background(), 1, metric.WithAttributes(
+		attribute.String("circuit_breaker.name", cb.Path()),
+		attribute.String("circuit_breaker.rejection.reason", rejectionReason(err)),
+	))
+}
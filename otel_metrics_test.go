@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestWithOTelMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	cb := NewCircuitBreaker(
+		WithName("payments"),
+		WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures > 0 }),
+		WithOTelMetrics(provider),
+	)
+	assert.Nil(t, succeed(cb))
+	assert.NotNil(t, fail(cb))
+	assert.Equal(t, ErrOpenState, succeed(cb))
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+
+	got := make(map[string]metricdata.Aggregation)
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		got[m.Name] = m.Data
+	}
+
+	duration := got["circuit_breaker.call.duration"].(metricdata.Histogram[float64])
+	counts := make(map[string]uint64)
+	for _, dp := range duration.DataPoints {
+		outcome, _ := dp.Attributes.Value("circuit_breaker.outcome")
+		counts[outcome.AsString()] = dp.Count
+	}
+	assert.Equal(t, map[string]uint64{"success": 1, "failure": 1}, counts)
+
+	transitions := got["circuit_breaker.transitions"].(metricdata.Sum[int64])
+	require.Len(t, transitions.DataPoints, 1)
+	assert.Equal(t, int64(1), transitions.DataPoints[0].Value)
+	assert.Equal(t, attribute.NewSet(
+		attribute.String("circuit_breaker.name", "payments"),
+		attribute.String("circuit_breaker.state.from", "closed"),
+		attribute.String("circuit_breaker.state.to", "open"),
+	), transitions.DataPoints[0].Attributes)
+
+	rejections := got["circuit_breaker.rejections"].(metricdata.Sum[int64])
+	require.Len(t, rejections.DataPoints, 1)
+	assert.Equal(t, int64(1), rejections.DataPoints[0].Value)
+	reason, _ := rejections.DataPoints[0].Attributes.Value("circuit_breaker.rejection.reason")
+	assert.Equal(t, "open", reason.AsString())
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WithTraceAnnotations включает запись решений Circuit Breaker в активный span
+// OpenTelemetry из контекста ExecuteContext: имя и состояние при допуске запроса,
+// признак пробного запроса в Half-Open и причину отклонения.
+func WithTraceAnnotations() Option {
+	return func(s *settings) {
+		s.traceAnnotations = true
+	}
+}
+
+func (cb *CircuitBreaker) annotateAdmission(ctx context.Context) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	state := cb.State()
+	span.SetAttributes(
+		attribute.String("circuit_breaker.name", cb.Path()),
+		attribute.String("circuit_breaker.state", state.String()),
+		attribute.Bool("circuit_breaker.probe", state == StateHalfOpen),
+	)
+}
+
+func (cb *CircuitBreaker) annotateRejection(ctx context.Context, err error) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	span.SetAttributes(
+		attribute.String("circuit_breaker.name", cb.Path()),
+		attribute.String("circuit_breaker.state", cb.State().String()),
+	)
+	span.AddEvent("circuit_breaker.rejected", trace.WithAttributes(
+		attribute.String("circuit_breaker.rejection.reason", rejectionReason(err)),
+	))
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestWithTraceAnnotations(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	cb := NewCircuitBreaker(WithName("payments"), WithTraceAnnotations())
+	execute := func() {
+		ctx, span := tracer.Start(context.Background(), "call")
+		defer span.End()
+		_, _ = cb.ExecuteContext(ctx, func(context.Context) (interface{}, error) {
+			return nil, nil
+		})
+	}
+
+	execute()
+	cb.trip()
+	execute()
+	cb.mu.Lock()
+	cb.setState(StateHalfOpen)
+	cb.mu.Unlock()
+	execute()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		attribute.String("circuit_breaker.name", "payments"),
+		attribute.String("circuit_breaker.state", "closed"),
+		attribute.Bool("circuit_breaker.probe", false),
+	}, spans[0].Attributes())
+
+	assert.ElementsMatch(t, []attribute.KeyValue{
+		attribute.String("circuit_breaker.name", "payments"),
+		attribute.String("circuit_breaker.state", "open"),
+	}, spans[1].Attributes())
+	require.Len(t, spans[1].Events(), 1)
+	assert.Equal(t, "circuit_breaker.rejected", spans[1].Events()[0].Name)
+	assert.Equal(t, []attribute.KeyValue{
+		attribute.String("circuit_breaker.rejection.reason", "open"),
+	}, spans[1].Events()[0].Attributes)
+
+	assert.Contains(t, spans[2].Attributes(), attribute.Bool("circuit_breaker.probe", true))
+}
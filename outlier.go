@@ -0,0 +1,177 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+type OutlierOption func(*OutlierDetector)
+
+// WithDeviationFactor задает, на сколько отклонений доля ошибок должна
+// превышать медиану по группе, чтобы участник считался выбросом.
+// Отклонение - это 1.4826·MAD (медианное абсолютное отклонение), а если MAD
+// равно нулю - 1.2533·среднее абсолютное отклонение. Оба множителя приводят
+// оценку к стандартному отклонению нормального распределения. Сам выброс на MAD
+// почти не влияет, поэтому выброс находится и в группе из трех участников.
+// По умолчанию 1.9: при нормальном распределении долей ошибок порог превышают
+// около 3% исправных участников.
+func WithDeviationFactor(factor float64) OutlierOption {
+	return func(d *OutlierDetector) {
+		d.deviationFactor = factor
+	}
+}
+
+// WithMinOutlierRequests задает минимальное кол-во запросов у участника,
+// чтобы его статистика учитывалась при поиске выбросов.
+func WithMinOutlierRequests(minRequests uint32) OutlierOption {
+	return func(d *OutlierDetector) {
+		d.minRequests = minRequests
+	}
+}
+
+// WithMaxEjectionPercent ограничивает долю участников группы (0..100),
+// которые могут быть одновременно переведены в состояние Open.
+func WithMaxEjectionPercent(percent float64) OutlierOption {
+	return func(d *OutlierDetector) {
+		d.maxEjectionPercent = percent
+	}
+}
+
+func NewOutlierDetector(options ...OutlierOption) *OutlierDetector {
+	d := &OutlierDetector{
+		breakers:           make(map[string]*CircuitBreaker),
+		deviationFactor:    1.9,
+		minRequests:        5,
+		maxEjectionPercent: 50,
+	}
+
+	for _, opt := range options {
+		opt(d)
+	}
+
+	return d
+}
+
+// OutlierDetector переводит в состояние Open только тех участников группы,
+// доля ошибок которых статистически хуже, чем у остальных.
+// При общей деградации всех участников никто из них не отключается.
+type OutlierDetector struct {
+	mu       sync.Mutex
+	breakers map[string]*CircuitBreaker
+	// Порог в отклонениях от медианы доли ошибок, см. WithDeviationFactor.
+	deviationFactor float64
+	// Минимальное кол-во запросов для участия в оценке.
+	minRequests uint32
+	// Максимальная доля участников в состоянии Open, в процентах.
+	maxEjectionPercent float64
+}
+
+func (d *OutlierDetector) Add(name string, cb *CircuitBreaker) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.breakers[name] = cb
+}
+
+func (d *OutlierDetector) Remove(name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.breakers, name)
+}
+
+// Detect оценивает текущую статистику участников группы, переводит выбросы
+// в состояние Open и возвращает их имена.
+func (d *OutlierDetector) Detect() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	type candidate struct {
+		name string
+		rate float64
+	}
+
+	var (
+		candidates []candidate
+		ejected    int
+	)
+	for name, cb := range d.breakers {
+		if cb.State() == StateOpen {
+			ejected++
+			continue
+		}
+		counts := cb.Counts()
+		if counts.Requests == 0 || counts.Requests < d.minRequests {
+			continue
+		}
+		candidates = append(candidates, candidate{
+			name: name,
+			rate: float64(counts.TotalFailures) / float64(counts.Requests),
+		})
+	}
+
+	// для статистики нужны хотя бы два участника
+	if len(candidates) < 2 {
+		return nil
+	}
+
+	rates := make([]float64, len(candidates))
+	for i, c := range candidates {
+		rates[i] = c.rate
+	}
+	center := median(rates)
+	threshold := center + d.deviationFactor*robustStdev(rates, center)
+
+	// сначала отключаются участники с наибольшей долей ошибок
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].rate > candidates[j].rate
+	})
+
+	maxEjected := int(float64(len(d.breakers)) * d.maxEjectionPercent / 100)
+
+	var outliers []string
+	for _, c := range candidates {
+		if c.rate <= threshold || ejected >= maxEjected {
+			break
+		}
+		d.breakers[c.name].tripFor(ReasonOutlier)
+		outliers = append(outliers, c.name)
+		ejected++
+	}
+
+	return outliers
+}
+
+// madScale приводит MAD к стандартному отклонению нормального распределения.
+const madScale = 1.4826
+
+// meanADScale приводит среднее абсолютное отклонение к стандартному
+// отклонению нормального распределения.
+const meanADScale = 1.2533
+
+// robustStdev оценивает стандартное отклонение values от center по MAD.
+// Если больше половины значений совпадают с center и MAD равно нулю,
+// используется среднее абсолютное отклонение.
+func robustStdev(values []float64, center float64) float64 {
+	deviations := make([]float64, len(values))
+	var sum float64
+	for i, v := range values {
+		deviations[i] = math.Abs(v - center)
+		sum += deviations[i]
+	}
+	if mad := median(deviations); mad > 0 {
+		return madScale * mad
+	}
+	return meanADScale * sum / float64(len(values))
+}
+
+// median возвращает медиану values, упорядочивая их.
+func median(values []float64) float64 {
+	sort.Float64s(values)
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutlierDetector_Detect(t *testing.T) {
+	d := NewOutlierDetector(WithDeviationFactor(1), WithMinOutlierRequests(10))
+
+	hosts := map[string]int{
+		"a": 1,
+		"b": 0,
+		"c": 1,
+		"d": 9, // заметно хуже остальных
+	}
+	for name, failures := range hosts {
+		cb := NewCircuitBreaker(WithReadyToTrip(func(Counts) bool { return false }))
+		for i := 0; i < 10; i++ {
+			if i < failures {
+				_ = fail(cb)
+			} else {
+				_ = succeed(cb)
+			}
+		}
+		d.Add(name, cb)
+	}
+
+	assert.Equal(t, []string{"d"}, d.Detect())
+	assert.Equal(t, StateOpen, d.breakers["d"].State())
+	assert.Equal(t, StateClosed, d.breakers["a"].State())
+}
+
+func TestOutlierDetector_ThreeHosts(t *testing.T) {
+	// при пороге по умолчанию выброс находится и среди трех участников
+	d := NewOutlierDetector(WithMinOutlierRequests(10))
+
+	for name, failures := range map[string]int{"a": 1, "b": 0, "c": 9} {
+		cb := NewCircuitBreaker(WithReadyToTrip(func(Counts) bool { return false }))
+		for i := 0; i < 10; i++ {
+			if i < failures {
+				_ = fail(cb)
+			} else {
+				_ = succeed(cb)
+			}
+		}
+		d.Add(name, cb)
+	}
+
+	assert.Equal(t, []string{"c"}, d.Detect())
+}
+
+func TestOutlierDetector_GlobalDegradation(t *testing.T) {
+	d := NewOutlierDetector(WithMinOutlierRequests(10))
+
+	// все участники деградировали одинаково - никто не отключается
+	for _, name := range []string{"a", "b", "c"} {
+		cb := NewCircuitBreaker(WithReadyToTrip(func(Counts) bool { return false }))
+		for i := 0; i < 10; i++ {
+			if i%2 == 0 {
+				_ = fail(cb)
+			} else {
+				_ = succeed(cb)
+			}
+		}
+		d.Add(name, cb)
+	}
+
+	assert.Empty(t, d.Detect())
+	for _, cb := range d.breakers {
+		assert.Equal(t, StateClosed, cb.State())
+	}
+}
+
+func TestOutlierDetector_MaxEjectionPercent(t *testing.T) {
+	d := NewOutlierDetector(WithDeviationFactor(0), WithMinOutlierRequests(1), WithMaxEjectionPercent(25))
+
+	for i, name := range []string{"a", "b", "c", "d"} {
+		cb := NewCircuitBreaker(WithReadyToTrip(func(Counts) bool { return false }))
+		for j := 0; j < 4; j++ {
+			if j < i {
+				_ = fail(cb)
+			} else {
+				_ = succeed(cb)
+			}
+		}
+		d.Add(name, cb)
+	}
+
+	// выше средней двое, но отключить можно только одного из четырех
+	assert.Equal(t, []string{"d"}, d.Detect())
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"context"
+	"runtime/pprof"
+)
+
+// WithPprofLabels помечает горутину на время Execute и ExecuteContext метками pprof
+// circuit_breaker (путь Circuit Breaker, см. Path) и circuit_breaker_state
+// (состояние на момент вызова), чтобы профили CPU и горутин относили время
+// к конкретным зависимостям. ExecuteContext добавляет метки к меткам ctx
+// и передает их запросу, Execute заменяет метки горутины на время вызова.
+func WithPprofLabels() Option {
+	return func(s *settings) {
+		s.pprofLabels = true
+	}
+}
+
+func (cb *CircuitBreaker) executeLabeled(ctx context.Context, fn func(ctx context.Context) (interface{}, error)) (response interface{}, err error) {
+	labels := pprof.Labels("circuit_breaker", cb.Path(), "circuit_breaker_state", cb.State().String())
+	pprof.Do(ctx, labels, func(ctx context.Context) {
+		response, err = fn(ctx)
+	})
+	return response, err
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"runtime/pprof"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithPprofLabels_ExecuteContext(t *testing.T) {
+	cb := NewCircuitBreaker(WithName("payments"), WithPprofLabels())
+	ctx := pprof.WithLabels(context.Background(), pprof.Labels("handler", "checkout"))
+
+	_, err := cb.ExecuteContext(ctx, func(ctx context.Context) (interface{}, error) {
+		for key, want := range map[string]string{"circuit_breaker": "payments", "circuit_breaker_state": "closed", "handler": "checkout"} {
+			value, ok := pprof.Label(ctx, key)
+			assert.True(t, ok, key)
+			assert.Equal(t, want, value)
+		}
+		return nil, nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestWithPprofLabels_Execute(t *testing.T) {
+	cb := NewCircuitBreaker(WithName("payments"), WithPprofLabels())
+
+	var profile strings.Builder
+	_, err := cb.Execute(func() (interface{}, error) {
+		return nil, pprof.Lookup("goroutine").WriteTo(&profile, 1)
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, profile.String(), `"circuit_breaker":"payments"`)
+	assert.Contains(t, profile.String(), `"circuit_breaker_state":"closed"`)
+
+	profile.Reset()
+	assert.NoError(t, pprof.Lookup("goroutine").WriteTo(&profile, 1))
+	assert.NotContains(t, profile.String(), `"circuit_breaker":"payments"`)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+type ProbeOption func(*probeHandler)
+
+// WithCriticalBreakers задает Circuit Breaker, от которых зависит готовность.
+// Не созданные в реестре Circuit Breaker не влияют на результат.
+func WithCriticalBreakers(names ...string) ProbeOption {
+	return func(h *probeHandler) {
+		for _, name := range names {
+			h.critical[name] = struct{}{}
+		}
+	}
+}
+
+// WithLivenessThreshold задает, сколько критичный Circuit Breaker может находиться
+// в состоянии Open, прежде чем LivenessHandler начнет возвращать 503.
+// По умолчанию liveness не зависит от состояния Circuit Breaker.
+func WithLivenessThreshold(threshold time.Duration) ProbeOption {
+	return func(h *probeHandler) {
+		h.livenessThreshold = threshold
+	}
+}
+
+// ReadinessHandler возвращает readiness probe для Kubernetes: 503, если хотя бы один
+// критичный Circuit Breaker в состоянии Open, иначе 200. См. WithCriticalBreakers.
+func ReadinessHandler(r *Registry, options ...ProbeOption) http.Handler {
+	h := newProbeHandler(r, options)
+	return h.handler(func(cb *CircuitBreaker) bool {
+		return cb.State() == StateOpen
+	})
+}
+
+// LivenessHandler возвращает liveness probe для Kubernetes: 503, если критичный
+// Circuit Breaker находится в состоянии Open дольше WithLivenessThreshold, иначе 200.
+func LivenessHandler(r *Registry, options ...ProbeOption) http.Handler {
+	h := newProbeHandler(r, options)
+	return h.handler(func(cb *CircuitBreaker) bool {
+		return h.livenessThreshold > 0 &&
+			cb.State() == StateOpen &&
+			cb.TimeInCurrentState() > h.livenessThreshold
+	})
+}
+
+type probeHandler struct {
+	registry          *Registry
+	critical          map[string]struct{}
+	livenessThreshold time.Duration
+}
+
+func newProbeHandler(r *Registry, options []ProbeOption) *probeHandler {
+	h := &probeHandler{registry: r, critical: make(map[string]struct{})}
+	for _, opt := range options {
+		opt(h)
+	}
+	return h
+}
+
+type probeJSON struct {
+	Status   string             `json:"status"`
+	Breakers []probeBreakerJSON `json:"breakers"`
+}
+
+type probeBreakerJSON struct {
+	Name     string `json:"name"`
+	State    string `json:"state"`
+	Critical bool   `json:"critical"`
+	Failing  bool   `json:"failing"`
+}
+
+func (h *probeHandler) handler(failing func(cb *CircuitBreaker) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		result := probeJSON{Status: "ok", Breakers: []probeBreakerJSON{}}
+		h.registry.Range(func(name string, cb *CircuitBreaker) bool {
+			_, critical := h.critical[name]
+			breaker := probeBreakerJSON{
+				Name:     name,
+				State:    cb.State().String(),
+				Critical: critical,
+				Failing:  critical && failing(cb),
+			}
+			if breaker.Failing {
+				result.Status = "unavailable"
+			}
+			result.Breakers = append(result.Breakers, breaker)
+			return true
+		})
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if result.Status != "ok" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		if req.Method == http.MethodGet {
+			_ = json.NewEncoder(w).Encode(result)
+		}
+	})
+}
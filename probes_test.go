@@ -0,0 +1,65 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func probe(h http.Handler, method string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, "/", nil))
+	return rec
+}
+
+func TestReadinessHandler(t *testing.T) {
+	r := NewRegistry()
+	r.Get("payments")
+	r.Get("search")
+	h := ReadinessHandler(r, WithCriticalBreakers("payments", "users"))
+
+	rec := probe(h, http.MethodGet)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"ok","breakers":[
+		{"name":"payments","state":"closed","critical":true,"failing":false},
+		{"name":"search","state":"closed","critical":false,"failing":false}
+	]}`, rec.Body.String())
+
+	r.Get("search").Trip()
+	assert.Equal(t, http.StatusOK, probe(h, http.MethodGet).Code)
+
+	r.Get("payments").Trip()
+	rec = probe(h, http.MethodGet)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.JSONEq(t, `{"status":"unavailable","breakers":[
+		{"name":"payments","state":"open","critical":true,"failing":true},
+		{"name":"search","state":"open","critical":false,"failing":false}
+	]}`, rec.Body.String())
+
+	rec = probe(h, http.MethodHead)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	assert.Equal(t, http.StatusMethodNotAllowed, probe(h, http.MethodPost).Code)
+}
+
+func TestLivenessHandler(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	r := NewRegistry(WithDefaults(WithTimeProvider(clock), WithTimeout(time.Hour)))
+	r.Get("payments").Trip()
+
+	assert.Equal(t, http.StatusOK, probe(LivenessHandler(r, WithCriticalBreakers("payments")), http.MethodGet).Code)
+
+	h := LivenessHandler(r, WithCriticalBreakers("payments"), WithLivenessThreshold(time.Minute))
+	assert.Equal(t, http.StatusOK, probe(h, http.MethodGet).Code)
+
+	clock.Advance(2 * time.Minute)
+	assert.Equal(t, http.StatusServiceUnavailable, probe(h, http.MethodGet).Code)
+
+	r.Get("payments").Reset()
+	assert.Equal(t, http.StatusOK, probe(h, http.MethodGet).Code)
+}
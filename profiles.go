@@ -0,0 +1,60 @@
+package main
+
+import "time"
+
+// combine объединяет несколько опций в одну.
+func combine(options ...Option) Option {
+	return func(s *settings) {
+		for _, opt := range options {
+			opt(s)
+		}
+	}
+}
+
+// ProfileAggressive быстро отключает проблемную зависимость: Open после 3 ошибок подряд,
+// короткий timeout и единственный пробный запрос в Half-Open.
+// Лучше всего защищает вызывающую сторону, но чаще срабатывает на случайные всплески ошибок.
+func ProfileAggressive() Option {
+	return combine(
+		WithReadyToTrip(func(counts Counts) bool {
+			return counts.ConsecutiveFailures >= 3
+		}),
+		WithTimeout(5*time.Second),
+		WithMaxOpenDuration(30*time.Second),
+		WithMaxRequests(1),
+	)
+}
+
+// ProfileConservative терпим к шуму: Open только после 20 ошибок подряд либо при доле
+// ошибок больше половины из не менее чем 50 запросов. Прогрев 30 секунд без перехода в Open
+// и сброс истории после 5 минут без ошибок. Реже дает ложные срабатывания,
+// но дольше пропускает запросы к действительно недоступной зависимости.
+func ProfileConservative() Option {
+	return combine(
+		WithReadyToTrip(func(counts Counts) bool {
+			if counts.ConsecutiveFailures >= 20 {
+				return true
+			}
+			return counts.Requests >= 50 && counts.TotalFailures*2 > counts.Requests
+		}),
+		WithTimeout(30*time.Second),
+		WithMaxRequests(5),
+		WithWarmup(30*time.Second, nil),
+		WithHealthyResetInterval(5*time.Minute),
+	)
+}
+
+// ProfileLatencySensitive рассчитан на пользовательские запросы, где долгое ожидание
+// хуже быстрого отказа: Open после 5 ошибок подряд, короткий timeout с верхней границей
+// и несколько пробных запросов в Half-Open для быстрого восстановления.
+// Ошибками считаются только ошибки запросов, медленные успешные запросы не учитываются.
+func ProfileLatencySensitive() Option {
+	return combine(
+		WithReadyToTrip(func(counts Counts) bool {
+			return counts.ConsecutiveFailures >= 5
+		}),
+		WithTimeout(2*time.Second),
+		WithMaxOpenDuration(10*time.Second),
+		WithMaxRequests(3),
+	)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfiles(t *testing.T) {
+	aggressive := NewCircuitBreaker(ProfileAggressive())
+	for i := 0; i < 3; i++ {
+		assert.NotNil(t, fail(aggressive))
+	}
+	assert.Equal(t, StateOpen, aggressive.State())
+	assert.Equal(t, uint32(1), aggressive.config().maxRequests)
+
+	conservative := NewCircuitBreaker(ProfileConservative())
+	assert.Equal(t, 30*time.Second, conservative.config().warmupPeriod)
+	assert.False(t, conservative.config().readyToTrip(Counts{Requests: 40, TotalFailures: 30, ConsecutiveFailures: 19}))
+	assert.True(t, conservative.config().readyToTrip(Counts{Requests: 50, TotalFailures: 26}))
+
+	// опции после профиля переопределяют его настройки
+	latency := NewCircuitBreaker(ProfileLatencySensitive(), WithTimeout(time.Second))
+	assert.Equal(t, time.Second, latency.config().timeout)
+	assert.Equal(t, 10*time.Second, latency.config().maxOpenDuration)
+}
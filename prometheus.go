@@ -0,0 +1,113 @@
+package main
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// prometheusLabelPrefix добавляется к ключам меток Circuit Breaker, совпадающим
+// с метками name и state коллектора.
+const prometheusLabelPrefix = "label_"
+
+// PrometheusCollector экспортирует метрики всех Circuit Breaker реестра.
+type PrometheusCollector struct {
+	registry  *Registry
+	labelKeys []string
+
+	state               *prometheus.Desc
+	timeInState         *prometheus.Desc
+	requests            *prometheus.Desc
+	successes           *prometheus.Desc
+	failures            *prometheus.Desc
+	consecutiveFailures *prometheus.Desc
+	rejectionsTotal     *prometheus.Desc
+	successesTotal      *prometheus.Desc
+	failuresTotal       *prometheus.Desc
+	tripsTotal          *prometheus.Desc
+	reopensTotal        *prometheus.Desc
+	recoveriesTotal     *prometheus.Desc
+}
+
+var _ prometheus.Collector = (*PrometheusCollector)(nil)
+
+// NewPrometheusCollector создает collector для реестра r. У каждой метрики есть метка
+// name с именем Circuit Breaker и по метке на каждый из labelKeys со значением
+// соответствующей метки Circuit Breaker (см. WithLabels). Метки для ключей
+// "name" и "state" экспортируются с префиксом "label_", например label_name.
+func NewPrometheusCollector(r *Registry, labelKeys ...string) *PrometheusCollector {
+	variable := []string{"name"}
+	for _, key := range labelKeys {
+		if key == "name" || key == "state" {
+			key = prometheusLabelPrefix + key
+		}
+		variable = append(variable, key)
+	}
+	desc := func(name, help string, extra ...string) *prometheus.Desc {
+		return prometheus.NewDesc("circuit_breaker_"+name, help, append(variable[:len(variable):len(variable)], extra...), nil)
+	}
+
+	return &PrometheusCollector{
+		registry:  r,
+		labelKeys: labelKeys,
+
+		state:               desc("state", "Current state: 1 for the active state, 0 otherwise.", "state"),
+		timeInState:         desc("time_in_state_seconds", "Time since the last state change."),
+		requests:            desc("requests", "Requests in the current state."),
+		successes:           desc("successes", "Successful requests in the current state."),
+		failures:            desc("failures", "Failed requests in the current state."),
+		consecutiveFailures: desc("consecutive_failures", "Consecutive failed requests."),
+		rejectionsTotal:     desc("rejections_total", "Requests rejected by the breaker."),
+		successesTotal:      desc("successes_total", "Successful requests."),
+		failuresTotal:       desc("failures_total", "Failed requests."),
+		tripsTotal:          desc("trips_total", "Transitions from closed to open."),
+		reopensTotal:        desc("reopens_total", "Transitions from half-open to open."),
+		recoveriesTotal:     desc("recoveries_total", "Transitions from half-open to closed."),
+	}
+}
+
+func (c *PrometheusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.state
+	ch <- c.timeInState
+	ch <- c.requests
+	ch <- c.successes
+	ch <- c.failures
+	ch <- c.consecutiveFailures
+	ch <- c.rejectionsTotal
+	ch <- c.successesTotal
+	ch <- c.failuresTotal
+	ch <- c.tripsTotal
+	ch <- c.reopensTotal
+	ch <- c.recoveriesTotal
+}
+
+func (c *PrometheusCollector) Collect(ch chan<- prometheus.Metric) {
+	c.registry.Range(func(name string, cb *CircuitBreaker) bool {
+		values := make([]string, 0, len(c.labelKeys)+1)
+		values = append(values, name)
+		labels := cb.Labels()
+		for _, key := range c.labelKeys {
+			values = append(values, labels[key])
+		}
+
+		stats := cb.Stats()
+		current := cb.current.Load()
+		for _, state := range []State{StateClosed, StateOpen, StateHalfOpen} {
+			var v float64
+			if state == current.state {
+				v = 1
+			}
+			ch <- prometheus.MustNewConstMetric(c.state, prometheus.GaugeValue, v, append(values, state.String())...)
+		}
+
+		timeInState := cb.config().timeProvider.Now().Sub(current.since).Seconds()
+		ch <- prometheus.MustNewConstMetric(c.timeInState, prometheus.GaugeValue, timeInState, values...)
+		ch <- prometheus.MustNewConstMetric(c.requests, prometheus.GaugeValue, float64(stats.Counts.Requests), values...)
+		ch <- prometheus.MustNewConstMetric(c.successes, prometheus.GaugeValue, float64(stats.Counts.TotalSuccess), values...)
+		ch <- prometheus.MustNewConstMetric(c.failures, prometheus.GaugeValue, float64(stats.Counts.TotalFailures), values...)
+		ch <- prometheus.MustNewConstMetric(c.consecutiveFailures, prometheus.GaugeValue, float64(stats.Counts.ConsecutiveFailures), values...)
+		ch <- prometheus.MustNewConstMetric(c.rejectionsTotal, prometheus.CounterValue, float64(stats.Rejections), values...)
+		ch <- prometheus.MustNewConstMetric(c.successesTotal, prometheus.CounterValue, float64(stats.Successes), values...)
+		ch <- prometheus.MustNewConstMetric(c.failuresTotal, prometheus.CounterValue, float64(stats.Failures), values...)
+		ch <- prometheus.MustNewConstMetric(c.tripsTotal, prometheus.CounterValue, float64(stats.Transitions.Trips), values...)
+		ch <- prometheus.MustNewConstMetric(c.reopensTotal, prometheus.CounterValue, float64(stats.Transitions.Reopens), values...)
+		ch <- prometheus.MustNewConstMetric(c.recoveriesTotal, prometheus.CounterValue, float64(stats.Transitions.Recoveries), values...)
+		return true
+	})
+}
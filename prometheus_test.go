@@ -0,0 +1,77 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/raymanovg/circuit-breaker/clocktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheusCollector(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	r := NewRegistry(WithDefaults(WithClock(clock)))
+	payments := r.Get("payments", WithLabels(map[string]string{"team": "billing"}))
+	search := r.Get("search")
+
+	assert.Nil(t, succeed(payments))
+	assert.NotNil(t, fail(payments))
+	search.trip()
+	assert.Equal(t, ErrOpenState, succeed(search))
+	clock.Advance(3 * time.Second)
+
+	collector := NewPrometheusCollector(r, "team")
+	assert.Equal(t, 28, testutil.CollectAndCount(collector))
+
+	err := testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP circuit_breaker_state Current state: 1 for the active state, 0 otherwise.
+# TYPE circuit_breaker_state gauge
+circuit_breaker_state{name="payments",state="closed",team="billing"} 1
+circuit_breaker_state{name="payments",state="half-open",team="billing"} 0
+circuit_breaker_state{name="payments",state="open",team="billing"} 0
+circuit_breaker_state{name="search",state="closed",team=""} 0
+circuit_breaker_state{name="search",state="half-open",team=""} 0
+circuit_breaker_state{name="search",state="open",team=""} 1
+# HELP circuit_breaker_requests Requests in the current state.
+# TYPE circuit_breaker_requests gauge
+circuit_breaker_requests{name="payments",team="billing"} 2
+circuit_breaker_requests{name="search",team=""} 0
+# HELP circuit_breaker_rejections_total Requests rejected by the breaker.
+# TYPE circuit_breaker_rejections_total counter
+circuit_breaker_rejections_total{name="payments",team="billing"} 0
+circuit_breaker_rejections_total{name="search",team=""} 1
+# HELP circuit_breaker_successes_total Successful requests.
+# TYPE circuit_breaker_successes_total counter
+circuit_breaker_successes_total{name="payments",team="billing"} 1
+circuit_breaker_successes_total{name="search",team=""} 0
+# HELP circuit_breaker_failures_total Failed requests.
+# TYPE circuit_breaker_failures_total counter
+circuit_breaker_failures_total{name="payments",team="billing"} 1
+circuit_breaker_failures_total{name="search",team=""} 0
+# HELP circuit_breaker_time_in_state_seconds Time since the last state change.
+# TYPE circuit_breaker_time_in_state_seconds gauge
+circuit_breaker_time_in_state_seconds{name="payments",team="billing"} 3
+circuit_breaker_time_in_state_seconds{name="search",team=""} 3
+# HELP circuit_breaker_trips_total Transitions from closed to open.
+# TYPE circuit_breaker_trips_total counter
+circuit_breaker_trips_total{name="payments",team="billing"} 0
+circuit_breaker_trips_total{name="search",team=""} 1
+`), "circuit_breaker_trips_total", "circuit_breaker_state", "circuit_breaker_requests", "circuit_breaker_rejections_total",
+		"circuit_breaker_successes_total", "circuit_breaker_failures_total", "circuit_breaker_time_in_state_seconds")
+	assert.NoError(t, err)
+}
+
+func TestPrometheusCollector_ReservedLabels(t *testing.T) {
+	r := NewRegistry()
+	r.Get("payments", WithLabels(map[string]string{"name": "billing-api", "state": "eu"}))
+
+	collector := NewPrometheusCollector(r, "name", "state")
+	err := testutil.CollectAndCompare(collector, strings.NewReader(`
+# HELP circuit_breaker_successes_total Successful requests.
+# TYPE circuit_breaker_successes_total counter
+circuit_breaker_successes_total{label_name="billing-api",label_state="eu",name="payments"} 0
+`), "circuit_breaker_successes_total")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,24 @@
+package main
+
+// ReportQueueDepth передает Circuit Breaker текущую глубину внутренней очереди
+// приложения. Если стратегия WithShedOnQueueDepth считает очередь переполненной,
+// Circuit Breaker переходит в Open, не дожидаясь ошибок от нижестоящего сервиса.
+func (cb *CircuitBreaker) ReportQueueDepth(depth int64) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.queueDepth = depth
+
+	shed := cb.config().shedOnQueueDepth
+	if cb.current.Load().state == StateClosed && shed != nil && shed(depth) {
+		cb.transition(StateOpen, ReasonQueueDepth, nil)
+	}
+}
+
+// QueueDepth возвращает последнюю глубину очереди, переданную через ReportQueueDepth.
+func (cb *CircuitBreaker) QueueDepth() int64 {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return cb.queueDepth
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker_ReportQueueDepth(t *testing.T) {
+	cb := NewCircuitBreaker(WithShedOnQueueDepth(func(depth int64) bool {
+		return depth > 100
+	}))
+
+	cb.ReportQueueDepth(50)
+	assert.Equal(t, int64(50), cb.QueueDepth())
+	assert.Equal(t, StateClosed, cb.State())
+
+	// очередь переполнена - переход в Open без ошибок нижестоящего сервиса
+	cb.ReportQueueDepth(150)
+	assert.Equal(t, StateOpen, cb.State())
+	assert.ErrorIs(t, succeed(cb), ErrOpenState)
+}
+
+func TestCircuitBreaker_ReportQueueDepthWithoutStrategy(t *testing.T) {
+	cb := NewCircuitBreaker()
+
+	cb.ReportQueueDepth(1000)
+	assert.Equal(t, int64(1000), cb.QueueDepth())
+	assert.Equal(t, StateClosed, cb.State())
+}
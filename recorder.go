@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// OutcomeRecord - результат одного запроса, выполненного через Circuit Breaker.
+// Отклоненные Circuit Breaker запросы не записываются.
+type OutcomeRecord struct {
+	At       time.Time     `json:"at"`
+	Duration time.Duration `json:"duration"`
+	Outcome  Outcome       `json:"outcome"`
+}
+
+// OutcomeRecorder получает результат каждого выполненного запроса.
+// Record вызывается синхронно из Execute, поэтому должен быть быстрым.
+type OutcomeRecorder interface {
+	Record(record OutcomeRecord)
+}
+
+func WithOutcomeRecorder(recorder OutcomeRecorder) Option {
+	return func(s *settings) {
+		s.outcomeRecorder = recorder
+	}
+}
+
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeSuccess:
+		return "success"
+	case OutcomeFailure:
+		return "failure"
+	default:
+		return fmt.Sprintf("Outcome(%d)", int(o))
+	}
+}
+
+func (o Outcome) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+func (o *Outcome) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "success":
+		*o = OutcomeSuccess
+	case "failure":
+		*o = OutcomeFailure
+	default:
+		return fmt.Errorf("unknown outcome %q", text)
+	}
+	return nil
+}
+
+// JSONLRecorder пишет результаты запросов в w по одному JSON-объекту на строку.
+type JSONLRecorder struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	err error
+}
+
+func NewJSONLRecorder(w io.Writer) *JSONLRecorder {
+	return &JSONLRecorder{enc: json.NewEncoder(w)}
+}
+
+func (r *JSONLRecorder) Record(record OutcomeRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err == nil {
+		r.err = r.enc.Encode(record)
+	}
+}
+
+// Err возвращает первую ошибку записи. После ошибки записи прекращаются.
+func (r *JSONLRecorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.err
+}
+
+// ReadOutcomes читает результаты запросов, записанные JSONLRecorder.
+func ReadOutcomes(r io.Reader) ([]OutcomeRecord, error) {
+	var records []OutcomeRecord
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record OutcomeRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+
+	return records, scanner.Err()
+}
+
+// Replay прогоняет записанный трафик через Circuit Breaker с опциями options,
+// позволяя ответить на вопрос "сработал бы Circuit Breaker с такой конфигурацией?".
+// Время отсчитывается от первой записи.
+func Replay(records []OutcomeRecord, options ...Option) SimulationResult {
+	steps := make([]SimulationStep, 0, len(records))
+	for _, record := range records {
+		steps = append(steps, SimulationStep{
+			At:      record.At.Sub(records[0].At),
+			Outcome: record.Outcome,
+			Latency: record.Duration,
+		})
+	}
+
+	return Simulate(steps, options...)
+}
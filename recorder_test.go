@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+)
+
+func TestOutcomeRecorder_RecordAndReplay(t *testing.T) {
+	clock := clocktest.New(time.Unix(1000, 0))
+	buf := &bytes.Buffer{}
+	recorder := NewJSONLRecorder(buf)
+
+	// текущая конфигурация не открывается при 4 ошибках подряд
+	cb := NewCircuitBreaker(WithTimeProvider(clock), WithOutcomeRecorder(recorder))
+	for i := 0; i < 4; i++ {
+		_, _ = cb.Execute(func() (interface{}, error) {
+			clock.Advance(100 * time.Millisecond)
+			return nil, errSimulatedFailure
+		})
+		clock.Advance(time.Second)
+	}
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateClosed, cb.State())
+	assert.NoError(t, recorder.Err())
+
+	records, err := ReadOutcomes(buf)
+	assert.NoError(t, err)
+	assert.Len(t, records, 5)
+	assert.True(t, records[0].At.Equal(time.Unix(1000, 0)))
+	assert.Equal(t, 100*time.Millisecond, records[0].Duration)
+	assert.Equal(t, OutcomeFailure, records[0].Outcome)
+	assert.Equal(t, OutcomeSuccess, records[4].Outcome)
+
+	// с более строгим порогом тот же трафик открыл бы Circuit Breaker
+	result := Replay(records, WithReadyToTrip(func(counts Counts) bool {
+		return counts.ConsecutiveFailures >= 3
+	}))
+	assert.Equal(t, []StateChange{
+		{From: StateClosed, To: StateOpen, At: SimulationStart.Add(2*1100*time.Millisecond + 100*time.Millisecond), Reason: ReasonTripStrategy,
+			Counts: Counts{Requests: 3, TotalFailures: 3, ConsecutiveFailures: 3}, Err: errSimulatedFailure},
+	}, result.Transitions)
+	assert.Equal(t, 2, result.Rejected)
+}
+
+func TestReadOutcomes_InvalidLine(t *testing.T) {
+	_, err := ReadOutcomes(bytes.NewBufferString(`{"outcome":"success"}` + "\n" + `{"outcome":"unknown"}`))
+	assert.ErrorContains(t, err, "line 2")
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type RedisOption func(*redisHook)
+
+// WithRedisFailurePolicy задает, какие ошибки команд считаются неуспешными
+// запросами. По умолчанию DefaultRedisFailurePolicy.
+func WithRedisFailurePolicy(isFailure func(err error) bool) RedisOption {
+	return func(h *redisHook) {
+		h.isFailure = isFailure
+	}
+}
+
+// DefaultRedisFailurePolicy считает неуспешными ошибки соединения и ответы
+// сервера, означающие его недоступность: LOADING, MASTERDOWN, CLUSTERDOWN и TRYAGAIN.
+// redis.Nil, остальные ответы сервера, например WRONGTYPE, и отмена контекста
+// вызывающей стороной считаются успешными запросами.
+func DefaultRedisFailurePolicy(err error) bool {
+	if errors.Is(err, redis.Nil) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var redisErr redis.Error
+	if errors.As(err, &redisErr) {
+		for _, prefix := range []string{"LOADING", "MASTERDOWN", "CLUSTERDOWN", "TRYAGAIN"} {
+			if redis.HasErrorPrefix(err, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+type redisHook struct {
+	cb        *CircuitBreaker
+	isFailure func(err error) bool
+}
+
+// NewRedisHook возвращает redis.Hook, выполняющий команды и конвейеры клиента
+// через cb, например rdb.AddHook(NewRedisHook(cb)). Конвейер учитывается как
+// один запрос, неуспешный, если неуспешна хотя бы одна команда. Отклоненные
+// команды не отправляются и завершаются ошибкой, оборачивающей ErrOpenState
+// или ErrTooManyRequests.
+func NewRedisHook(cb *CircuitBreaker, options ...RedisOption) redis.Hook {
+	h := &redisHook{cb: cb, isFailure: DefaultRedisFailurePolicy}
+	for _, opt := range options {
+		opt(h)
+	}
+	return h
+}
+
+// AddRedisClusterHooks добавляет NewRedisHook в клиент каждого узла кластера c
+// с Circuit Breaker группы по адресу узла, чтобы недоступность одного узла
+// не отклоняла команды остальных. Вызывается до первой команды клиента:
+// хуки добавляются только в новые клиенты узлов.
+func AddRedisClusterHooks(c *redis.ClusterClient, group *Group, options ...RedisOption) {
+	c.OnNewNode(func(node *redis.Client) {
+		node.AddHook(NewRedisHook(group.Get(node.Options().Addr), options...))
+	})
+}
+
+func (h *redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h *redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		return h.execute(ctx, []redis.Cmder{cmd}, func(ctx context.Context) error {
+			return next(ctx, cmd)
+		})
+	}
+}
+
+func (h *redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		return h.execute(ctx, cmds, func(ctx context.Context) error {
+			return next(ctx, cmds)
+		})
+	}
+}
+
+// execute выполняет call через Circuit Breaker. Если команды cmds не
+// отправлены, ошибка отказа записывается в каждую из них.
+func (h *redisHook) execute(ctx context.Context, cmds []redis.Cmder, call func(ctx context.Context) error) error {
+	var called bool
+	var callErr error
+	_, err := h.cb.ExecuteContext(ctx, func(ctx context.Context) (interface{}, error) {
+		called = true
+		callErr = call(ctx)
+		for _, cmd := range cmds {
+			if cmdErr := cmd.Err(); cmdErr != nil && h.isFailure(cmdErr) {
+				return nil, cmdErr
+			}
+		}
+		if callErr != nil && h.isFailure(callErr) {
+			return nil, callErr
+		}
+		return nil, nil
+	})
+	if called {
+		return callErr
+	}
+
+	if isRejection(err) {
+		err = fmt.Errorf("circuit breaker %s: %w", h.cb.Path(), err)
+	}
+	for _, cmd := range cmds {
+		cmd.SetErr(err)
+	}
+	return err
+}
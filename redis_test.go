@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tripOnFirstRedisFailure() *CircuitBreaker {
+	return NewCircuitBreaker(WithName("redis"), WithReadyToTrip(func(counts Counts) bool {
+		return counts.ConsecutiveFailures >= 1
+	}))
+}
+
+func TestRedisHook(t *testing.T) {
+	server := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = rdb.Close() })
+	cb := tripOnFirstRedisFailure()
+	rdb.AddHook(NewRedisHook(cb))
+	ctx := context.Background()
+
+	require.NoError(t, rdb.Set(ctx, "key", "value", 0).Err())
+	_, err := rdb.Get(ctx, "missing").Result()
+	assert.ErrorIs(t, err, redis.Nil)
+	assert.True(t, redis.HasErrorPrefix(rdb.LPush(ctx, "key", "item").Err(), "WRONGTYPE"))
+	assert.Equal(t, uint32(3), cb.Counts().TotalSuccess)
+
+	server.SetError("LOADING Redis is loading the dataset in memory")
+	require.Error(t, rdb.Get(ctx, "key").Err())
+	assert.Equal(t, StateOpen, cb.State())
+
+	server.SetError("")
+	_, err = rdb.Get(ctx, "key").Result()
+	assert.ErrorIs(t, err, ErrOpenState)
+	assert.EqualError(t, err, "circuit breaker redis: "+ErrOpenState.Error())
+}
+
+func TestRedisHook_Pipeline(t *testing.T) {
+	server := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = rdb.Close() })
+	cb := tripOnFirstRedisFailure()
+	rdb.AddHook(NewRedisHook(cb))
+	ctx := context.Background()
+
+	_, err := rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, "key", "value", 0)
+		pipe.Get(ctx, "missing")
+		return nil
+	})
+	assert.ErrorIs(t, err, redis.Nil)
+	assert.Equal(t, uint32(1), cb.Counts().TotalSuccess)
+
+	server.Close()
+	_, err = rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Get(ctx, "key")
+		return nil
+	})
+	require.Error(t, err)
+	assert.Equal(t, StateOpen, cb.State())
+
+	cmds, err := rdb.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Get(ctx, "key")
+		pipe.Incr(ctx, "counter")
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrOpenState)
+	for _, cmd := range cmds {
+		assert.ErrorIs(t, cmd.Err(), ErrOpenState)
+	}
+}
+
+func TestAddRedisClusterHooks(t *testing.T) {
+	server := miniredis.RunT(t)
+	rdb := redis.NewClusterClient(&redis.ClusterOptions{Addrs: []string{server.Addr()}, MaxRedirects: -1, MaxRetries: -1})
+	t.Cleanup(func() { _ = rdb.Close() })
+	group := tripOnFirstFailure()
+	AddRedisClusterHooks(rdb, group)
+	ctx := context.Background()
+
+	require.NoError(t, rdb.Set(ctx, "key", "value", 0).Err())
+	cb := group.Get(server.Addr())
+	assert.Positive(t, cb.Counts().TotalSuccess)
+
+	server.SetError("CLUSTERDOWN The cluster is down")
+	require.Error(t, rdb.Get(ctx, "key").Err())
+	assert.Equal(t, StateOpen, cb.State())
+}
+
+func TestDefaultRedisFailurePolicy(t *testing.T) {
+	assert.True(t, DefaultRedisFailurePolicy(errors.New("dial tcp: connection refused")))
+	assert.True(t, DefaultRedisFailurePolicy(context.DeadlineExceeded))
+	assert.False(t, DefaultRedisFailurePolicy(redis.Nil))
+	assert.False(t, DefaultRedisFailurePolicy(context.Canceled))
+}
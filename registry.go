@@ -0,0 +1,337 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clock"
+)
+
+// ExpiryModel определяет, как Circuit Breaker реестра выходят из состояния Open.
+type ExpiryModel int
+
+const (
+	// ExpiryLazy - срок состояния Open проверяется при очередном вызове Execute.
+	ExpiryLazy ExpiryModel = iota
+	// ExpiryBackground - переходы в Half-Open выполняет фоновая горутина реестра
+	// через общее колесо таймеров, даже если запросов нет.
+	ExpiryBackground
+)
+
+func (m ExpiryModel) String() string {
+	switch m {
+	case ExpiryLazy:
+		return "lazy"
+	case ExpiryBackground:
+		return "background"
+	default:
+		return fmt.Sprintf("ExpiryModel(%d)", int(m))
+	}
+}
+
+func (m ExpiryModel) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *ExpiryModel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "lazy":
+		*m = ExpiryLazy
+	case "background":
+		*m = ExpiryBackground
+	default:
+		return fmt.Errorf("unknown expiry model %q", text)
+	}
+	return nil
+}
+
+type RegistryOption func(*Registry)
+
+func WithExpiryModel(model ExpiryModel) RegistryOption {
+	return func(r *Registry) {
+		r.expiryModel = model
+	}
+}
+
+// WithExpiryTick задает шаг колеса таймеров для ExpiryBackground.
+func WithExpiryTick(tick time.Duration) RegistryOption {
+	return func(r *Registry) {
+		r.expiryTick = tick
+	}
+}
+
+// WithRegistryClock задает часы колеса таймеров ExpiryBackground и Circuit
+// Breaker реестра, см. WithClock. По умолчанию системные часы.
+func WithRegistryClock(c Clock) RegistryOption {
+	return func(r *Registry) {
+		r.clock = c
+		r.defaults = append(r.defaults, WithClock(c))
+	}
+}
+
+// WithDefaults задает опции, применяемые к каждому Circuit Breaker реестра.
+func WithDefaults(options ...Option) RegistryOption {
+	return func(r *Registry) {
+		r.defaults = append(r.defaults, options...)
+	}
+}
+
+// WithOverrides задает опции Circuit Breaker с именем name.
+// Они применяются после WithDefaults и до опций, переданных в Get.
+func WithOverrides(name string, options ...Option) RegistryOption {
+	return func(r *Registry) {
+		r.overrides[name] = append(r.overrides[name], options...)
+	}
+}
+
+func NewRegistry(options ...RegistryOption) *Registry {
+	r := &Registry{
+		breakers:   make(map[string]*CircuitBreaker),
+		overrides:  make(map[string][]Option),
+		created:    make(map[string][]Option),
+		expiryTick: 10 * time.Millisecond,
+		clock:      clock.Real{},
+	}
+
+	for _, opt := range options {
+		opt(r)
+	}
+
+	if r.expiryModel == ExpiryBackground {
+		// колесо планирует переходы, только пока работает горутина Start
+		r.wheel = NewTimerWheel(r.expiryTick, r.clock)
+		r.wheel.setPaused(true)
+	}
+
+	return r
+}
+
+// Registry хранит именованные Circuit Breaker. Circuit Breaker остаются в реестре
+// до Remove: удаления по времени без обращений у реестра нет, оно есть у Group,
+// см. WithGroupIdleTTL и WithGroupRegistry.
+type Registry struct {
+	mu       sync.RWMutex
+	breakers map[string]*CircuitBreaker
+
+	// Опции для всех Circuit Breaker реестра и для отдельных имен.
+	defaults  []Option
+	overrides map[string][]Option
+	// Опции из файла конфигурации. Заменяются целиком при перезагрузке
+	// и применяются перед defaults и overrides соответственно.
+	configDefaults  []Option
+	configOverrides map[string][]Option
+	// Опции из переменных окружения по envName. Применяются последними.
+	envOverrides map[string][]Option
+	// Опции, переданные в Get при создании Circuit Breaker.
+	created map[string][]Option
+	// Загруженные через Restore состояния еще не созданных Circuit Breaker.
+	restored map[string]Snapshot
+
+	expiryModel ExpiryModel
+	expiryTick  time.Duration
+	clock       Clock
+	wheel       *TimerWheel
+
+	// Управление фоновой горутиной для ExpiryBackground.
+	lifecycle sync.Mutex
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// Get возвращает Circuit Breaker с именем name, создавая его, если его еще нет.
+// Новый Circuit Breaker получает опции WithDefaults, затем WithOverrides для name,
+// затем options, затем EnvOverrides. Для существующего Circuit Breaker options игнорируются.
+func (r *Registry) Get(name string, options ...Option) *CircuitBreaker {
+	r.mu.RLock()
+	cb, ok := r.breakers[name]
+	r.mu.RUnlock()
+	if ok {
+		return cb
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if cb, ok := r.breakers[name]; ok {
+		return cb
+	}
+
+	cb = NewCircuitBreaker(r.options(name, options)...)
+	if snapshot, ok := r.restored[name]; ok {
+		cb.Restore(snapshot)
+		delete(r.restored, name)
+	}
+	r.breakers[name] = cb
+	r.created[name] = options
+
+	return cb
+}
+
+// Lookup возвращает Circuit Breaker с именем name, не создавая его.
+func (r *Registry) Lookup(name string) (*CircuitBreaker, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	cb, ok := r.breakers[name]
+	return cb, ok
+}
+
+// Remove удаляет Circuit Breaker с именем name из реестра, его статистики
+// и метрик и сообщает, был ли он в реестре. Circuit Breaker не закрывается
+// и продолжает работать у тех, кто его уже получил.
+func (r *Registry) Remove(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.breakers[name]; !ok {
+		return false
+	}
+	delete(r.breakers, name)
+	delete(r.created, name)
+	return true
+}
+
+// unregister удаляет cb из реестра, если под именем name зарегистрирован именно он.
+func (r *Registry) unregister(name string, cb *CircuitBreaker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.breakers[name] == cb {
+		delete(r.breakers, name)
+		delete(r.created, name)
+	}
+}
+
+// Persist атомарно сохраняет состояние всех Circuit Breaker реестра в store.
+func (r *Registry) Persist(store BatchStateStore) error {
+	r.mu.RLock()
+	snapshots := make(map[string]Snapshot, len(r.breakers))
+	for name, cb := range r.breakers {
+		snapshots[name] = cb.Snapshot()
+	}
+	r.mu.RUnlock()
+
+	return store.SaveAll(snapshots)
+}
+
+// Restore загружает состояние всех Circuit Breaker из store одним запросом.
+// Существующие Circuit Breaker восстанавливаются сразу, остальные - при создании через Get.
+func (r *Registry) Restore(store BatchStateStore) error {
+	snapshots, err := store.LoadAll()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for name, snapshot := range snapshots {
+		if cb, ok := r.breakers[name]; ok {
+			cb.Restore(snapshot)
+			continue
+		}
+		if r.restored == nil {
+			r.restored = make(map[string]Snapshot)
+		}
+		r.restored[name] = snapshot
+	}
+
+	return nil
+}
+
+func (r *Registry) options(name string, options []Option) []Option {
+	all := make([]Option, 0, len(r.configDefaults)+len(r.defaults)+len(r.configOverrides[name])+len(r.overrides[name])+len(options)+len(r.envOverrides[envName(name)])+2)
+	all = append(all, WithName(name))
+	all = append(all, r.configDefaults...)
+	all = append(all, r.defaults...)
+	all = append(all, r.configOverrides[name]...)
+	all = append(all, r.overrides[name]...)
+	all = append(all, options...)
+	all = append(all, r.envOverrides[envName(name)]...)
+	if r.wheel != nil {
+		all = append(all, WithTimerWheel(r.wheel))
+	}
+	return all
+}
+
+// reconfigure заменяет опции из файла конфигурации и пересобирает по ним настройки
+// существующих Circuit Breaker. Реестр изменяется, только если все настройки корректны.
+func (r *Registry) reconfigure(defaults []Option, overrides map[string][]Option) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prevDefaults, prevOverrides := r.configDefaults, r.configOverrides
+	r.configDefaults, r.configOverrides = defaults, overrides
+
+	updated := make(map[*CircuitBreaker]*settings, len(r.breakers))
+	var errs []error
+	for name, cb := range r.breakers {
+		s, err := validSettings(r.options(name, r.created[name]))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("breaker %q: %w", name, err))
+			continue
+		}
+		updated[cb] = s
+	}
+
+	if len(errs) > 0 {
+		r.configDefaults, r.configOverrides = prevDefaults, prevOverrides
+		return errors.Join(errs...)
+	}
+
+	for cb, s := range updated {
+		cb.replaceConfig(s)
+	}
+	return nil
+}
+
+// Start запускает фоновую горутину для ExpiryBackground.
+// Для ExpiryLazy и при повторном вызове ничего не делает. До Start и после
+// Stop срок состояния Open проверяется при очередном вызове Execute.
+func (r *Registry) Start() {
+	r.lifecycle.Lock()
+	defer r.lifecycle.Unlock()
+
+	if r.wheel == nil || r.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+
+	r.wheel.setPaused(false)
+	go func() {
+		defer close(r.done)
+		r.wheel.Run(ctx)
+	}()
+
+	// переходы Circuit Breaker, открытых до Start, планируются сейчас
+	r.Range(func(_ string, cb *CircuitBreaker) bool {
+		if current := cb.current.Load(); current.state == StateOpen && cb.lifetime.Err() == nil {
+			r.wheel.schedule(cb, current.generation, current.expiry)
+		}
+		return true
+	})
+}
+
+// Stop останавливает фоновую горутину и дожидается ее завершения.
+// После Stop реестр продолжает работать с ленивой проверкой срока в Execute,
+// а колесо таймеров не удерживает Circuit Breaker.
+func (r *Registry) Stop() {
+	r.lifecycle.Lock()
+	defer r.lifecycle.Unlock()
+
+	if r.cancel == nil {
+		return
+	}
+
+	r.cancel()
+	<-r.done
+	r.wheel.setPaused(true)
+	r.cancel = nil
+	r.done = nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import "sort"
+
+// Filter отбирает Circuit Breaker реестра.
+type Filter func(name string, cb *CircuitBreaker) bool
+
+// InState отбирает Circuit Breaker, находящиеся в одном из состояний states.
+func InState(states ...State) Filter {
+	return func(_ string, cb *CircuitBreaker) bool {
+		state := cb.State()
+		for _, s := range states {
+			if s == state {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// HasLabel отбирает Circuit Breaker с меткой key, равной value.
+func HasLabel(key, value string) Filter {
+	return func(_ string, cb *CircuitBreaker) bool {
+		v, ok := cb.config().labels[key]
+		return ok && v == value
+	}
+}
+
+// Range вызывает fn для каждого Circuit Breaker реестра в порядке имен,
+// пока fn возвращает true. Реестр не блокируется на время вызовов fn,
+// поэтому fn может обращаться к реестру.
+func (r *Registry) Range(fn func(name string, cb *CircuitBreaker) bool) {
+	type entry struct {
+		name string
+		cb   *CircuitBreaker
+	}
+
+	r.mu.RLock()
+	entries := make([]entry, 0, len(r.breakers))
+	for name, cb := range r.breakers {
+		entries = append(entries, entry{name: name, cb: cb})
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].name < entries[j].name
+	})
+	for _, e := range entries {
+		if !fn(e.name, e.cb) {
+			return
+		}
+	}
+}
+
+// Filter возвращает Circuit Breaker реестра, удовлетворяющие всем filters, в порядке имен.
+func (r *Registry) Filter(filters ...Filter) []*CircuitBreaker {
+	var result []*CircuitBreaker
+	r.Range(func(name string, cb *CircuitBreaker) bool {
+		for _, filter := range filters {
+			if !filter(name, cb) {
+				return true
+			}
+		}
+		result = append(result, cb)
+		return true
+	})
+	return result
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Range(t *testing.T) {
+	r := NewRegistry()
+	r.Get("orders")
+	r.Get("payments")
+	r.Get("users")
+
+	var names []string
+	r.Range(func(name string, cb *CircuitBreaker) bool {
+		names = append(names, name)
+		return name != "payments"
+	})
+	assert.Equal(t, []string{"orders", "payments"}, names)
+}
+
+func TestRegistry_Filter(t *testing.T) {
+	r := NewRegistry(WithOverrides("payments", WithLabels(map[string]string{"team": "billing"})))
+	orders := r.Get("orders", WithLabels(map[string]string{"team": "billing"}))
+	payments := r.Get("payments")
+	users := r.Get("users")
+
+	payments.trip()
+	users.trip()
+
+	assert.Equal(t, []*CircuitBreaker{payments, users}, r.Filter(InState(StateOpen)))
+	assert.Equal(t, []*CircuitBreaker{orders, payments}, r.Filter(HasLabel("team", "billing")))
+	assert.Equal(t, []*CircuitBreaker{payments}, r.Filter(InState(StateOpen, StateHalfOpen), HasLabel("team", "billing")))
+	assert.Len(t, r.Filter(), 3)
+}
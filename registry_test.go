@@ -0,0 +1,141 @@
+package main
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+)
+
+func TestRegistry_Get(t *testing.T) {
+	r := NewRegistry()
+
+	cb := r.Get("payments", WithMaxRequests(1))
+	assert.Same(t, cb, r.Get("payments"))
+	assert.Equal(t, uint32(1), cb.config().maxRequests)
+	assert.NotSame(t, cb, r.Get("orders"))
+}
+
+func TestRegistry_Remove(t *testing.T) {
+	r := NewRegistry()
+	cb := r.Get("payments")
+
+	assert.True(t, r.Remove("payments"))
+	assert.False(t, r.Remove("payments"))
+	_, ok := r.Lookup("payments")
+	assert.False(t, ok)
+	assert.NotSame(t, cb, r.Get("payments"))
+}
+
+func TestRegistry_LazyExpiry(t *testing.T) {
+	r := NewRegistry()
+	r.Start()
+	defer r.Stop()
+
+	cb := r.Get("payments", WithTimeout(10*time.Millisecond))
+	cb.trip()
+
+	// без запросов Circuit Breaker остается в Open
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, StateOpen, cb.State())
+
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateHalfOpen, cb.State())
+}
+
+func TestRegistry_BackgroundExpiry(t *testing.T) {
+	goroutines := runtime.NumGoroutine()
+
+	r := NewRegistry(WithExpiryModel(ExpiryBackground), WithExpiryTick(time.Millisecond))
+	r.Start()
+	r.Start()
+
+	cb := r.Get("payments", WithTimeout(10*time.Millisecond))
+	cb.trip()
+
+	assert.Eventually(t, func() bool {
+		return cb.State() == StateHalfOpen
+	}, time.Second, time.Millisecond)
+
+	r.Stop()
+	r.Stop()
+	assert.LessOrEqual(t, runtime.NumGoroutine(), goroutines)
+}
+
+func TestRegistry_BackgroundExpiryClock(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	r := NewRegistry(WithExpiryModel(ExpiryBackground), WithExpiryTick(time.Second), WithRegistryClock(clock))
+	r.Start()
+	defer r.Stop()
+
+	cb := r.Get("payments", WithTimeout(10*time.Second))
+	cb.trip()
+	assert.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+
+	clock.Advance(5 * time.Second)
+	assert.Equal(t, StateOpen, cb.State())
+
+	// переход в Half-Open выполняет колесо без запросов к Circuit Breaker
+	clock.Advance(7 * time.Second)
+	assert.Eventually(t, func() bool {
+		return cb.State() == StateHalfOpen
+	}, time.Second, time.Millisecond)
+}
+
+// wheelEntries возвращает кол-во запланированных переходов колеса w.
+func wheelEntries(w *TimerWheel) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var n int
+	for _, level := range w.levels {
+		for _, slot := range level {
+			n += len(slot)
+		}
+	}
+	return n
+}
+
+func TestRegistry_BackgroundExpiryStopped(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	r := NewRegistry(WithExpiryModel(ExpiryBackground), WithExpiryTick(time.Second), WithRegistryClock(clock))
+
+	// без фоновой горутины переходы не планируются
+	cb := r.Get("payments", WithTimeout(10*time.Second))
+	cb.trip()
+	assert.Equal(t, 0, wheelEntries(r.wheel))
+
+	// Start планирует переходы уже открытых Circuit Breaker
+	r.Start()
+	assert.Equal(t, 1, wheelEntries(r.wheel))
+	assert.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+	clock.Advance(11 * time.Second)
+	assert.Eventually(t, func() bool {
+		return cb.State() == StateHalfOpen
+	}, time.Second, time.Millisecond)
+
+	cb.trip()
+	r.Stop()
+	assert.Equal(t, 0, wheelEntries(r.wheel))
+	cb.trip()
+	assert.Equal(t, 0, wheelEntries(r.wheel))
+}
+
+func TestRegistry_DefaultsAndOverrides(t *testing.T) {
+	r := NewRegistry(
+		WithDefaults(WithTimeout(time.Minute), WithMaxRequests(3)),
+		WithOverrides("payments", WithTimeout(time.Hour)),
+	)
+
+	orders := r.Get("orders")
+	assert.Equal(t, "orders", orders.Name())
+	assert.Equal(t, time.Minute, orders.config().timeout)
+	assert.Equal(t, uint32(3), orders.config().maxRequests)
+
+	payments := r.Get("payments", WithMaxRequests(1))
+	assert.Equal(t, time.Hour, payments.config().timeout)
+	assert.Equal(t, uint32(1), payments.config().maxRequests)
+}
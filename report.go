@@ -0,0 +1,132 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// rejectionBucketSize - точность подсчета отклоненных запросов в Report.
+	rejectionBucketSize = time.Hour
+	// rejectionBucketCount - кол-во хранимых интервалов, 8 суток.
+	rejectionBucketCount = 8 * 24
+)
+
+// Report - сводка работы Circuit Breaker за период, например для еженедельного
+// обзора надежности.
+type Report struct {
+	Name   string
+	Window time.Duration
+	// Доля (0..100) времени вне состояния Open.
+	Availability float64
+	// Кол-во переходов в Open.
+	Trips int
+	// Среднее время от выхода из Closed до возврата в Closed по восстановлениям за период.
+	// Ноль, если восстановлений не было.
+	MeanTimeToRecovery time.Duration
+	// Кол-во отклоненных запросов, с точностью до часа.
+	Rejected uint64
+}
+
+// Report возвращает сводку за последний window. Если Circuit Breaker создан позже
+// начала window, сводка строится за время жизни.
+func (cb *CircuitBreaker) Report(window time.Duration) Report {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := cb.config().timeProvider.Now()
+	start := now.Add(-window)
+	if start.Before(cb.createdAt) {
+		start = cb.createdAt
+	}
+
+	report := Report{Name: cb.Path(), Window: window, Availability: 100}
+	if now.After(start) {
+		open := cb.timeIn(StateOpen, start, now)
+		report.Availability = 100 - float64(open)/float64(now.Sub(start))*100
+	}
+
+	current := cb.current.Load()
+	spans := append(cb.stateTimes.spans[:len(cb.stateTimes.spans):len(cb.stateTimes.spans)],
+		stateSpan{state: current.state, from: current.since, to: now})
+	inWindow := func(t time.Time) bool { return !t.Before(start) && !t.After(now) }
+
+	var (
+		outageStart time.Time
+		inOutage    bool
+		recoveries  int
+		recovery    time.Duration
+	)
+	for _, span := range spans {
+		if span.state == StateOpen && inWindow(span.from) {
+			report.Trips++
+		}
+		switch {
+		case span.state != StateClosed && !inOutage:
+			inOutage, outageStart = true, span.from
+		case span.state == StateClosed && inOutage:
+			inOutage = false
+			if inWindow(span.from) {
+				recoveries++
+				recovery += span.from.Sub(outageStart)
+			}
+		}
+	}
+	if recoveries > 0 {
+		report.MeanTimeToRecovery = recovery / time.Duration(recoveries)
+	}
+
+	report.Rejected = cb.rejectionBuckets.sum(start, now)
+	return report
+}
+
+// Report возвращает сводки всех Circuit Breaker реестра в порядке имен.
+func (r *Registry) Report(window time.Duration) []Report {
+	var reports []Report
+	r.Range(func(_ string, cb *CircuitBreaker) bool {
+		reports = append(reports, cb.Report(window))
+		return true
+	})
+	return reports
+}
+
+// rejectionBuckets - кол-во отклоненных запросов по часам.
+type rejectionBuckets struct {
+	mu     sync.Mutex
+	starts [rejectionBucketCount]int64
+	counts [rejectionBucketCount]uint64
+}
+
+func bucketIndex(t time.Time) int64 {
+	return t.UnixNano() / int64(rejectionBucketSize)
+}
+
+func (b *rejectionBuckets) add(now time.Time) {
+	index := bucketIndex(now)
+	i := index % rejectionBucketCount
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.starts[i] != index {
+		b.starts[i] = index
+		b.counts[i] = 0
+	}
+	b.counts[i]++
+}
+
+// sum возвращает кол-во отклонений в интервалах, пересекающихся с [from, to].
+func (b *rejectionBuckets) sum(from, to time.Time) uint64 {
+	first, last := bucketIndex(from), bucketIndex(to)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var total uint64
+	for i, start := range b.starts {
+		if start >= first && start <= last {
+			total += b.counts[i]
+		}
+	}
+	return total
+}
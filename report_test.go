@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker_Report(t *testing.T) {
+	clock := clocktest.New(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	cb := NewCircuitBreaker(
+		WithName("payments"),
+		WithClock(clock),
+		WithTimeout(10*time.Minute),
+		WithMaxRequests(1),
+		WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures > 0 }),
+	)
+
+	// первое восстановление: Open 10m с повторным переходом из Half-Open, затем Open еще 10m
+	clock.Advance(time.Hour)
+	assert.NotNil(t, fail(cb))
+	assert.Equal(t, ErrOpenState, succeed(cb))
+	clock.Advance(11 * time.Minute)
+	assert.NotNil(t, fail(cb))
+	clock.Advance(11 * time.Minute)
+	assert.Nil(t, succeed(cb))
+
+	// второе восстановление: Open 10m
+	clock.Advance(time.Hour)
+	assert.NotNil(t, fail(cb))
+	clock.Advance(11 * time.Minute)
+	assert.Nil(t, succeed(cb))
+	clock.Advance(27 * time.Minute)
+
+	report := cb.Report(3 * time.Hour)
+	assert.Equal(t, "payments", report.Name)
+	assert.Equal(t, 3, report.Trips)
+	assert.Equal(t, 16*time.Minute+30*time.Second, report.MeanTimeToRecovery)
+	assert.Equal(t, uint64(1), report.Rejected)
+	assert.InDelta(t, 100-float64(33)/180*100, report.Availability, 0.001)
+
+	report = cb.Report(time.Hour)
+	assert.Equal(t, 1, report.Trips)
+	assert.Equal(t, 11*time.Minute, report.MeanTimeToRecovery)
+	assert.Equal(t, uint64(0), report.Rejected)
+	assert.InDelta(t, 100-float64(11)/60*100, report.Availability, 0.001)
+}
+
+func TestRegistry_Report(t *testing.T) {
+	r := NewRegistry()
+	r.Get("search")
+	r.Get("payments").trip()
+
+	reports := r.Report(time.Hour)
+	assert.Len(t, reports, 2)
+	assert.Equal(t, "payments", reports[0].Name)
+	assert.Equal(t, 1, reports[0].Trips)
+	assert.Equal(t, Report{Name: "search", Window: time.Hour, Availability: 100}, reports[1])
+}
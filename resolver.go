@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// HostResolver разрешает имена хостов, например *net.Resolver.
+type HostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+	LookupIP(ctx context.Context, network, host string) ([]net.IP, error)
+}
+
+type ResolverOption func(*Resolver)
+
+// WithResolverKey задает ключ Circuit Breaker для имени хоста, например
+// DNSZoneKey. По умолчанию DNSResolverKey.
+func WithResolverKey(key func(host string) string) ResolverOption {
+	return func(r *Resolver) {
+		r.key = key
+	}
+}
+
+// WithResolverFailurePolicy задает, какие ошибки разрешения считаются неуспешными
+// запросами. По умолчанию DefaultResolverFailurePolicy.
+func WithResolverFailurePolicy(isFailure func(err error) bool) ResolverOption {
+	return func(r *Resolver) {
+		r.isFailure = isFailure
+	}
+}
+
+// WithResolverStale включает возврат последнего успешного ответа для запросов,
+// отклоненных Circuit Breaker или завершившихся неуспешно. Хранится не больше
+// capacity ответов, вытесняются давно использованные. По умолчанию выключен.
+func WithResolverStale(capacity int) ResolverOption {
+	return func(r *Resolver) {
+		r.staleCapacity = capacity
+	}
+}
+
+// DNSResolverKey возвращает "dns", один Circuit Breaker на Resolver.
+func DNSResolverKey(string) string {
+	return "dns"
+}
+
+// DNSZoneKey возвращает зону из двух последних меток имени, например
+// "example.com" для "api.eu.example.com.", один Circuit Breaker на зону.
+func DNSZoneKey(host string) string {
+	labels := strings.Split(strings.ToLower(strings.TrimSuffix(host, ".")), ".")
+	if len(labels) > 2 {
+		labels = labels[len(labels)-2:]
+	}
+	return strings.Join(labels, ".")
+}
+
+// DefaultResolverFailurePolicy считает неуспешными истечение времени ожидания
+// и ошибки резолвера. Отсутствие имени означает, что резолвер ответил, и
+// считается успешным запросом, как и отмена контекста вызывающей стороной.
+func DefaultResolverFailurePolicy(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return false
+	}
+	return true
+}
+
+// Resolver выполняет разрешение имен через Circuit Breaker группы по ключу
+// имени, чтобы недоступность резолвера приводила к быстрому отказу, а не
+// к истечению времени ожидания каждого запроса. Отклоненные запросы не
+// отправляются и завершаются ошибкой, оборачивающей ErrOpenState или
+// ErrTooManyRequests, или возвращают ответ из WithResolverStale.
+type Resolver struct {
+	resolver      HostResolver
+	group         *Group
+	key           func(host string) string
+	isFailure     func(err error) bool
+	staleCapacity int
+	stale         *lru[string, interface{}]
+}
+
+// NewResolver возвращает Resolver поверх resolver или net.DefaultResolver, если resolver - nil.
+func NewResolver(resolver HostResolver, group *Group, options ...ResolverOption) *Resolver {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	r := &Resolver{
+		resolver:  resolver,
+		group:     group,
+		key:       DNSResolverKey,
+		isFailure: DefaultResolverFailurePolicy,
+	}
+	for _, opt := range options {
+		opt(r)
+	}
+	r.stale = newLRU[string, interface{}](r.staleCapacity)
+	return r
+}
+
+func (r *Resolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	answer, err := r.lookup(ctx, host, "host "+host, func(ctx context.Context) (interface{}, error) {
+		return r.resolver.LookupHost(ctx, host)
+	})
+	addrs, _ := answer.([]string)
+	return addrs, err
+}
+
+func (r *Resolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	answer, err := r.lookup(ctx, host, "ipaddr "+host, func(ctx context.Context) (interface{}, error) {
+		return r.resolver.LookupIPAddr(ctx, host)
+	})
+	addrs, _ := answer.([]net.IPAddr)
+	return addrs, err
+}
+
+func (r *Resolver) LookupIP(ctx context.Context, network, host string) ([]net.IP, error) {
+	answer, err := r.lookup(ctx, host, network+" "+host, func(ctx context.Context) (interface{}, error) {
+		return r.resolver.LookupIP(ctx, network, host)
+	})
+	ips, _ := answer.([]net.IP)
+	return ips, err
+}
+
+// lookup выполняет call через Circuit Breaker имени host. staleKey - ключ
+// ответа для WithResolverStale.
+func (r *Resolver) lookup(ctx context.Context, host, staleKey string, call func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	cb := r.group.Get(r.key(host))
+	var called bool
+	var answer interface{}
+	var lookupErr error
+	_, err := cb.ExecuteContext(ctx, func(ctx context.Context) (interface{}, error) {
+		called = true
+		answer, lookupErr = call(ctx)
+		if lookupErr != nil && r.isFailure(lookupErr) {
+			return nil, lookupErr
+		}
+		return nil, nil
+	})
+
+	switch {
+	case called && lookupErr == nil:
+		r.remember(staleKey, answer)
+		return answer, nil
+	case called && err == nil:
+		var dnsErr *net.DNSError
+		if errors.As(lookupErr, &dnsErr) && dnsErr.IsNotFound {
+			r.forget(staleKey)
+		}
+		return answer, lookupErr
+	case called:
+		err = lookupErr
+	case isRejection(err):
+		err = fmt.Errorf("circuit breaker %s: %w", cb.Path(), err)
+	default:
+		return nil, err
+	}
+
+	if stale, ok := r.staleAnswer(staleKey); ok {
+		return stale, nil
+	}
+	return answer, err
+}
+
+// remember сохраняет копию answer для WithResolverStale.
+func (r *Resolver) remember(key string, answer interface{}) {
+	if r.staleCapacity > 0 {
+		r.stale.add(key, copyResolverAnswer(answer))
+	}
+}
+
+func (r *Resolver) forget(key string) {
+	r.stale.remove(key)
+}
+
+func (r *Resolver) staleAnswer(key string) (interface{}, bool) {
+	answer, ok := r.stale.get(key)
+	if !ok {
+		return nil, false
+	}
+	return copyResolverAnswer(answer), true
+}
+
+// copyResolverAnswer возвращает копию ответа, чтобы вызывающая сторона не
+// изменила сохраненные адреса.
+func copyResolverAnswer(answer interface{}) interface{} {
+	switch answer := answer.(type) {
+	case []string:
+		return append([]string(nil), answer...)
+	case []net.IPAddr:
+		addrs := make([]net.IPAddr, len(answer))
+		for i, addr := range answer {
+			addrs[i] = net.IPAddr{IP: append(net.IP(nil), addr.IP...), Zone: addr.Zone}
+		}
+		return addrs
+	case []net.IP:
+		ips := make([]net.IP, len(answer))
+		for i, ip := range answer {
+			ips[i] = append(net.IP(nil), ip...)
+		}
+		return ips
+	default:
+		return answer
+	}
+}
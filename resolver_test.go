@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testResolver разрешает имена по hosts. Пока down, запросы завершаются тайм-аутом.
+type testResolver struct {
+	hosts map[string][]string
+	down  bool
+	calls int
+}
+
+func (r *testResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	r.calls++
+	if r.down {
+		return nil, &net.DNSError{Err: "i/o timeout", Name: host, Server: "10.0.0.2:53", IsTimeout: true}
+	}
+	addrs, ok := r.hosts[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	return addrs, nil
+}
+
+func (r *testResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	addrs, err := r.LookupHost(ctx, host)
+	var ipAddrs []net.IPAddr
+	for _, addr := range addrs {
+		ipAddrs = append(ipAddrs, net.IPAddr{IP: net.ParseIP(addr)})
+	}
+	return ipAddrs, err
+}
+
+func (r *testResolver) LookupIP(ctx context.Context, _, host string) ([]net.IP, error) {
+	addrs, err := r.LookupHost(ctx, host)
+	var ips []net.IP
+	for _, addr := range addrs {
+		ips = append(ips, net.ParseIP(addr))
+	}
+	return ips, err
+}
+
+func TestResolver(t *testing.T) {
+	backend := &testResolver{hosts: map[string][]string{"api.example.com": {"10.0.0.1"}}}
+	group := tripOnFirstFailure()
+	resolver := NewResolver(backend, group)
+	ctx := context.Background()
+
+	addrs, err := resolver.LookupHost(ctx, "api.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1"}, addrs)
+	_, err = resolver.LookupHost(ctx, "missing.example.com")
+	var dnsErr *net.DNSError
+	require.ErrorAs(t, err, &dnsErr)
+	assert.True(t, dnsErr.IsNotFound)
+	cb := group.Get("dns")
+	assert.Equal(t, uint32(2), cb.Counts().TotalSuccess)
+
+	backend.down = true
+	_, err = resolver.LookupIPAddr(ctx, "api.example.com")
+	require.ErrorAs(t, err, &dnsErr)
+	assert.True(t, dnsErr.IsTimeout)
+	assert.Equal(t, StateOpen, cb.State())
+
+	_, err = resolver.LookupIP(ctx, "ip4", "api.example.com")
+	assert.ErrorIs(t, err, ErrOpenState)
+	assert.EqualError(t, err, "circuit breaker dns: "+ErrOpenState.Error())
+	assert.Equal(t, 3, backend.calls)
+}
+
+func TestResolver_Stale(t *testing.T) {
+	backend := &testResolver{hosts: map[string][]string{
+		"api.example.com": {"10.0.0.1", "10.0.0.2"},
+		"db.example.com":  {"10.0.1.1"},
+		"old.example.net": {"10.0.2.1"},
+	}}
+	group := tripOnFirstFailure()
+	resolver := NewResolver(backend, group, WithResolverKey(DNSZoneKey), WithResolverStale(2))
+	ctx := context.Background()
+
+	for _, host := range []string{"old.example.net", "api.example.com", "db.example.com"} {
+		_, err := resolver.LookupIP(ctx, "ip", host)
+		require.NoError(t, err)
+	}
+	delete(backend.hosts, "db.example.com")
+	_, err := resolver.LookupIP(ctx, "ip", "db.example.com")
+	require.Error(t, err)
+
+	backend.down = true
+	ips, err := resolver.LookupIP(ctx, "ip", "api.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("10.0.0.2")}, ips)
+	assert.Equal(t, StateOpen, group.Get("example.com").State())
+
+	ips[0][15] = 9
+	ips, err = resolver.LookupIP(ctx, "ip", "api.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, net.ParseIP("10.0.0.1"), ips[0])
+
+	_, err = resolver.LookupIP(ctx, "ip", "db.example.com")
+	assert.ErrorIs(t, err, ErrOpenState)
+	_, err = resolver.LookupIP(ctx, "ip", "old.example.net")
+	var dnsErr *net.DNSError
+	require.ErrorAs(t, err, &dnsErr)
+	assert.True(t, dnsErr.IsTimeout)
+	_, err = resolver.LookupHost(ctx, "api.example.com")
+	assert.ErrorIs(t, err, ErrOpenState)
+}
+
+func TestDNSZoneKey(t *testing.T) {
+	assert.Equal(t, "example.com", DNSZoneKey("api.eu.Example.com."))
+	assert.Equal(t, "example.com", DNSZoneKey("example.com"))
+	assert.Equal(t, "localhost", DNSZoneKey("localhost"))
+}
+
+func TestDefaultResolverFailurePolicy(t *testing.T) {
+	assert.True(t, DefaultResolverFailurePolicy(&net.DNSError{Err: "i/o timeout", IsTimeout: true}))
+	assert.True(t, DefaultResolverFailurePolicy(&net.DNSError{Err: "server misbehaving", IsTemporary: true}))
+	assert.False(t, DefaultResolverFailurePolicy(&net.DNSError{Err: "no such host", IsNotFound: true}))
+	assert.False(t, DefaultResolverFailurePolicy(context.Canceled))
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"runtime"
+	"runtime/metrics"
+)
+
+// ResourceUsage описывает состояние ресурсов текущего процесса.
+type ResourceUsage struct {
+	Goroutines int
+	// Объем памяти, занятой объектами в куче, в байтах.
+	HeapBytes uint64
+	// Загрузка CPU в диапазоне 0..1. RuntimeResourceProbe ее не заполняет,
+	// значение должен предоставлять пользовательский ResourceProbe.
+	CPULoad float64
+}
+
+type ResourceProbe interface {
+	Usage() ResourceUsage
+}
+
+// RuntimeResourceProbe получает кол-во горутин и размер кучи из runtime.
+type RuntimeResourceProbe struct{}
+
+func (RuntimeResourceProbe) Usage() ResourceUsage {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(sample)
+
+	usage := ResourceUsage{Goroutines: runtime.NumGoroutine()}
+	if sample[0].Value.Kind() == metrics.KindUint64 {
+		usage.HeapBytes = sample[0].Value.Uint64()
+	}
+
+	return usage
+}
+
+// WithResourceProbe включает проверку ресурсов процесса перед каждым запросом.
+// Если overloaded возвращает true, запрос отклоняется с ErrResourcePressure
+// без изменения счетчиков и состояния.
+func WithResourceProbe(probe ResourceProbe, overloaded func(usage ResourceUsage) bool) Option {
+	return func(s *settings) {
+		s.resourceProbe = probe
+		s.overloaded = overloaded
+	}
+}
+
+func (cb *CircuitBreaker) underResourcePressure() bool {
+	s := cb.config()
+	if s.resourceProbe == nil || s.overloaded == nil {
+		return false
+	}
+	return s.overloaded(s.resourceProbe.Usage())
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testResourceProbe struct {
+	usage ResourceUsage
+}
+
+func (p *testResourceProbe) Usage() ResourceUsage {
+	return p.usage
+}
+
+func TestCircuitBreaker_ResourcePressure(t *testing.T) {
+	probe := &testResourceProbe{}
+
+	cb := NewCircuitBreaker(WithResourceProbe(probe, func(usage ResourceUsage) bool {
+		return usage.CPULoad > 0.9
+	}))
+
+	assert.Nil(t, succeed(cb))
+
+	// процесс перегружен - запросы отклоняются, счетчики не меняются
+	probe.usage.CPULoad = 0.95
+	assert.ErrorIs(t, succeed(cb), ErrResourcePressure)
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, cb.Counts())
+
+	probe.usage.CPULoad = 0.5
+	assert.Nil(t, succeed(cb))
+}
+
+func TestRuntimeResourceProbe(t *testing.T) {
+	usage := RuntimeResourceProbe{}.Usage()
+	assert.Positive(t, usage.Goroutines)
+	assert.Positive(t, usage.HeapBytes)
+}
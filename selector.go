@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync/atomic"
+)
+
+// ErrNoTargets возвращается Selector без целей.
+var ErrNoTargets = errors.New("no targets")
+
+// Target - цель балансировки нагрузки со своим Circuit Breaker.
+type Target struct {
+	Addr    string
+	Breaker *CircuitBreaker
+	// Произвольные данные цели, например клиент или соединение.
+	Value interface{}
+}
+
+// SelectorStrategy определяет порядок выбора целей Selector.
+type SelectorStrategy int
+
+const (
+	// SelectRoundRobin выбирает цели по очереди.
+	SelectRoundRobin SelectorStrategy = iota
+	// SelectLeastFailures выбирает цель с наименьшим Counts.TotalFailures,
+	// а среди равных - по очереди.
+	SelectLeastFailures
+)
+
+type SelectorOption func(*Selector)
+
+// WithSelectorStrategy задает порядок выбора целей. По умолчанию SelectRoundRobin.
+func WithSelectorStrategy(strategy SelectorStrategy) SelectorOption {
+	return func(s *Selector) {
+		s.strategy = strategy
+	}
+}
+
+// Selector выбирает цель, Circuit Breaker которой допускает запрос, для
+// балансировки нагрузки на стороне клиента. Цели в состоянии Open пропускаются
+// без учета отказа, в состоянии Half-Open выбираются для пробных запросов,
+// пока Circuit Breaker их допускает:
+//
+//	selector := NewSelector([]Target{
+//		{Addr: "10.0.0.1:8080", Breaker: group.Get("10.0.0.1:8080")},
+//		{Addr: "10.0.0.2:8080", Breaker: group.Get("10.0.0.2:8080")},
+//	})
+//	target, done, err := selector.Select()
+//	if err != nil {
+//		return err
+//	}
+//	err = send(target.Addr)
+//	done(err)
+//
+// Selector неизменяем; при изменении набора целей создается новый.
+type Selector struct {
+	targets  []Target
+	strategy SelectorStrategy
+	next     atomic.Uint32
+}
+
+// NewSelector возвращает Selector целей targets. Выбор начинается со случайной
+// цели, чтобы клиенты не начинали с одной.
+func NewSelector(targets []Target, options ...SelectorOption) *Selector {
+	s := &Selector{targets: append([]Target(nil), targets...)}
+	for _, opt := range options {
+		opt(s)
+	}
+	if len(s.targets) > 0 {
+		s.next.Store(uint32(rand.Intn(len(s.targets))))
+	}
+	return s
+}
+
+// Targets возвращает цели Selector.
+func (s *Selector) Targets() []Target {
+	return append([]Target(nil), s.targets...)
+}
+
+// Select возвращает цель, допустившую запрос, и функцию done, которой сообщается
+// результат запроса: ошибка учитывается как неуспешный запрос. Если все цели
+// отклоняют запрос, возвращается ошибка, оборачивающая ErrOpenState или
+// ErrTooManyRequests, а без целей - ErrNoTargets.
+func (s *Selector) Select() (Target, func(err error), error) {
+	target, done, rejected, err := s.selectTarget()
+	if err != nil && rejected != nil {
+		err = fmt.Errorf("circuit breaker %s: %w", rejected.Path(), err)
+	}
+	return target, done, err
+}
+
+// selectTarget возвращает допустившую запрос цель или Circuit Breaker, первым
+// отклонивший запрос, и ошибку отказа.
+func (s *Selector) selectTarget() (Target, func(err error), *CircuitBreaker, error) {
+	n := uint32(len(s.targets))
+	if n == 0 {
+		return Target{}, nil, nil, ErrNoTargets
+	}
+
+	start := s.next.Add(1)
+	order := make([]Target, 0, n)
+	for i := uint32(0); i < n; i++ {
+		order = append(order, s.targets[(start+i)%n])
+	}
+	if s.strategy == SelectLeastFailures {
+		sort.SliceStable(order, func(i, j int) bool {
+			return order[i].Breaker.Counts().TotalFailures < order[j].Breaker.Counts().TotalFailures
+		})
+	}
+
+	var rejected *CircuitBreaker
+	rejectErr := ErrOpenState
+	for _, target := range order {
+		if target.Breaker.isOpen() {
+			if rejected == nil {
+				rejected = target.Breaker
+			}
+			continue
+		}
+		done, err := target.Breaker.allow()
+		if err != nil {
+			if rejected == nil {
+				rejected, rejectErr = target.Breaker, err
+			}
+			continue
+		}
+		return target, done, nil, nil
+	}
+	return Target{}, nil, rejected, rejectErr
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// selectorTargets возвращает цели addrs с Circuit Breaker группы.
+func selectorTargets(group *Group, addrs ...string) []Target {
+	var targets []Target
+	for _, addr := range addrs {
+		targets = append(targets, Target{Addr: addr, Breaker: group.Get(addr)})
+	}
+	return targets
+}
+
+func TestSelector(t *testing.T) {
+	group := tripOnFirstFailure()
+	selector := NewSelector(selectorTargets(group, "a", "b", "c"))
+
+	selected := make(map[string]int)
+	for i := 0; i < 3; i++ {
+		target, done, err := selector.Select()
+		require.NoError(t, err)
+		selected[target.Addr]++
+		if target.Addr == "b" {
+			done(errors.New("connection refused"))
+		} else {
+			done(nil)
+		}
+	}
+	assert.Equal(t, map[string]int{"a": 1, "b": 1, "c": 1}, selected)
+	assert.Equal(t, StateOpen, group.Get("b").State())
+
+	for i := 0; i < 4; i++ {
+		target, done, err := selector.Select()
+		require.NoError(t, err)
+		assert.NotEqual(t, "b", target.Addr)
+		done(nil)
+	}
+	assert.Equal(t, uint64(0), group.Get("b").Stats().Rejections)
+}
+
+func TestSelector_AllOpen(t *testing.T) {
+	group := tripOnFirstFailure()
+	selector := NewSelector(selectorTargets(group, "a", "b"))
+	group.Get("a").trip()
+	group.Get("b").trip()
+
+	_, _, err := selector.Select()
+	assert.ErrorIs(t, err, ErrOpenState)
+	assert.Regexp(t, `^circuit breaker [ab]: state is open$`, err.Error())
+
+	_, _, err = NewSelector(nil).Select()
+	assert.ErrorIs(t, err, ErrNoTargets)
+}
+
+func TestSelector_LeastFailures(t *testing.T) {
+	group := NewGroup(func(key string) *CircuitBreaker {
+		return NewCircuitBreaker(WithName(key))
+	})
+	selector := NewSelector(selectorTargets(group, "a", "b", "c"), WithSelectorStrategy(SelectLeastFailures))
+	assert.NotNil(t, fail(group.Get("a")))
+	assert.NotNil(t, fail(group.Get("a")))
+	assert.NotNil(t, fail(group.Get("b")))
+
+	for i := 0; i < 3; i++ {
+		target, done, err := selector.Select()
+		require.NoError(t, err)
+		assert.Equal(t, "c", target.Addr)
+		done(nil)
+	}
+
+	assert.NotNil(t, fail(group.Get("c")))
+	assert.NotNil(t, fail(group.Get("c")))
+	target, done, err := selector.Select()
+	require.NoError(t, err)
+	assert.Equal(t, "b", target.Addr)
+	done(nil)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type ShedOption func(*shedder)
+
+// WithShedDependencies задает Circuit Breaker нижестоящих сервисов: пока хотя бы один
+// из них в состоянии Open, входящие запросы отклоняются, не доходя до обработчика.
+func WithShedDependencies(dependencies ...*CircuitBreaker) ShedOption {
+	return func(s *shedder) {
+		s.dependencies = append(s.dependencies, dependencies...)
+	}
+}
+
+// WithShedStatusPolicy задает, какие статусы ответа обработчика считаются
+// неуспешными запросами. По умолчанию DefaultStatusPolicy.
+func WithShedStatusPolicy(isFailure func(status int) bool) ShedOption {
+	return func(s *shedder) {
+		s.isFailure = isFailure
+	}
+}
+
+// WithShedRetryAfter задает Retry-After для отказов, срок которых неизвестен,
+// например ErrTooManyRequests или ErrResourcePressure. По умолчанию 1s.
+func WithShedRetryAfter(retryAfter time.Duration) ShedOption {
+	return func(s *shedder) {
+		s.retryAfter = retryAfter
+	}
+}
+
+// ShedMiddleware возвращает middleware входящих HTTP-запросов, выполняющее
+// обработчик через cb. Если cb или одна из WithShedDependencies отклоняет запрос,
+// клиент получает 503 с заголовком Retry-After: оставшимся временем состояния Open
+// или WithShedRetryAfter. Для отказа по нагрузке используется WithResourceProbe
+// или WithShedOnQueueDepth у cb.
+func ShedMiddleware(cb *CircuitBreaker, options ...ShedOption) func(http.Handler) http.Handler {
+	return newShedder(func(*http.Request) *CircuitBreaker { return cb }, options).middleware
+}
+
+// RouteMiddleware возвращает middleware, аналогичное ShedMiddleware, с отдельным
+// Circuit Breaker группы для каждого ключа запроса, например шаблона маршрута
+// chi или gorilla/mux, чтобы ошибки одного маршрута не отклоняли запросы к остальным.
+// Ключ должен иметь ограниченное кол-во значений, см. также WithGroupCapacity.
+func RouteMiddleware(group *Group, key func(req *http.Request) string, options ...ShedOption) func(http.Handler) http.Handler {
+	return newShedder(func(req *http.Request) *CircuitBreaker { return group.Get(key(req)) }, options).middleware
+}
+
+type shedder struct {
+	breaker      func(req *http.Request) *CircuitBreaker
+	dependencies []*CircuitBreaker
+	isFailure    func(status int) bool
+	retryAfter   time.Duration
+}
+
+func newShedder(breaker func(req *http.Request) *CircuitBreaker, options []ShedOption) *shedder {
+	s := &shedder{breaker: breaker, isFailure: DefaultStatusPolicy, retryAfter: time.Second}
+	for _, opt := range options {
+		opt(s)
+	}
+	return s
+}
+
+func (s *shedder) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		for _, dependency := range s.dependencies {
+			if dependency.State() == StateOpen {
+				s.reject(w, dependency)
+				return
+			}
+		}
+
+		cb := s.breaker(req)
+		_, err := cb.ExecuteContext(req.Context(), func(context.Context) (interface{}, error) {
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, req)
+			if s.isFailure(sw.status) {
+				return nil, &StatusError{StatusCode: sw.status}
+			}
+			return nil, nil
+		})
+		if isRejection(err) {
+			s.reject(w, cb)
+		}
+	})
+}
+
+func (s *shedder) reject(w http.ResponseWriter, cb *CircuitBreaker) {
+	retryAfter := s.retryAfter
+	if current := cb.current.Load(); current.state == StateOpen {
+		if remaining := current.expiry.Sub(cb.config().timeProvider.Now()); remaining > 0 {
+			retryAfter = remaining
+		}
+	}
+
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+}
+
+// statusWriter запоминает статус ответа обработчика.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = status, true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap позволяет http.ResponseController использовать исходный ResponseWriter.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
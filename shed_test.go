@@ -0,0 +1,102 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShedMiddleware(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cb := NewCircuitBreaker(
+		WithTimeProvider(clock),
+		WithTimeout(30*time.Second),
+		WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures >= 2 }),
+	)
+	status := http.StatusInternalServerError
+	handler := ShedMiddleware(cb)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+	}))
+	serve := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, http.StatusInternalServerError, serve().Code)
+	}
+	assert.Equal(t, StateOpen, cb.State())
+
+	clock.Advance(10500 * time.Millisecond)
+	rec := serve()
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "20", rec.Header().Get("Retry-After"))
+
+	clock.Advance(20 * time.Second)
+	status = http.StatusNotFound
+	assert.Equal(t, http.StatusNotFound, serve().Code)
+	assert.Equal(t, uint64(2), cb.Stats().Failures)
+}
+
+func TestShedMiddleware_Dependencies(t *testing.T) {
+	cb := NewCircuitBreaker()
+	payments := NewCircuitBreaker(WithTimeout(time.Minute))
+	var calls int
+	handler := ShedMiddleware(cb, WithShedDependencies(payments))(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		calls++
+	}))
+	serve := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, serve().Code)
+
+	payments.Trip()
+	rec := serve()
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, uint64(0), cb.Stats().Rejections)
+}
+
+func TestShedMiddleware_ResourcePressure(t *testing.T) {
+	cb := NewCircuitBreaker(WithResourceProbe(RuntimeResourceProbe{}, func(ResourceUsage) bool { return true }))
+	handler := ShedMiddleware(cb, WithShedRetryAfter(5*time.Second))(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		t.Fatal("handler must not be called")
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "5", rec.Header().Get("Retry-After"))
+}
+
+func TestRouteMiddleware(t *testing.T) {
+	group := tripOnFirstFailure()
+	route := func(req *http.Request) string { return req.Method + " " + req.URL.Path }
+	handler := RouteMiddleware(group, route)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/orders" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	serve := func(path string) int {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusInternalServerError, serve("/orders"))
+	assert.Equal(t, http.StatusServiceUnavailable, serve("/orders"))
+
+	// ошибки одного маршрута не влияют на другой
+	assert.Equal(t, http.StatusOK, serve("/users"))
+	assert.Equal(t, StateOpen, group.Get("GET /orders").State())
+	assert.Equal(t, StateClosed, group.Get("GET /users").State())
+}
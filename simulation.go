@@ -0,0 +1,85 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+)
+
+// Outcome - результат запроса в сценарии симуляции.
+type Outcome int
+
+const (
+	OutcomeSuccess Outcome = iota
+	OutcomeFailure
+)
+
+var errSimulatedFailure = errors.New("simulated failure")
+
+// SimulationStep - запрос сценария. At - смещение момента запроса от начала симуляции,
+// Latency - время выполнения запроса.
+type SimulationStep struct {
+	At      time.Duration
+	Outcome Outcome
+	Latency time.Duration
+}
+
+// SimulationStepResult - результат прохождения шага через Circuit Breaker.
+type SimulationStepResult struct {
+	At time.Time
+	// Состояние Circuit Breaker в момент допуска запроса.
+	State State
+	// Ошибка Execute: ошибка запроса или причина отказа Circuit Breaker.
+	Err      error
+	Rejected bool
+}
+
+type SimulationResult struct {
+	Steps       []SimulationStepResult
+	Transitions []StateChange
+	Rejected    int
+}
+
+// SimulationStart - момент начала симуляции, от которого отсчитываются SimulationStep.At.
+var SimulationStart = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Simulate прогоняет сценарий через Circuit Breaker с опциями options на управляемых часах
+// и возвращает все переходы между состояниями. Позволяет проверить конфигурацию
+// до выкатки в production. Шаги должны быть упорядочены по At.
+func Simulate(steps []SimulationStep, options ...Option) SimulationResult {
+	clock := clocktest.New(SimulationStart)
+
+	cb := NewCircuitBreaker(append(options[:len(options):len(options)], WithTimeProvider(clock))...)
+
+	var result SimulationResult
+	cb.onTransition = func(change StateChange) {
+		result.Transitions = append(result.Transitions, change)
+	}
+
+	for _, step := range steps {
+		if at := SimulationStart.Add(step.At); at.After(clock.Now()) {
+			clock.Set(at)
+		}
+
+		stepResult := SimulationStepResult{At: clock.Now()}
+		_, err := cb.Execute(func() (interface{}, error) {
+			stepResult.State = cb.State()
+			clock.Advance(step.Latency)
+			if step.Outcome == OutcomeFailure {
+				return nil, errSimulatedFailure
+			}
+			return nil, nil
+		})
+
+		stepResult.Err = err
+		if err != nil && !errors.Is(err, errSimulatedFailure) {
+			stepResult.Rejected = true
+			stepResult.State = cb.State()
+			result.Rejected++
+		}
+		result.Steps = append(result.Steps, stepResult)
+	}
+
+	return result
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimulate(t *testing.T) {
+	var steps []SimulationStep
+	// 3 ошибки подряд открывают Circuit Breaker
+	for i := 0; i < 3; i++ {
+		steps = append(steps, SimulationStep{At: time.Duration(i) * time.Second, Outcome: OutcomeFailure})
+	}
+	// запрос в Open отклоняется
+	steps = append(steps, SimulationStep{At: 5 * time.Second})
+	// после timeout два успешных запроса закрывают Circuit Breaker
+	steps = append(steps,
+		SimulationStep{At: 20 * time.Second, Latency: time.Second},
+		SimulationStep{At: 21 * time.Second},
+	)
+
+	result := Simulate(steps,
+		WithTimeout(10*time.Second),
+		WithMaxRequests(2),
+		WithReadyToTrip(func(counts Counts) bool {
+			return counts.ConsecutiveFailures >= 3
+		}),
+	)
+
+	assert.Equal(t, 1, result.Rejected)
+	assert.True(t, result.Steps[3].Rejected)
+	assert.ErrorIs(t, result.Steps[3].Err, ErrOpenState)
+	assert.Equal(t, StateHalfOpen, result.Steps[4].State)
+
+	assert.Equal(t, []StateChange{
+		{From: StateClosed, To: StateOpen, At: SimulationStart.Add(2 * time.Second), Reason: ReasonTripStrategy,
+			Counts: Counts{Requests: 3, TotalFailures: 3, ConsecutiveFailures: 3}, Err: errSimulatedFailure},
+		{From: StateOpen, To: StateHalfOpen, At: SimulationStart.Add(20 * time.Second), Reason: ReasonTimeout},
+		{From: StateHalfOpen, To: StateClosed, At: SimulationStart.Add(21 * time.Second), Reason: ReasonRecovered,
+			Counts: Counts{Requests: 2, TotalSuccess: 2, ConsecutiveSuccesses: 2}},
+	}, result.Transitions)
+}
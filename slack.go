@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+	"text/template"
+	"time"
+)
+
+var (
+	defaultSlackOpenTemplate = template.Must(template.New("open").Parse(
+		`:red_circle: Circuit breaker *{{.Name}}* is open: {{.Reason}}{{if .Err}} ({{.Err}}){{end}}`))
+	defaultSlackClosedTemplate = template.Must(template.New("closed").Parse(
+		`:large_green_circle: Circuit breaker *{{.Name}}* recovered`))
+)
+
+// SlackNotifier отправляет в Slack incoming webhook сообщение о переходе
+// Circuit Breaker в Open и последующее сообщение о восстановлении.
+type SlackNotifier struct {
+	webhook        *Webhook
+	webhookOptions []WebhookOption
+	open, closed   *template.Template
+	throttle       time.Duration
+
+	mu sync.Mutex
+	// Время последнего сообщения о переходе в Open по путям Circuit Breaker.
+	alertedAt map[string]time.Time
+	// Circuit Breaker, о переходе которых в Open сообщено, а о восстановлении - нет.
+	alerted    map[string]bool
+	suppressed uint64
+}
+
+type SlackOption func(*SlackNotifier)
+
+// WithSlackTemplates задает шаблоны сообщений о переходе в Open и о восстановлении.
+// Шаблоны выполняются над StateChange.
+func WithSlackTemplates(open, closed *template.Template) SlackOption {
+	return func(n *SlackNotifier) {
+		n.open = open
+		n.closed = closed
+	}
+}
+
+// WithSlackThrottle ограничивает сообщения о переходе в Open одним за interval
+// для каждого Circuit Breaker. О восстановлении сообщается, только если
+// о предшествующем переходе в Open было отправлено сообщение.
+func WithSlackThrottle(interval time.Duration) SlackOption {
+	return func(n *SlackNotifier) {
+		n.throttle = interval
+	}
+}
+
+// WithSlackWebhookOptions задает параметры отправки: клиент, таймаут, повторы.
+func WithSlackWebhookOptions(options ...WebhookOption) SlackOption {
+	return func(n *SlackNotifier) {
+		n.webhookOptions = append(n.webhookOptions, options...)
+	}
+}
+
+func NewSlackNotifier(url string, options ...SlackOption) *SlackNotifier {
+	n := &SlackNotifier{
+		open:      defaultSlackOpenTemplate,
+		closed:    defaultSlackClosedTemplate,
+		alertedAt: make(map[string]time.Time),
+		alerted:   make(map[string]bool),
+	}
+	for _, opt := range options {
+		opt(n)
+	}
+	n.webhook = NewWebhook([]string{url}, n.webhookOptions...)
+	return n
+}
+
+// WithSlack включает отправку сообщений о переходах Circuit Breaker в n.
+func WithSlack(n *SlackNotifier) Option {
+	return withObserver(n)
+}
+
+// Suppressed возвращает кол-во сообщений о переходе в Open, пропущенных из-за WithSlackThrottle.
+func (n *SlackNotifier) Suppressed() uint64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.suppressed
+}
+
+// Close дожидается отправки принятых сообщений.
+func (n *SlackNotifier) Close() {
+	n.webhook.Close()
+}
+
+func (n *SlackNotifier) observeTransition(_ *CircuitBreaker, change StateChange) {
+	var tmpl *template.Template
+	switch {
+	case change.To == StateOpen && n.alert(change):
+		tmpl = n.open
+	case change.To == StateClosed && n.recovered(change):
+		tmpl = n.closed
+	default:
+		return
+	}
+
+	var text bytes.Buffer
+	if err := tmpl.Execute(&text, change); err != nil {
+		return
+	}
+	body, err := json.Marshal(struct {
+		Text string `json:"text"`
+	}{text.String()})
+	if err != nil {
+		return
+	}
+	if n.webhook.enqueue(body) {
+		n.sent(change)
+	}
+}
+
+func (*SlackNotifier) observeCall(*CircuitBreaker, OutcomeRecord) {}
+
+func (*SlackNotifier) observeRejection(*CircuitBreaker, error) {}
+
+// alert сообщает, нужно ли отправить сообщение о переходе в Open.
+func (n *SlackNotifier) alert(change StateChange) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if last, ok := n.alertedAt[change.Name]; ok && change.At.Sub(last) < n.throttle {
+		n.suppressed++
+		return false
+	}
+	return true
+}
+
+// recovered сообщает, нужно ли отправить сообщение о восстановлении.
+func (n *SlackNotifier) recovered(change StateChange) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.alerted[change.Name]
+}
+
+// sent отмечает сообщение о change, принятое к отправке.
+func (n *SlackNotifier) sent(change StateChange) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if change.To == StateOpen {
+		n.alertedAt[change.Name] = change.At
+		n.alerted[change.Name] = true
+	} else {
+		delete(n.alerted, change.Name)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func slackServer(t *testing.T) (*httptest.Server, func() []string) {
+	var (
+		mu    sync.Mutex
+		texts []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct{ Text string }
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		mu.Lock()
+		texts = append(texts, body.Text)
+		mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return texts
+	}
+}
+
+func TestWithSlack(t *testing.T) {
+	server, texts := slackServer(t)
+	slack := NewSlackNotifier(server.URL, WithSlackThrottle(time.Minute))
+	clock := clocktest.New(time.Now())
+	cb := NewCircuitBreaker(
+		WithName("payments"),
+		WithClock(clock),
+		WithTimeout(time.Second),
+		WithMaxRequests(1),
+		WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures > 0 }),
+		WithSlack(slack),
+	)
+
+	assert.NotNil(t, fail(cb))
+	clock.Advance(2 * time.Second)
+	assert.Nil(t, succeed(cb))
+
+	// повторный переход в течение минуты не сообщается, как и последующее восстановление
+	assert.NotNil(t, fail(cb))
+	clock.Advance(2 * time.Second)
+	assert.Nil(t, succeed(cb))
+
+	clock.Advance(time.Minute)
+	cb.trip()
+	slack.Close()
+
+	assert.Equal(t, []string{
+		":red_circle: Circuit breaker *payments* is open: trip strategy (fail)",
+		":large_green_circle: Circuit breaker *payments* recovered",
+		":red_circle: Circuit breaker *payments* is open: manual",
+	}, texts())
+	assert.Equal(t, uint64(1), slack.Suppressed())
+}
+
+func TestSlackNotifier_Templates(t *testing.T) {
+	server, texts := slackServer(t)
+	slack := NewSlackNotifier(server.URL, WithSlackTemplates(
+		template.Must(template.New("open").Parse(`{{.Name}} {{.Labels.team}} open after {{.Counts.ConsecutiveFailures}} failures`)),
+		template.Must(template.New("closed").Parse(`{{.Name}} closed`)),
+	))
+	cb := NewCircuitBreaker(
+		WithName("search"),
+		WithLabels(map[string]string{"team": "discovery"}),
+		WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures > 1 }),
+		WithSlack(slack),
+	)
+
+	assert.NotNil(t, fail(cb))
+	assert.NotNil(t, fail(cb))
+	slack.Close()
+
+	assert.Equal(t, []string{"search discovery open after 2 failures"}, texts())
+}
+
+func TestSlackNotifier_Dropped(t *testing.T) {
+	server, texts := slackServer(t)
+	slack := NewSlackNotifier(server.URL, WithSlackThrottle(time.Minute))
+	cb := NewCircuitBreaker(WithName("payments"), WithSlack(slack))
+	slack.Close()
+
+	// непринятое сообщение не отмечает Circuit Breaker и не ограничивает следующие
+	cb.trip()
+	assert.Empty(t, texts())
+	assert.Empty(t, slack.alerted)
+	assert.Empty(t, slack.alertedAt)
+	assert.Equal(t, uint64(0), slack.Suppressed())
+}
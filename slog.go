@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// WithSlog пишет события Circuit Breaker в logger, а при ненулевом summaryInterval -
+// и периодическую сводку (см. WithLogger и WithLogSummary). Уровни и группы
+// logger учитываются как обычно.
+func WithSlog(logger *slog.Logger, summaryInterval time.Duration) Option {
+	return combine(WithLogger(NewSlogLogger(logger)), WithLogSummary(summaryInterval))
+}
+
+// NewSlogLogger адаптирует logger к интерфейсу Logger.
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return slogLogger{logger}
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func (l slogLogger) Log(level LogLevel, msg string, keyvals ...any) {
+	l.logger.Log(context.Background(), slogLevel(level), msg, keyvals...)
+}
+
+func slogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LogDebug:
+		return slog.LevelDebug
+	case LogWarn:
+		return slog.LevelWarn
+	case LogError:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// LogValue представляет счетчики группой атрибутов slog.
+func (c Counts) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Uint64("requests", uint64(c.Requests)),
+		slog.Uint64("total_success", uint64(c.TotalSuccess)),
+		slog.Uint64("total_failures", uint64(c.TotalFailures)),
+		slog.Uint64("consecutive_successes", uint64(c.ConsecutiveSuccesses)),
+		slog.Uint64("consecutive_failures", uint64(c.ConsecutiveFailures)),
+	)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// safeBuffer - bytes.Buffer, в который можно писать из фоновых горутин.
+type safeBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *safeBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *safeBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Len()
+}
+
+func (b *safeBuffer) Buffer() *bytes.Buffer {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return bytes.NewBuffer(append([]byte(nil), b.buf.Bytes()...))
+}
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		delete(entry, "time")
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestWithSlog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})).WithGroup("cb")
+
+	cb := NewCircuitBreaker(
+		WithName("payments"),
+		WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures > 0 }),
+		WithSlog(logger, 0),
+	)
+	assert.NotNil(t, fail(cb))
+
+	assert.Equal(t, []map[string]any{{
+		"level": "INFO",
+		"msg":   "state changed",
+		"cb": map[string]any{
+			"name": "payments",
+			"from": "closed",
+			"to":   "open",
+			"counts": map[string]any{
+				"requests": 1.0, "total_success": 0.0, "total_failures": 1.0,
+				"consecutive_successes": 0.0, "consecutive_failures": 1.0,
+			},
+		},
+	}}, decodeLines(t, &buf))
+}
+
+func TestWithSlog_Summary(t *testing.T) {
+	var buf safeBuffer
+	clock := clocktest.New(time.Now())
+	cb := NewCircuitBreaker(
+		WithName("payments"),
+		WithClock(clock),
+		WithSlog(slog.New(slog.NewJSONHandler(&buf, nil)), time.Minute),
+	)
+	defer cb.Close(context.Background())
+	assert.Nil(t, succeed(cb))
+
+	assert.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+	clock.Advance(time.Minute)
+	assert.Eventually(t, func() bool { return buf.Len() > 0 }, time.Second, time.Millisecond)
+
+	entries := decodeLines(t, buf.Buffer())
+	assert.Equal(t, "summary", entries[0]["msg"])
+	assert.Equal(t, "closed", entries[0]["state"])
+	assert.Equal(t, 1.0, entries[0]["counts"].(map[string]any)["total_success"])
+}
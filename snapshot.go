@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *State) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "closed":
+		*s = StateClosed
+	case "open":
+		*s = StateOpen
+	case "half-open":
+		*s = StateHalfOpen
+	default:
+		return fmt.Errorf("unknown state %q", text)
+	}
+	return nil
+}
+
+// Snapshot - сохраняемое состояние Circuit Breaker. Позволяет сохранить,
+// передать или изучить состояние в виде структурированных данных.
+type Snapshot struct {
+	State      State     `json:"state"`
+	Counts     Counts    `json:"counts"`
+	Expiry     time.Time `json:"expiry"`
+	Generation uint64    `json:"generation"`
+}
+
+func (cb *CircuitBreaker) Snapshot() Snapshot {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	current := cb.current.Load()
+
+	return Snapshot{
+		State:      current.state,
+		Counts:     cb.counts.snapshot(),
+		Expiry:     current.expiry,
+		Generation: current.generation,
+	}
+}
+
+// Restore восстанавливает состояние из snapshot. Запросы, начатые до Restore,
+// не учитываются. Уведомления о смене состояния не отправляются.
+func (cb *CircuitBreaker) Restore(snapshot Snapshot) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := cb.config().timeProvider.Now()
+	prev := cb.current.Load()
+	cb.stateTimes.record(stateSpan{state: prev.state, from: prev.since, to: now})
+
+	next := &stateSnapshot{
+		state: snapshot.State,
+		// номер состояния должен отличаться от текущего, чтобы не учитывать начатые запросы
+		generation: max(snapshot.Generation, prev.generation+1),
+		since:      now,
+	}
+	if snapshot.State == StateOpen {
+		next.expiry = snapshot.Expiry
+	}
+
+	cb.counts.restore(snapshot.Counts)
+	cb.current.Store(next)
+	cb.startStateTimers(next)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+)
+
+func TestCircuitBreaker_SnapshotRestore(t *testing.T) {
+	clock := clocktest.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	cb := NewCircuitBreaker(WithTimeProvider(clock))
+	assert.Nil(t, succeed(cb))
+	assert.NotNil(t, fail(cb))
+	assert.Nil(t, succeed(cb))
+
+	snapshot := cb.Snapshot()
+	assert.Equal(t, Snapshot{State: StateClosed, Counts: Counts{3, 2, 1, 1, 0}}, snapshot)
+
+	restored := NewCircuitBreaker(WithTimeProvider(clock))
+	restored.Restore(snapshot)
+	assert.Equal(t, Counts{3, 2, 1, 1, 0}, restored.Counts())
+
+	// счетчики после восстановления продолжают работать
+	assert.Nil(t, succeed(restored))
+	assert.Equal(t, Counts{4, 3, 1, 2, 0}, restored.Counts())
+}
+
+func TestCircuitBreaker_SnapshotJSON(t *testing.T) {
+	clock := clocktest.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	cb := NewCircuitBreaker(WithTimeProvider(clock), WithTimeout(time.Minute))
+	cb.trip()
+
+	data, err := json.Marshal(cb.Snapshot())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"state": "open",
+		"counts": {"Requests":0,"TotalSuccess":0,"TotalFailures":0,"ConsecutiveSuccesses":0,"ConsecutiveFailures":0},
+		"expiry": "2024-01-01T00:01:00Z",
+		"generation": 1
+	}`, string(data))
+
+	var snapshot Snapshot
+	require.NoError(t, json.Unmarshal(data, &snapshot))
+
+	restored := NewCircuitBreaker(WithTimeProvider(clock), WithTimeout(time.Minute))
+	restored.Restore(snapshot)
+	assert.Equal(t, StateOpen, restored.State())
+	assert.ErrorIs(t, succeed(restored), ErrOpenState)
+
+	// срок состояния Open сохраняется
+	clock.Advance(time.Minute + time.Second)
+	assert.Nil(t, succeed(restored))
+	assert.Equal(t, StateHalfOpen, restored.State())
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+)
+
+type SQLOption func(*sqlConnector)
+
+// WithSQLKey задает ключ Circuit Breaker для запроса query, например
+// SQLFingerprintKey, чтобы один медленный запрос не отклонял остальные.
+// По умолчанию SQLDatabaseKey.
+func WithSQLKey(key func(ctx context.Context, query string) string) SQLOption {
+	return func(c *sqlConnector) {
+		c.key = key
+	}
+}
+
+// WithSQLFailurePolicy задает, какие ошибки драйвера считаются неуспешными
+// запросами. По умолчанию DefaultSQLFailurePolicy.
+func WithSQLFailurePolicy(isFailure func(err error) bool) SQLOption {
+	return func(c *sqlConnector) {
+		c.isFailure = isFailure
+	}
+}
+
+// DefaultSQLFailurePolicy считает неуспешными все ошибки, кроме driver.ErrSkip
+// и отмены контекста вызывающей стороной.
+func DefaultSQLFailurePolicy(err error) bool {
+	return !errors.Is(err, driver.ErrSkip) && !errors.Is(err, context.Canceled)
+}
+
+type sqlConnector struct {
+	next      driver.Connector
+	group     *Group
+	key       func(ctx context.Context, query string) string
+	isFailure func(err error) bool
+}
+
+// NewSQLConnector возвращает driver.Connector, выполняющий запросы соединений next
+// через Circuit Breaker группы по ключу запроса:
+//
+//	db := sql.OpenDB(NewSQLConnector(connector, group, WithSQLKey(SQLFingerprintKey)))
+//
+// Через Circuit Breaker выполняются Exec, Query, Prepare, запросы подготовленных
+// выражений и BeginTx с запросом "BEGIN". Commit и Rollback не отклоняются, чтобы
+// начатая транзакция всегда могла завершиться, Ping - чтобы проверки доступности
+// базы отражали ее действительное состояние. Ошибки чтения строк результата не учитываются.
+// Отклоненный запрос завершается ошибкой, оборачивающей ErrOpenState или ErrTooManyRequests.
+func NewSQLConnector(next driver.Connector, group *Group, options ...SQLOption) driver.Connector {
+	c := &sqlConnector{next: next, group: group, key: SQLDatabaseKey, isFailure: DefaultSQLFailurePolicy}
+	for _, opt := range options {
+		opt(c)
+	}
+	return c
+}
+
+func (c *sqlConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.next.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &sqlConn{Conn: conn, connector: c}, nil
+}
+
+func (c *sqlConnector) Driver() driver.Driver {
+	return c.next.Driver()
+}
+
+// execute выполняет call через Circuit Breaker запроса query.
+func (c *sqlConnector) execute(ctx context.Context, query string, call func(ctx context.Context) error) error {
+	cb := c.group.Get(c.key(ctx, query))
+	var called bool
+	var callErr error
+	_, err := cb.ExecuteContext(ctx, func(ctx context.Context) (interface{}, error) {
+		called = true
+		callErr = call(ctx)
+		if callErr != nil && c.isFailure(callErr) {
+			return nil, callErr
+		}
+		return nil, nil
+	})
+	if !called {
+		if isRejection(err) {
+			return fmt.Errorf("circuit breaker %s: %w", cb.Path(), err)
+		}
+		return err
+	}
+	return callErr
+}
+
+type sqlConn struct {
+	driver.Conn
+	connector *sqlConnector
+}
+
+// sqlBeginQuery - запрос, по которому выбирается Circuit Breaker для BeginTx.
+const sqlBeginQuery = "BEGIN"
+
+func (c *sqlConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	var result driver.Result
+	err := c.connector.execute(ctx, query, func(ctx context.Context) (err error) {
+		result, err = execer.ExecContext(ctx, query, args)
+		return err
+	})
+	return result, err
+}
+
+func (c *sqlConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	var rows driver.Rows
+	err := c.connector.execute(ctx, query, func(ctx context.Context) (err error) {
+		rows, err = queryer.QueryContext(ctx, query, args)
+		return err
+	})
+	return rows, err
+}
+
+func (c *sqlConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	err := c.connector.execute(ctx, query, func(ctx context.Context) (err error) {
+		if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+			stmt, err = preparer.PrepareContext(ctx, query)
+		} else {
+			stmt, err = c.Conn.Prepare(query)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &sqlStmt{Stmt: stmt, query: query, connector: c.connector}, nil
+}
+
+func (c *sqlConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *sqlConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	var tx driver.Tx
+	err := c.connector.execute(ctx, sqlBeginQuery, func(ctx context.Context) (err error) {
+		if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+			tx, err = beginner.BeginTx(ctx, opts)
+		} else {
+			tx, err = c.Conn.Begin()
+		}
+		return err
+	})
+	return tx, err
+}
+
+func (c *sqlConn) Ping(ctx context.Context) error {
+	pinger, ok := c.Conn.(driver.Pinger)
+	if !ok {
+		return nil
+	}
+	return pinger.Ping(ctx)
+}
+
+func (c *sqlConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+func (c *sqlConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *sqlConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+type sqlStmt struct {
+	driver.Stmt
+	query     string
+	connector *sqlConnector
+}
+
+func (s *sqlStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	var result driver.Result
+	err := s.connector.execute(ctx, s.query, func(ctx context.Context) (err error) {
+		if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+			result, err = execer.ExecContext(ctx, args)
+			return err
+		}
+		values, err := namedValues(args)
+		if err != nil {
+			return err
+		}
+		result, err = s.Stmt.Exec(values)
+		return err
+	})
+	return result, err
+}
+
+func (s *sqlStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	var rows driver.Rows
+	err := s.connector.execute(ctx, s.query, func(ctx context.Context) (err error) {
+		if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+			rows, err = queryer.QueryContext(ctx, args)
+			return err
+		}
+		values, err := namedValues(args)
+		if err != nil {
+			return err
+		}
+		rows, err = s.Stmt.Query(values)
+		return err
+	})
+	return rows, err
+}
+
+// namedValues возвращает позиционные аргументы для драйвера без поддержки именованных.
+func namedValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, fmt.Errorf("sql driver does not support named argument %s", arg.Name)
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// SQLDatabaseKey возвращает "database", один Circuit Breaker на все запросы к базе.
+func SQLDatabaseKey(_ context.Context, _ string) string {
+	return "database"
+}
+
+type sqlLabelKey struct{}
+
+// WithSQLLabel возвращает контекст, запросы с которым выполняются через Circuit
+// Breaker с ключом label при SQLLabelKey или SQLFingerprintKey, например
+// "reports.monthly" для группы запросов отчета.
+func WithSQLLabel(ctx context.Context, label string) context.Context {
+	return context.WithValue(ctx, sqlLabelKey{}, label)
+}
+
+// SQLLabel возвращает метку запросов, заданную WithSQLLabel.
+func SQLLabel(ctx context.Context) (string, bool) {
+	label, ok := ctx.Value(sqlLabelKey{}).(string)
+	return label, ok && label != ""
+}
+
+// SQLLabelKey возвращает метку запроса, а для запросов без метки - SQLDatabaseKey:
+// отдельные Circuit Breaker получают только явно помеченные запросы.
+func SQLLabelKey(ctx context.Context, query string) string {
+	if label, ok := SQLLabel(ctx); ok {
+		return label
+	}
+	return SQLDatabaseKey(ctx, query)
+}
+
+// SQLFingerprintKey возвращает метку запроса, а для запросов без метки - SQLFingerprint:
+// один Circuit Breaker на каждый вид запроса.
+func SQLFingerprintKey(ctx context.Context, query string) string {
+	if label, ok := SQLLabel(ctx); ok {
+		return label
+	}
+	return SQLFingerprint(query)
+}
+
+var (
+	sqlValueList = regexp.MustCompile(`\?(, \?)+`)
+	sqlRowList   = regexp.MustCompile(`\(\?\)(, \(\?\))+`)
+)
+
+// SQLFingerprint возвращает запрос без значений: литералы и параметры заменяются
+// на "?", списки значений сворачиваются, комментарии удаляются, пробелы
+// нормализуются, слова приводятся к нижнему регистру, например
+// "SELECT * FROM users WHERE id IN (1, 2)" - "select * from users where id in (?)".
+// Имена в кавычках сохраняются.
+func SQLFingerprint(query string) string {
+	var b strings.Builder
+	var prev string
+	emit := func(token string) {
+		if b.Len() > 0 && !sqlTightAfter(prev) && !sqlTightBefore(token) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(token)
+		prev = token
+	}
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			i += end
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				end = len(query) - i - 4
+			}
+			i += end + 4
+		case c == '\'':
+			i = sqlQuoteEnd(query, i, '\'')
+			emit("?")
+		case c == '"' || c == '`':
+			end := sqlQuoteEnd(query, i, c)
+			emit(query[i:end])
+			i = end
+		case c == '?':
+			i++
+			emit("?")
+		case (c == '$' || c == ':' || c == '@') && i+1 < len(query) && sqlWordByte(query[i+1]):
+			i = sqlWordEnd(query, i+1)
+			emit("?")
+		case c >= '0' && c <= '9':
+			i = sqlWordEnd(query, i)
+			if i < len(query) && query[i] == '.' {
+				i = sqlWordEnd(query, i+1)
+			}
+			emit("?")
+		case sqlWordByte(c):
+			end := sqlWordEnd(query, i)
+			emit(strings.ToLower(query[i:end]))
+			i = end
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == ':' && strings.HasPrefix(query[i:], "::"):
+			i += 2
+			emit("::")
+		default:
+			i++
+			emit(string(c))
+		}
+	}
+
+	fingerprint := sqlValueList.ReplaceAllString(b.String(), "?")
+	return sqlRowList.ReplaceAllString(fingerprint, "(?)")
+}
+
+// sqlTightBefore сообщает, пишется ли token без пробела перед ним.
+func sqlTightBefore(token string) bool {
+	return token == "," || token == ")" || token == "." || token == "::"
+}
+
+// sqlTightAfter сообщает, пишется ли следующий за token токен без пробела.
+func sqlTightAfter(token string) bool {
+	return token == "(" || token == "." || token == "::"
+}
+
+func sqlWordByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+func sqlWordEnd(query string, i int) int {
+	for i < len(query) && sqlWordByte(query[i]) {
+		i++
+	}
+	return i
+}
+
+// sqlQuoteEnd возвращает позицию после строки или имени в кавычках quote,
+// начинающихся с позиции start. Удвоенная кавычка внутри не завершает строку.
+func sqlQuoteEnd(query string, start int, quote byte) int {
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if quote == '\'' {
+				i++
+			}
+		case quote:
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(query)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLFingerprint(t *testing.T) {
+	tests := map[string]string{
+		"SELECT * FROM users WHERE id = 42":                        "select * from users where id = ?",
+		"select *\n  from users\twhere id=$1":                      "select * from users where id = ?",
+		"SELECT * FROM users WHERE id IN (1, 2, 3)":                "select * from users where id in (?)",
+		"SELECT * FROM users WHERE name = 'O''Brien' AND a > 1.5":  "select * from users where name = ? and a > ?",
+		"INSERT INTO t (a, b) VALUES (?, ?), (?, ?)":               "insert into t (a, b) values (?)",
+		"INSERT INTO t (a) VALUES (1), (2), (3)":                   "insert into t (a) values (?)",
+		"SELECT \"User Id\" FROM t -- comment\nWHERE x = :x":       "select \"User Id\" from t where x = ?",
+		"/* report */ SELECT price::numeric FROM t WHERE id = @p1": "select price::numeric from t where id = ?",
+		"SELECT `name` FROM t t1 LIMIT 10":                         "select `name` from t t1 limit ?",
+		"":                                                         "",
+	}
+	for query, want := range tests {
+		assert.Equal(t, want, SQLFingerprint(query), query)
+	}
+}
+
+func TestSQLKeys(t *testing.T) {
+	query := "SELECT * FROM users WHERE id = 1"
+	ctx := context.Background()
+	labeled := WithSQLLabel(ctx, "users.get")
+
+	assert.Equal(t, "database", SQLDatabaseKey(labeled, query))
+	assert.Equal(t, "database", SQLLabelKey(ctx, query))
+	assert.Equal(t, "users.get", SQLLabelKey(labeled, query))
+	assert.Equal(t, "select * from users where id = ?", SQLFingerprintKey(ctx, query))
+	assert.Equal(t, "users.get", SQLFingerprintKey(labeled, query))
+	assert.Equal(t, "database", SQLLabelKey(WithSQLLabel(ctx, ""), query))
+
+	label, ok := SQLLabel(labeled)
+	assert.True(t, ok)
+	assert.Equal(t, "users.get", label)
+}
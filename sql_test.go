@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSQLConnector - тестовая база: запросы, содержащие "broken", завершаются
+// ошибкой, остальные выполняются успешно без строк результата.
+type testSQLConnector struct {
+	calls atomic.Int32
+}
+
+func (c *testSQLConnector) Connect(context.Context) (driver.Conn, error) {
+	return &testSQLConn{connector: c}, nil
+}
+
+func (c *testSQLConnector) Driver() driver.Driver {
+	return nil
+}
+
+func (c *testSQLConnector) run(query string) error {
+	c.calls.Add(1)
+	if strings.Contains(query, "broken") {
+		return errors.New("relation does not exist")
+	}
+	return nil
+}
+
+type testSQLConn struct {
+	connector *testSQLConnector
+}
+
+func (c *testSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return &testSQLStmt{query: query, connector: c.connector}, nil
+}
+
+func (c *testSQLConn) Close() error {
+	return nil
+}
+
+func (c *testSQLConn) Begin() (driver.Tx, error) {
+	return testSQLTx{}, nil
+}
+
+func (c *testSQLConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if err := c.connector.run(query); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (c *testSQLConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if err := c.connector.run(query); err != nil {
+		return nil, err
+	}
+	return testSQLRows{}, nil
+}
+
+type testSQLStmt struct {
+	query     string
+	connector *testSQLConnector
+}
+
+func (s *testSQLStmt) Close() error {
+	return nil
+}
+
+func (s *testSQLStmt) NumInput() int {
+	return -1
+}
+
+func (s *testSQLStmt) Exec([]driver.Value) (driver.Result, error) {
+	if err := s.connector.run(s.query); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *testSQLStmt) Query([]driver.Value) (driver.Rows, error) {
+	if err := s.connector.run(s.query); err != nil {
+		return nil, err
+	}
+	return testSQLRows{}, nil
+}
+
+type testSQLTx struct{}
+
+func (testSQLTx) Commit() error {
+	return nil
+}
+
+func (testSQLTx) Rollback() error {
+	return nil
+}
+
+type testSQLRows struct{}
+
+func (testSQLRows) Columns() []string {
+	return nil
+}
+
+func (testSQLRows) Close() error {
+	return nil
+}
+
+func (testSQLRows) Next([]driver.Value) error {
+	return io.EOF
+}
+
+func testSQLDB(t *testing.T, group *Group, options ...SQLOption) (*sql.DB, *testSQLConnector) {
+	connector := &testSQLConnector{}
+	db := sql.OpenDB(NewSQLConnector(connector, group, options...))
+	t.Cleanup(func() { _ = db.Close() })
+	return db, connector
+}
+
+func TestSQLConnector(t *testing.T) {
+	group := tripOnFirstFailure()
+	db, connector := testSQLDB(t, group)
+	ctx := context.Background()
+
+	_, err := db.ExecContext(ctx, "UPDATE users SET name = $1", "alice")
+	require.NoError(t, err)
+	rows, err := db.QueryContext(ctx, "SELECT * FROM users")
+	require.NoError(t, err)
+	require.NoError(t, rows.Close())
+
+	cb := group.Get("database")
+	assert.Equal(t, uint32(2), cb.Counts().TotalSuccess)
+
+	_, err = db.ExecContext(ctx, "SELECT * FROM broken")
+	require.EqualError(t, err, "relation does not exist")
+	assert.Equal(t, StateOpen, cb.State())
+
+	_, err = db.QueryContext(ctx, "SELECT * FROM users")
+	assert.ErrorIs(t, err, ErrOpenState)
+	assert.EqualError(t, err, "circuit breaker database: "+ErrOpenState.Error())
+	_, err = db.BeginTx(ctx, nil)
+	assert.ErrorIs(t, err, ErrOpenState)
+	assert.NoError(t, db.PingContext(ctx))
+	assert.Equal(t, int32(3), connector.calls.Load())
+}
+
+func TestSQLConnector_FingerprintKey(t *testing.T) {
+	group := tripOnFirstFailure()
+	db, _ := testSQLDB(t, group, WithSQLKey(SQLFingerprintKey))
+	ctx := context.Background()
+
+	_, err := db.ExecContext(ctx, "SELECT * FROM broken WHERE id = 1")
+	require.Error(t, err)
+	_, err = db.ExecContext(ctx, "SELECT * FROM broken WHERE id = 2")
+	assert.ErrorIs(t, err, ErrOpenState)
+	assert.Equal(t, StateOpen, group.Get("select * from broken where id = ?").State())
+
+	_, err = db.ExecContext(ctx, "SELECT * FROM users WHERE id = 1")
+	assert.NoError(t, err)
+
+	labeled := WithSQLLabel(ctx, "users.broken")
+	_, err = db.ExecContext(labeled, "SELECT * FROM broken WHERE id = 3")
+	require.EqualError(t, err, "relation does not exist")
+	assert.Equal(t, StateOpen, group.Get("users.broken").State())
+}
+
+func TestSQLConnector_PreparedStatement(t *testing.T) {
+	group := tripOnFirstFailure()
+	db, connector := testSQLDB(t, group, WithSQLKey(SQLFingerprintKey))
+	ctx := context.Background()
+
+	stmt, err := db.PrepareContext(ctx, "INSERT INTO broken VALUES (1)")
+	require.NoError(t, err)
+	defer stmt.Close()
+
+	_, err = stmt.ExecContext(ctx)
+	require.EqualError(t, err, "relation does not exist")
+	_, err = stmt.QueryContext(ctx)
+	assert.ErrorIs(t, err, ErrOpenState)
+	assert.Equal(t, int32(1), connector.calls.Load())
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit())
+}
+
+func TestSQLConnector_FailurePolicy(t *testing.T) {
+	group := tripOnFirstFailure()
+	db, _ := testSQLDB(t, group, WithSQLFailurePolicy(func(err error) bool {
+		return errors.Is(err, driver.ErrBadConn)
+	}))
+
+	_, err := db.ExecContext(context.Background(), "SELECT * FROM broken")
+	require.Error(t, err)
+	assert.Equal(t, StateClosed, group.Get("database").State())
+}
+
+func TestDefaultSQLFailurePolicy(t *testing.T) {
+	assert.True(t, DefaultSQLFailurePolicy(errors.New("connection reset")))
+	assert.True(t, DefaultSQLFailurePolicy(context.DeadlineExceeded))
+	assert.False(t, DefaultSQLFailurePolicy(context.Canceled))
+	assert.False(t, DefaultSQLFailurePolicy(driver.ErrSkip))
+}
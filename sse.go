@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// eventStreamBuffer - кол-во событий, ожидающих записи в соединение.
+	// События сверх буфера отбрасываются.
+	eventStreamBuffer = 64
+	// eventStreamKeepAlive - интервал комментариев, не дающих прокси закрыть соединение.
+	eventStreamKeepAlive = 15 * time.Second
+)
+
+// EventStreamHandler возвращает http.Handler, передающий события bus
+// в формате Server-Sent Events. Каждое событие записывается как
+// "event: <тип>" и "data: <JSON>". Параметры запроса type и name
+// (могут повторяться) отбирают события по типу и имени Circuit Breaker.
+// По умолчанию передаются только смены состояния.
+func EventStreamHandler(bus *EventBus) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		query := req.URL.Query()
+		types := []EventType{EventStateChanged}
+		if names := query["type"]; len(names) > 0 {
+			types = types[:0]
+			for _, name := range names {
+				t, ok := parseEventType(name)
+				if !ok {
+					http.Error(w, fmt.Sprintf("unknown event type %q", name), http.StatusBadRequest)
+					return
+				}
+				types = append(types, t)
+			}
+		}
+		breakers := make(map[string]bool)
+		for _, name := range query["name"] {
+			breakers[name] = true
+		}
+
+		events := make(chan Event, eventStreamBuffer)
+		unsubscribe := bus.Subscribe(func(event Event) error {
+			if len(breakers) > 0 && !breakers[event.Name] {
+				return nil
+			}
+			select {
+			case events <- event:
+			default:
+			}
+			return nil
+		}, eventStreamBuffer, types...)
+		defer unsubscribe()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		keepAlive := time.NewTicker(eventStreamKeepAlive)
+		defer keepAlive.Stop()
+
+		for {
+			select {
+			case <-req.Context().Done():
+				return
+			case <-keepAlive.C:
+				if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+					return
+				}
+			case event := <-events:
+				data, err := json.Marshal(newEventJSON(event))
+				if err != nil {
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+					return
+				}
+			}
+			flusher.Flush()
+		}
+	})
+}
+
+// parseEventType возвращает тип события по его имени, см. EventType.String.
+func parseEventType(name string) (EventType, bool) {
+	for t := EventAdmitted; t <= EventStateChanged; t++ {
+		if t.String() == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readEvent читает одно событие Server-Sent Events.
+func readEvent(t *testing.T, r *bufio.Reader) (string, map[string]any) {
+	var (
+		eventType string
+		data      map[string]any
+	)
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && eventType != "":
+			return eventType, data
+		case strings.HasPrefix(line, "event: "):
+			eventType = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &data))
+		}
+	}
+}
+
+func TestEventStreamHandler(t *testing.T) {
+	bus := NewEventBus(nil)
+	server := httptest.NewServer(EventStreamHandler(bus))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"?type=state-changed&type=rejected&name=payments", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	payments := NewCircuitBreaker(
+		WithName("payments"),
+		WithEventBus(bus),
+		WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures > 0 }),
+	)
+	search := NewCircuitBreaker(WithName("search"), WithEventBus(bus))
+	search.Trip()
+	assert.NotNil(t, fail(payments))
+	assert.Equal(t, ErrOpenState, succeed(payments))
+
+	body := bufio.NewReader(resp.Body)
+	eventType, data := readEvent(t, body)
+	assert.Equal(t, "state-changed", eventType)
+	assert.Equal(t, "payments", data["name"])
+	assert.Equal(t, "open", data["change"].(map[string]any)["to"])
+	assert.Equal(t, "fail", data["change"].(map[string]any)["error"])
+
+	eventType, data = readEvent(t, body)
+	assert.Equal(t, "rejected", eventType)
+	assert.Equal(t, "state is open", data["error"])
+}
+
+func TestEventStreamHandler_UnknownType(t *testing.T) {
+	rec := httptest.NewRecorder()
+	EventStreamHandler(NewEventBus(nil)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/?type=bogus", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
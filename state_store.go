@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// StateStore сохраняет состояние Circuit Breaker между перезапусками сервиса.
+// Ключом служит Path Circuit Breaker.
+type StateStore interface {
+	// Load возвращает сохраненное состояние. ok = false, если состояния нет.
+	Load(name string) (snapshot Snapshot, ok bool, err error)
+	Save(name string, snapshot Snapshot) error
+}
+
+// ContextStateStore - StateStore, сохранение в котором ограничено контекстом,
+// например сетевое хранилище. PersistContext и Close передают ему свой ctx.
+type ContextStateStore interface {
+	StateStore
+	SaveContext(ctx context.Context, name string, snapshot Snapshot) error
+}
+
+// WithStateStore загружает состояние из store при создании Circuit Breaker
+// и сохраняет его при каждой смене состояния. Сохранение выполняется в фоне,
+// при частых переходах сохраняется только последнее состояние.
+// Ошибки загрузки и сохранения передаются в onError, если он задан.
+func WithStateStore(store StateStore, onError func(err error)) Option {
+	return func(s *settings) {
+		s.stateStore = store
+		s.onStateStoreError = onError
+	}
+}
+
+// persister сохраняет состояние в фоне. Горутина сохранения запускается,
+// когда появляется новое состояние, и завершается, когда сохранять нечего.
+type persister struct {
+	mu      sync.Mutex
+	pending *Snapshot
+	// Закрывается при завершении горутины сохранения. nil, если горутина не запущена.
+	done chan struct{}
+}
+
+// loadState восстанавливает состояние из StateStore при создании Circuit Breaker.
+func (cb *CircuitBreaker) loadState() {
+	s := cb.config()
+	if s.stateStore == nil {
+		return
+	}
+
+	snapshot, ok, err := s.stateStore.Load(cb.Path())
+	if err != nil {
+		cb.stateStoreError(err)
+		return
+	}
+	if ok {
+		cb.Restore(snapshot)
+	}
+}
+
+// Persist синхронно сохраняет текущее состояние, например перед остановкой сервиса.
+func (cb *CircuitBreaker) Persist() error {
+	return cb.PersistContext(context.Background())
+}
+
+// PersistContext - Persist, ожидание и сохранение которого ограничены ctx.
+// Если ctx истекает раньше, возвращает ошибку ctx.
+func (cb *CircuitBreaker) PersistContext(ctx context.Context) error {
+	store := cb.config().stateStore
+	if store == nil {
+		return nil
+	}
+
+	if err := cb.waitPersisted(ctx); err != nil {
+		return err
+	}
+
+	if store, ok := store.(ContextStateStore); ok {
+		return store.SaveContext(ctx, cb.Path(), cb.Snapshot())
+	}
+	return store.Save(cb.Path(), cb.Snapshot())
+}
+
+// persistState ставит состояние в очередь на сохранение. Вызывается под блокировкой cb.mu.
+func (cb *CircuitBreaker) persistState() {
+	if cb.config().stateStore == nil {
+		return
+	}
+
+	current := cb.current.Load()
+	snapshot := Snapshot{
+		State:      current.state,
+		Counts:     cb.counts.snapshot(),
+		Expiry:     current.expiry,
+		Generation: current.generation,
+	}
+
+	p := &cb.persister
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.pending = &snapshot
+	if p.done == nil {
+		p.done = make(chan struct{})
+		go cb.savePending()
+	}
+}
+
+func (cb *CircuitBreaker) savePending() {
+	p := &cb.persister
+
+	for {
+		p.mu.Lock()
+		snapshot := p.pending
+		p.pending = nil
+		if snapshot == nil {
+			close(p.done)
+			p.done = nil
+			p.mu.Unlock()
+			return
+		}
+		p.mu.Unlock()
+
+		if err := cb.config().stateStore.Save(cb.Path(), *snapshot); err != nil {
+			cb.stateStoreError(err)
+		}
+	}
+}
+
+// waitPersisted дожидается завершения фонового сохранения.
+func (cb *CircuitBreaker) waitPersisted(ctx context.Context) error {
+	p := &cb.persister
+	p.mu.Lock()
+	done := p.done
+	p.mu.Unlock()
+
+	if done == nil {
+		return nil
+	}
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (cb *CircuitBreaker) stateStoreError(err error) {
+	if onError := cb.config().onStateStoreError; onError != nil {
+		onError(err)
+	}
+}
+
+// FileStateStore хранит состояние каждого Circuit Breaker в отдельном JSON-файле каталога.
+type FileStateStore struct {
+	dir string
+}
+
+func NewFileStateStore(dir string) (*FileStateStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStateStore{dir: dir}, nil
+}
+
+func (s *FileStateStore) Load(name string) (Snapshot, bool, error) {
+	data, err := os.ReadFile(s.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Snapshot{}, false, nil
+	}
+	if err != nil {
+		return Snapshot{}, false, err
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return Snapshot{}, false, err
+	}
+
+	return snapshot, true, nil
+}
+
+// Save записывает состояние во временный файл и атомарно переименовывает его,
+// чтобы при сбое не остался частично записанный файл.
+func (s *FileStateStore) Save(name string, snapshot Snapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path(name))
+}
+
+func (s *FileStateStore) path(name string) string {
+	return filepath.Join(s.dir, url.PathEscape(name)+".json")
+}
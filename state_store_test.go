@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStateStore(t *testing.T) {
+	store, err := NewFileStateStore(filepath.Join(t.TempDir(), "state"))
+	require.NoError(t, err)
+
+	_, ok, err := store.Load("payments/charge")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	snapshot := Snapshot{State: StateOpen, Counts: Counts{1, 0, 1, 0, 1}, Generation: 3}
+	require.NoError(t, store.Save("payments/charge", snapshot))
+
+	loaded, ok, err := store.Load("payments/charge")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, snapshot, loaded)
+}
+
+func TestCircuitBreaker_StateStore(t *testing.T) {
+	store, err := NewFileStateStore(t.TempDir())
+	require.NoError(t, err)
+
+	newBreaker := func() *CircuitBreaker {
+		return NewCircuitBreaker(
+			WithName("payments"),
+			WithTimeout(time.Hour),
+			WithStateStore(store, func(err error) {
+				t.Errorf("unexpected state store error: %v", err)
+			}),
+		)
+	}
+
+	cb := newBreaker()
+	cb.trip()
+	require.NoError(t, cb.waitPersisted(context.Background()))
+
+	// после перезапуска Circuit Breaker остается в Open
+	restarted := newBreaker()
+	assert.Equal(t, StateOpen, restarted.State())
+	assert.ErrorIs(t, succeed(restarted), ErrOpenState)
+
+	restarted.mu.Lock()
+	restarted.setState(StateClosed)
+	restarted.mu.Unlock()
+	assert.Nil(t, succeed(restarted))
+	require.NoError(t, restarted.Persist())
+
+	snapshot, ok, err := store.Load("payments")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, StateClosed, snapshot.State)
+	assert.Equal(t, Counts{1, 1, 0, 1, 0}, snapshot.Counts)
+}
+
+func TestCircuitBreaker_StateStoreLoadError(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "payments.json"), []byte("{"), 0o644))
+	store, err := NewFileStateStore(dir)
+	require.NoError(t, err)
+
+	var loadErr error
+	cb := NewCircuitBreaker(WithName("payments"), WithStateStore(store, func(err error) {
+		loadErr = err
+	}))
+
+	assert.Error(t, loadErr)
+	assert.False(t, errors.Is(loadErr, os.ErrNotExist))
+	assert.Equal(t, StateClosed, cb.State())
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"maps"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type totals struct {
+	rejections  atomic.Uint64
+	successes   atomic.Uint64
+	failures    atomic.Uint64
+	lastFailure lastFailure
+	errors      errorSamples
+
+	trips      atomic.Uint64
+	reopens    atomic.Uint64
+	recoveries atomic.Uint64
+}
+
+// countTransition учитывает переход from -> to в счетчиках TransitionCounts.
+func (t *totals) countTransition(from, to State) {
+	switch {
+	case from == StateClosed && to == StateOpen:
+		t.trips.Add(1)
+	case from == StateHalfOpen && to == StateOpen:
+		t.reopens.Add(1)
+	case from == StateHalfOpen && to == StateClosed:
+		t.recoveries.Add(1)
+	}
+}
+
+// TransitionCounts - кол-во переходов Circuit Breaker за все время.
+type TransitionCounts struct {
+	// Closed -> Open.
+	Trips uint64
+	// Half-Open -> Open.
+	Reopens uint64
+	// Half-Open -> Closed.
+	Recoveries uint64
+}
+
+// lastFailure - последний неуспешный запрос. Хранится по значению,
+// чтобы запись ошибки не выделяла память.
+type lastFailure struct {
+	mu  sync.Mutex
+	err error
+	at  time.Time
+}
+
+func (f *lastFailure) store(err error, at time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.err, f.at = err, at
+}
+
+func (f *lastFailure) load() (error, time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.err, f.at
+}
+
+// Stats - статистика одного Circuit Breaker.
+// Кодируется в JSON по схеме версии StatsVersion, см. MarshalJSON.
+type Stats struct {
+	Name   string
+	Labels map[string]string
+	State  State
+	// Время последней смены состояния.
+	Since  time.Time
+	Counts Counts
+	// Кол-во запросов, отклоненных Circuit Breaker, за все время.
+	Rejections uint64
+	// Кол-во успешных и неуспешных запросов за все время.
+	Successes   uint64
+	Failures    uint64
+	Transitions TransitionCounts
+	// Ошибка и время последнего неуспешного запроса.
+	LastError     error
+	LastFailureAt time.Time
+	// Самые частые классы ошибок последних неуспешных запросов.
+	TopErrors []ErrorClass
+	// Длительности последних запросов, если включен WithLatencyStats.
+	Latency Latency
+	Config  StatsConfig
+}
+
+// StatsConfig - основные настройки Circuit Breaker в Stats.
+type StatsConfig struct {
+	MaxRequests          uint32
+	Timeout              time.Duration
+	MinOpenDuration      time.Duration
+	MaxOpenDuration      time.Duration
+	HealthyResetInterval time.Duration
+	WarmupPeriod         time.Duration
+}
+
+func (cb *CircuitBreaker) Stats() Stats {
+	s := cb.config()
+	current := cb.current.Load()
+	stats := Stats{
+		Name:       cb.Path(),
+		Labels:     maps.Clone(s.labels),
+		State:      current.state,
+		Since:      current.since,
+		Counts:     cb.Counts(),
+		Rejections: cb.totals.rejections.Load(),
+		Successes:  cb.totals.successes.Load(),
+		Failures:   cb.totals.failures.Load(),
+		Transitions: TransitionCounts{
+			Trips:      cb.totals.trips.Load(),
+			Reopens:    cb.totals.reopens.Load(),
+			Recoveries: cb.totals.recoveries.Load(),
+		},
+		TopErrors: cb.totals.errors.top(topErrorsLimit),
+		Latency:   cb.latency.summary(),
+		Config: StatsConfig{
+			MaxRequests:          s.maxRequests,
+			Timeout:              s.timeout,
+			MinOpenDuration:      s.minOpenDuration,
+			MaxOpenDuration:      s.maxOpenDuration,
+			HealthyResetInterval: s.healthyResetInterval,
+			WarmupPeriod:         s.warmupPeriod,
+		},
+	}
+	stats.LastError, stats.LastFailureAt = cb.totals.lastFailure.load()
+	return stats
+}
+
+// LastError возвращает ошибку последнего неуспешного запроса или nil.
+func (cb *CircuitBreaker) LastError() error {
+	err, _ := cb.totals.lastFailure.load()
+	return err
+}
+
+// LastFailureAt возвращает время последнего неуспешного запроса
+// или нулевое время, если неуспешных запросов не было.
+func (cb *CircuitBreaker) LastFailureAt() time.Time {
+	_, at := cb.totals.lastFailure.load()
+	return at
+}
+
+// topFailuresLimit - кол-во Circuit Breaker с наибольшим числом ошибок в RegistryStats.
+const topFailuresLimit = 5
+
+// RegistryStats - сводная статистика всех Circuit Breaker реестра.
+type RegistryStats struct {
+	Total    int
+	Closed   int
+	Open     int
+	HalfOpen int
+	// Кол-во отклоненных запросов по всем Circuit Breaker.
+	Rejections uint64
+	// Circuit Breaker с наибольшим числом ошибок, по убыванию.
+	TopFailures []Stats
+	// Все Circuit Breaker в порядке имен.
+	Breakers []Stats
+}
+
+func (r *Registry) Stats() RegistryStats {
+	r.mu.RLock()
+	all := make([]Stats, 0, len(r.breakers))
+	for _, cb := range r.breakers {
+		all = append(all, cb.Stats())
+	}
+	r.mu.RUnlock()
+
+	stats := RegistryStats{Total: len(all)}
+	for _, s := range all {
+		switch s.State {
+		case StateClosed:
+			stats.Closed++
+		case StateOpen:
+			stats.Open++
+		case StateHalfOpen:
+			stats.HalfOpen++
+		}
+		stats.Rejections += s.Rejections
+	}
+
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	stats.Breakers = append([]Stats(nil), all...)
+
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].Failures > all[j].Failures
+	})
+	for _, s := range all {
+		if len(stats.TopFailures) == topFailuresLimit || s.Failures == 0 {
+			break
+		}
+		stats.TopFailures = append(stats.TopFailures, s)
+	}
+
+	return stats
+}
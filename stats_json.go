@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// StatsVersion - версия JSON-схемы Stats и RegistryStats. Увеличивается
+// при несовместимом изменении схемы, новые поля добавляются без смены версии.
+const StatsVersion = 1
+
+type statsJSON struct {
+	Version int               `json:"version"`
+	Name    string            `json:"name"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	State   string            `json:"state"`
+	Since   time.Time         `json:"since"`
+	Counts  countsJSON        `json:"counts"`
+	// Доли успешных и неуспешных запросов в Counts.
+	SuccessRate   float64          `json:"success_rate"`
+	FailureRate   float64          `json:"failure_rate"`
+	Rejections    uint64           `json:"rejections_total"`
+	Successes     uint64           `json:"successes_total"`
+	Failures      uint64           `json:"failures_total"`
+	Transitions   transitionsJSON  `json:"transitions"`
+	LastError     string           `json:"last_error,omitempty"`
+	LastFailureAt *time.Time       `json:"last_failure_at,omitempty"`
+	TopErrors     []errorClassJSON `json:"top_errors,omitempty"`
+	Latency       *latencyJSON     `json:"latency,omitempty"`
+	Config        statsConfigJSON  `json:"config"`
+}
+
+type countsJSON struct {
+	Requests             uint32 `json:"requests"`
+	TotalSuccess         uint32 `json:"total_success"`
+	TotalFailures        uint32 `json:"total_failures"`
+	ConsecutiveSuccesses uint32 `json:"consecutive_successes"`
+	ConsecutiveFailures  uint32 `json:"consecutive_failures"`
+}
+
+type transitionsJSON struct {
+	Trips      uint64 `json:"trips_total"`
+	Reopens    uint64 `json:"reopens_total"`
+	Recoveries uint64 `json:"recoveries_total"`
+}
+
+type errorClassJSON struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	Count   int    `json:"count"`
+}
+
+// latencyJSON - длительности в миллисекундах.
+type latencyJSON struct {
+	Samples int     `json:"samples"`
+	Mean    float64 `json:"mean_ms"`
+	P50     float64 `json:"p50_ms"`
+	P90     float64 `json:"p90_ms"`
+	P99     float64 `json:"p99_ms"`
+	Max     float64 `json:"max_ms"`
+}
+
+// statsConfigJSON - длительности в формате time.Duration.String, например "1m30s".
+type statsConfigJSON struct {
+	MaxRequests          uint32 `json:"max_requests"`
+	Timeout              string `json:"timeout"`
+	MinOpenDuration      string `json:"min_open_duration"`
+	MaxOpenDuration      string `json:"max_open_duration"`
+	HealthyResetInterval string `json:"healthy_reset_interval"`
+	WarmupPeriod         string `json:"warmup_period"`
+}
+
+type stateChangeJSON struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	From   string            `json:"from"`
+	To     string            `json:"to"`
+	At     time.Time         `json:"at"`
+	Reason string            `json:"reason,omitempty"`
+	Error  string            `json:"error,omitempty"`
+	Counts countsJSON        `json:"counts"`
+}
+
+func newStateChangeJSON(change StateChange) stateChangeJSON {
+	v := stateChangeJSON{
+		Name:   change.Name,
+		Labels: change.Labels,
+		From:   change.From.String(),
+		To:     change.To.String(),
+		At:     change.At,
+		Reason: string(change.Reason),
+		Counts: countsJSON(change.Counts),
+	}
+	if change.Err != nil {
+		v.Error = change.Err.Error()
+	}
+	return v
+}
+
+type registryStatsJSON struct {
+	Version     int         `json:"version"`
+	Total       int         `json:"total"`
+	Closed      int         `json:"closed"`
+	Open        int         `json:"open"`
+	HalfOpen    int         `json:"half_open"`
+	Rejections  uint64      `json:"rejections_total"`
+	TopFailures []statsJSON `json:"top_failures"`
+	Breakers    []statsJSON `json:"breakers"`
+}
+
+// MarshalJSON кодирует Stats по схеме версии StatsVersion с именами полей
+// в snake_case. Поля ошибок и длительностей опускаются, пока нет данных.
+func (s Stats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.toJSON())
+}
+
+func (s Stats) toJSON() statsJSON {
+	v := statsJSON{
+		Version:     StatsVersion,
+		Name:        s.Name,
+		Labels:      s.Labels,
+		State:       s.State.String(),
+		Since:       s.Since,
+		Counts:      countsJSON(s.Counts),
+		Rejections:  s.Rejections,
+		Successes:   s.Successes,
+		Failures:    s.Failures,
+		Transitions: transitionsJSON(s.Transitions),
+		Config: statsConfigJSON{
+			MaxRequests:          s.Config.MaxRequests,
+			Timeout:              s.Config.Timeout.String(),
+			MinOpenDuration:      s.Config.MinOpenDuration.String(),
+			MaxOpenDuration:      s.Config.MaxOpenDuration.String(),
+			HealthyResetInterval: s.Config.HealthyResetInterval.String(),
+			WarmupPeriod:         s.Config.WarmupPeriod.String(),
+		},
+	}
+	if s.Counts.Requests > 0 {
+		v.SuccessRate = float64(s.Counts.TotalSuccess) / float64(s.Counts.Requests)
+		v.FailureRate = float64(s.Counts.TotalFailures) / float64(s.Counts.Requests)
+	}
+	if s.LastError != nil {
+		v.LastError = s.LastError.Error()
+	}
+	if !s.LastFailureAt.IsZero() {
+		v.LastFailureAt = &s.LastFailureAt
+	}
+	for _, class := range s.TopErrors {
+		v.TopErrors = append(v.TopErrors, errorClassJSON(class))
+	}
+	if s.Latency.Samples > 0 {
+		v.Latency = &latencyJSON{
+			Samples: s.Latency.Samples,
+			Mean:    milliseconds(s.Latency.Mean),
+			P50:     milliseconds(s.Latency.P50),
+			P90:     milliseconds(s.Latency.P90),
+			P99:     milliseconds(s.Latency.P99),
+			Max:     milliseconds(s.Latency.Max),
+		}
+	}
+	return v
+}
+
+// MarshalJSON кодирует RegistryStats по схеме версии StatsVersion.
+func (s RegistryStats) MarshalJSON() ([]byte, error) {
+	v := registryStatsJSON{
+		Version:     StatsVersion,
+		Total:       s.Total,
+		Closed:      s.Closed,
+		Open:        s.Open,
+		HalfOpen:    s.HalfOpen,
+		Rejections:  s.Rejections,
+		TopFailures: make([]statsJSON, 0, len(s.TopFailures)),
+		Breakers:    make([]statsJSON, 0, len(s.Breakers)),
+	}
+	for _, stats := range s.TopFailures {
+		v.TopFailures = append(v.TopFailures, stats.toJSON())
+	}
+	for _, stats := range s.Breakers {
+		v.Breakers = append(v.Breakers, stats.toJSON())
+	}
+	return json.Marshal(v)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats_MarshalJSON(t *testing.T) {
+	clock := clocktest.New(time.Date(2024, 3, 1, 3, 0, 0, 0, time.UTC))
+	cb := NewCircuitBreaker(
+		WithName("payments"),
+		WithLabels(map[string]string{"team": "billing"}),
+		WithClock(clock),
+		WithTimeout(30*time.Second),
+		WithMaxRequests(2),
+		WithMinOpenDuration(time.Second),
+		WithMaxOpenDuration(time.Minute),
+		WithLatencyStats(),
+	)
+	for _, d := range []time.Duration{10, 20, 30, 40} {
+		_, err := cb.Execute(func() (interface{}, error) {
+			clock.Advance(d * time.Millisecond)
+			return nil, nil
+		})
+		require.NoError(t, err)
+	}
+	assert.NotNil(t, fail(cb))
+
+	data, err := json.Marshal(cb.Stats())
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"version": 1, "name": "payments", "labels": {"team": "billing"},
+		"state": "closed", "since": "2024-03-01T03:00:00Z",
+		"counts": {"requests": 5, "total_success": 4, "total_failures": 1, "consecutive_successes": 0, "consecutive_failures": 1},
+		"success_rate": 0.8, "failure_rate": 0.2, "rejections_total": 0,
+		"successes_total": 4, "failures_total": 1,
+		"transitions": {"trips_total": 0, "reopens_total": 0, "recoveries_total": 0},
+		"last_error": "fail", "last_failure_at": "2024-03-01T03:00:00.1Z",
+		"top_errors": [{"type": "*errors.errorString", "message": "fail", "count": 1}],
+		"latency": {"samples": 5, "mean_ms": 20, "p50_ms": 20, "p90_ms": 30, "p99_ms": 30, "max_ms": 40},
+		"config": {
+			"max_requests": 2, "timeout": "30s", "min_open_duration": "1s", "max_open_duration": "1m0s",
+			"healthy_reset_interval": "0s", "warmup_period": "0s"
+		}
+	}`, string(data))
+}
+
+func TestRegistryStats_MarshalJSON(t *testing.T) {
+	r := NewRegistry()
+	r.Get("search")
+	r.Get("payments").trip()
+	assert.NotNil(t, fail(r.Get("orders")))
+
+	data, err := json.Marshal(r.Stats())
+	require.NoError(t, err)
+
+	var v struct {
+		Version     int `json:"version"`
+		Total       int `json:"total"`
+		Open        int `json:"open"`
+		TopFailures []struct {
+			Name string `json:"name"`
+		} `json:"top_failures"`
+		Breakers []struct {
+			Version int    `json:"version"`
+			Name    string `json:"name"`
+			State   string `json:"state"`
+		} `json:"breakers"`
+	}
+	require.NoError(t, json.Unmarshal(data, &v))
+	assert.Equal(t, StatsVersion, v.Version)
+	assert.Equal(t, 3, v.Total)
+	assert.Equal(t, 1, v.Open)
+	require.Len(t, v.TopFailures, 1)
+	assert.Equal(t, "orders", v.TopFailures[0].Name)
+	require.Len(t, v.Breakers, 3)
+	assert.Equal(t, "orders", v.Breakers[0].Name)
+	assert.Equal(t, "payments", v.Breakers[1].Name)
+	assert.Equal(t, "open", v.Breakers[1].State)
+	assert.Equal(t, StatsVersion, v.Breakers[2].Version)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Stats(t *testing.T) {
+	r := NewRegistry(WithDefaults(
+		WithTimeout(time.Hour),
+		WithReadyToTrip(func(counts Counts) bool {
+			return counts.ConsecutiveFailures >= 3
+		}),
+	))
+
+	payments := r.Get("payments")
+	for i := 0; i < 3; i++ {
+		assert.NotNil(t, fail(payments))
+	}
+	assert.ErrorIs(t, succeed(payments), ErrOpenState)
+	assert.ErrorIs(t, succeed(payments), ErrOpenState)
+
+	orders := r.Get("orders")
+	assert.NotNil(t, fail(orders))
+	assert.Nil(t, succeed(orders))
+
+	r.Get("users")
+
+	stats := r.Stats()
+	assert.Equal(t, 3, stats.Total)
+	assert.Equal(t, 2, stats.Closed)
+	assert.Equal(t, 1, stats.Open)
+	assert.Equal(t, 0, stats.HalfOpen)
+	assert.Equal(t, uint64(2), stats.Rejections)
+
+	assert.Len(t, stats.TopFailures, 2)
+	assert.Equal(t, "payments", stats.TopFailures[0].Name)
+	assert.Equal(t, uint64(3), stats.TopFailures[0].Failures)
+	assert.Equal(t, "orders", stats.TopFailures[1].Name)
+	assert.Equal(t, Counts{2, 1, 1, 1, 0}, stats.TopFailures[1].Counts)
+}
+
+func TestCircuitBreaker_LastError(t *testing.T) {
+	clock := clocktest.New(time.Date(2024, 3, 1, 3, 0, 0, 0, time.UTC))
+	cb := NewCircuitBreaker(WithClock(clock))
+	assert.Nil(t, cb.LastError())
+	assert.True(t, cb.LastFailureAt().IsZero())
+
+	assert.NotNil(t, fail(cb))
+	failedAt := clock.Now()
+	clock.Advance(time.Second)
+	assert.Nil(t, succeed(cb))
+
+	assert.EqualError(t, cb.LastError(), "fail")
+	assert.Equal(t, failedAt, cb.LastFailureAt())
+
+	stats := cb.Stats()
+	assert.EqualError(t, stats.LastError, "fail")
+	assert.Equal(t, failedAt, stats.LastFailureAt)
+}
+
+func TestCircuitBreaker_TransitionCounts(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cb := NewCircuitBreaker(WithTimeProvider(clock), WithMaxRequests(1))
+
+	cb.Trip()
+	clock.Advance(11 * time.Second)
+	assert.NotNil(t, fail(cb))
+	clock.Advance(11 * time.Second)
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, StateClosed, cb.State())
+
+	assert.Equal(t, TransitionCounts{Trips: 1, Reopens: 1, Recoveries: 1}, cb.Stats().Transitions)
+}
+
+func TestCircuitBreaker_StatsLabels(t *testing.T) {
+	cb := NewCircuitBreaker(WithLabels(map[string]string{"team": "billing"}))
+
+	// изменение меток Stats не меняет метки Circuit Breaker
+	cb.Stats().Labels["team"] = "search"
+	assert.Equal(t, map[string]string{"team": "billing"}, cb.Stats().Labels)
+}
+
+func TestWithLatencyStats_Repeated(t *testing.T) {
+	r := NewRegistry(WithDefaults(WithLatencyStats()))
+	cb := r.Get("payments", WithLatencyStats())
+	cb.UpdateConfig(WithLatencyStats())
+
+	assert.Nil(t, succeed(cb))
+	assert.Equal(t, 1, cb.Stats().Latency.Samples)
+}
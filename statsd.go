@@ -0,0 +1,282 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clock"
+)
+
+// StatsdSink отправляет метрики Circuit Breaker в формате StatsD с тегами
+// DogStatsD. Метрики накапливаются в очереди и отправляются фоновой горутиной
+// пакетами до WithStatsdMaxPacketSize байт, разделенными переводом строки:
+// при заполнении пакета или раз в WithStatsdFlushInterval. Запросы Circuit
+// Breaker не ждут записи; при переполнении очереди метрики отбрасываются, см.
+// Dropped. Ошибки записи игнорируются, как принято для StatsD.
+type StatsdSink struct {
+	w io.Writer
+	// Соединение DialStatsd, закрываемое Close.
+	conn io.Closer
+
+	prefix        string
+	tags          []string
+	sampleRate    float64
+	flushInterval time.Duration
+	maxPacketSize int
+	queueSize     int
+	clock         clock.Clock
+
+	packets   chan string
+	dropped   atomic.Uint64
+	closeOnce sync.Once
+	closeErr  error
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+type StatsdOption func(*StatsdSink)
+
+// WithStatsdPrefix задает префикс имен метрик. По умолчанию "circuit_breaker.".
+func WithStatsdPrefix(prefix string) StatsdOption {
+	return func(s *StatsdSink) {
+		s.prefix = prefix
+	}
+}
+
+// WithStatsdTags задает теги вида "key:value", добавляемые ко всем метрикам.
+func WithStatsdTags(tags ...string) StatsdOption {
+	return func(s *StatsdSink) {
+		s.tags = append(s.tags, tags...)
+	}
+}
+
+// WithStatsdSampleRate задает долю (0..1] отправляемых длительностей запросов
+// и отклонений. События смены состояния отправляются всегда.
+func WithStatsdSampleRate(rate float64) StatsdOption {
+	return func(s *StatsdSink) {
+		s.sampleRate = rate
+	}
+}
+
+// WithStatsdFlushInterval задает период отправки неполного пакета. По умолчанию 100ms.
+func WithStatsdFlushInterval(interval time.Duration) StatsdOption {
+	return func(s *StatsdSink) {
+		s.flushInterval = interval
+	}
+}
+
+// WithStatsdMaxPacketSize задает максимальный размер пакета в байтах.
+// Метрика длиннее size отправляется отдельным пакетом. По умолчанию 1432,
+// чтобы пакет UDP не фрагментировался при MTU 1500.
+func WithStatsdMaxPacketSize(size int) StatsdOption {
+	return func(s *StatsdSink) {
+		s.maxPacketSize = size
+	}
+}
+
+// WithStatsdQueueSize задает число метрик, ожидающих отправки. По умолчанию 4096.
+func WithStatsdQueueSize(size int) StatsdOption {
+	return func(s *StatsdSink) {
+		s.queueSize = size
+	}
+}
+
+// WithStatsdClock задает источник времени для периода отправки.
+func WithStatsdClock(c clock.Clock) StatsdOption {
+	return func(s *StatsdSink) {
+		s.clock = c
+	}
+}
+
+// NewStatsdSink создает StatsdSink, пишущий пакеты в w, и запускает отправку.
+// Отправка останавливается через Close.
+func NewStatsdSink(w io.Writer, options ...StatsdOption) (*StatsdSink, error) {
+	s := &StatsdSink{
+		w:             w,
+		prefix:        "circuit_breaker.",
+		sampleRate:    1,
+		flushInterval: 100 * time.Millisecond,
+		maxPacketSize: 1432,
+		queueSize:     4096,
+		clock:         clock.Real{},
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	for _, opt := range options {
+		opt(s)
+	}
+	if !(s.sampleRate > 0 && s.sampleRate <= 1) {
+		return nil, fmt.Errorf("%w: statsd sample rate %v must be in (0, 1]", ErrInvalidConfig, s.sampleRate)
+	}
+	if s.flushInterval <= 0 || s.maxPacketSize <= 0 || s.queueSize < 0 {
+		return nil, fmt.Errorf("%w: statsd flush interval and packet size must be positive", ErrInvalidConfig)
+	}
+
+	s.packets = make(chan string, s.queueSize)
+	go s.run()
+	return s, nil
+}
+
+// DialStatsd создает StatsdSink, отправляющий метрики по UDP на addr.
+// Close закрывает соединение.
+func DialStatsd(addr string, options ...StatsdOption) (*StatsdSink, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s, err := NewStatsdSink(conn, options...)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	s.conn = conn
+	return s, nil
+}
+
+// Close отправляет метрики из очереди, останавливает отправку и закрывает
+// соединение DialStatsd. Метрики после Close отбрасываются.
+func (s *StatsdSink) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+		if s.conn != nil {
+			s.closeErr = s.conn.Close()
+		}
+	})
+	return s.closeErr
+}
+
+// Dropped возвращает кол-во метрик, отброшенных из-за переполнения очереди или после Close.
+func (s *StatsdSink) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// WithStatsd включает отправку метрик Circuit Breaker в sink:
+// длительности запросов по результату, кол-во отклоненных запросов по причине,
+// а также счетчик и событие DogStatsD для каждой смены состояния.
+func WithStatsd(sink *StatsdSink) Option {
+	return withObserver(sink)
+}
+
+func (s *StatsdSink) observeCall(cb *CircuitBreaker, record OutcomeRecord) {
+	if !s.sampled() {
+		return
+	}
+	ms := strconv.FormatFloat(float64(record.Duration.Microseconds())/1000, 'f', -1, 64)
+	s.send(s.prefix+"call.duration", ms+"|ms", true, "name:"+cb.Path(), "outcome:"+record.Outcome.String())
+}
+
+func (s *StatsdSink) observeRejection(cb *CircuitBreaker, err error) {
+	if !s.sampled() {
+		return
+	}
+	s.send(s.prefix+"rejections", "1|c", true, "name:"+cb.Path(), "reason:"+rejectionReason(err))
+}
+
+func (s *StatsdSink) observeTransition(cb *CircuitBreaker, change StateChange) {
+	tags := []string{"name:" + change.Name, "from:" + change.From.String(), "to:" + change.To.String()}
+	s.send(s.prefix+"transitions", "1|c", false, tags...)
+
+	title := "Circuit breaker " + change.Name + " is " + change.To.String()
+	text := "Changed state from " + change.From.String() + " to " + change.To.String()
+	s.write(fmt.Sprintf("_e{%d,%d}:%s|%s|t:%s%s", len(title), len(text), title, text, eventAlertType(change.To), s.formatTags(tags)))
+}
+
+func (s *StatsdSink) sampled() bool {
+	return s.sampleRate >= 1 || rand.Float64() < s.sampleRate
+}
+
+// send пишет метрику name со значением value вида "1|c".
+func (s *StatsdSink) send(name, value string, sampled bool, tags ...string) {
+	line := name + ":" + value
+	if sampled && s.sampleRate < 1 {
+		line += "|@" + strconv.FormatFloat(s.sampleRate, 'f', -1, 64)
+	}
+	s.write(line + s.formatTags(tags))
+}
+
+func (s *StatsdSink) formatTags(tags []string) string {
+	if len(s.tags)+len(tags) == 0 {
+		return ""
+	}
+	return "|#" + strings.Join(append(s.tags[:len(s.tags):len(s.tags)], tags...), ",")
+}
+
+// write ставит метрику в очередь, не блокируя вызывающего.
+func (s *StatsdSink) write(packet string) {
+	select {
+	case <-s.stop:
+		s.dropped.Add(1)
+		return
+	default:
+	}
+	select {
+	case s.packets <- packet:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+func (s *StatsdSink) run() {
+	defer close(s.done)
+
+	timer := s.clock.NewTimer(s.flushInterval)
+	defer timer.Stop()
+
+	var buf []byte
+	for {
+		select {
+		case packet := <-s.packets:
+			buf = s.add(buf, packet)
+		case <-timer.C():
+			buf = s.flush(buf)
+			timer.Reset(s.flushInterval)
+		case <-s.stop:
+			for {
+				select {
+				case packet := <-s.packets:
+					buf = s.add(buf, packet)
+				default:
+					s.flush(buf)
+					return
+				}
+			}
+		}
+	}
+}
+
+// add добавляет метрику в пакет buf, отправляя его, если метрика не помещается.
+func (s *StatsdSink) add(buf []byte, packet string) []byte {
+	if len(buf) > 0 && len(buf)+1+len(packet) > s.maxPacketSize {
+		buf = s.flush(buf)
+	}
+	if len(buf) > 0 {
+		buf = append(buf, '\n')
+	}
+	return append(buf, packet...)
+}
+
+func (s *StatsdSink) flush(buf []byte) []byte {
+	if len(buf) > 0 {
+		_, _ = s.w.Write(buf)
+	}
+	return buf[:0]
+}
+
+func eventAlertType(state State) string {
+	switch state {
+	case StateOpen:
+		return "error"
+	case StateHalfOpen:
+		return "warning"
+	default:
+		return "success"
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+)
+
+// packetWriter сохраняет каждую запись как отдельный пакет. Пока release
+// не закрыт, запись блокируется.
+type packetWriter struct {
+	mu      sync.Mutex
+	packets []string
+	release chan struct{}
+}
+
+func (w *packetWriter) Write(p []byte) (int, error) {
+	if w.release != nil {
+		<-w.release
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.packets = append(w.packets, string(p))
+	return len(p), nil
+}
+
+func (w *packetWriter) written() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.packets...)
+}
+
+// lines возвращает метрики всех пакетов.
+func (w *packetWriter) lines() []string {
+	var lines []string
+	for _, packet := range w.written() {
+		lines = append(lines, strings.Split(packet, "\n")...)
+	}
+	return lines
+}
+
+func TestWithStatsd(t *testing.T) {
+	w := &packetWriter{}
+	sink, err := NewStatsdSink(w, WithStatsdTags("env:test"), WithStatsdClock(clocktest.New(time.Now())))
+	require.NoError(t, err)
+	cb := NewCircuitBreaker(
+		WithName("payments"),
+		WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures > 0 }),
+		WithStatsd(sink),
+	)
+
+	assert.NotNil(t, fail(cb))
+	assert.Equal(t, ErrOpenState, succeed(cb))
+	require.NoError(t, sink.Close())
+
+	// метрики отправлены одним пакетом
+	require.Len(t, w.written(), 1)
+	lines := w.lines()
+	require.Len(t, lines, 4)
+	assert.Regexp(t, `^circuit_breaker\.call\.duration:[0-9.]+\|ms\|#env:test,name:payments,outcome:failure$`, lines[0])
+	assert.Equal(t, "circuit_breaker.transitions:1|c|#env:test,name:payments,from:closed,to:open", lines[1])
+	assert.Equal(t, "_e{32,33}:Circuit breaker payments is open|Changed state from closed to open|t:error|#env:test,name:payments,from:closed,to:open", lines[2])
+	assert.Equal(t, "circuit_breaker.rejections:1|c|#env:test,name:payments,reason:open", lines[3])
+
+	assert.Equal(t, ErrOpenState, succeed(cb))
+	assert.Equal(t, uint64(1), sink.Dropped())
+}
+
+func TestWithStatsd_SampleRate(t *testing.T) {
+	w := &packetWriter{}
+	sink, err := NewStatsdSink(w, WithStatsdPrefix("cb."), WithStatsdSampleRate(0.5))
+	require.NoError(t, err)
+	cb := NewCircuitBreaker(WithStatsd(sink))
+
+	for i := 0; i < 1000; i++ {
+		assert.Nil(t, succeed(cb))
+	}
+	require.NoError(t, sink.Close())
+
+	lines := w.lines()
+	assert.InDelta(t, 500, len(lines), 100)
+	for _, line := range lines {
+		assert.True(t, strings.HasPrefix(line, "cb.call.duration:"), line)
+		assert.Contains(t, line, "|@0.5|#")
+	}
+
+	for _, rate := range []float64{0, -1, 1.5} {
+		_, err := NewStatsdSink(w, WithStatsdSampleRate(rate))
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+	}
+}
+
+func TestStatsdSink_Flush(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	w := &packetWriter{}
+	sink, err := NewStatsdSink(w, WithStatsdClock(clock), WithStatsdFlushInterval(time.Second), WithStatsdMaxPacketSize(20))
+	require.NoError(t, err)
+	defer sink.Close()
+
+	sink.write("a:1|c")
+	sink.write("b:1|c")
+	assert.Eventually(t, func() bool { return len(sink.packets) == 0 }, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+	assert.Empty(t, w.written())
+
+	clock.Advance(time.Second)
+	assert.Eventually(t, func() bool { return len(w.written()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, "a:1|c\nb:1|c", w.written()[0])
+
+	// метрика, не помещающаяся в пакет, отправляет накопленные
+	sink.write("c:1|c")
+	sink.write("d:1|c")
+	sink.write("e:1|c")
+	sink.write("long.metric.name:1|c")
+	assert.Eventually(t, func() bool { return len(w.written()) == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, "c:1|c\nd:1|c\ne:1|c", w.written()[1])
+}
+
+func TestStatsdSink_Overflow(t *testing.T) {
+	w := &packetWriter{release: make(chan struct{})}
+	sink, err := NewStatsdSink(w, WithStatsdQueueSize(1), WithStatsdMaxPacketSize(1))
+	require.NoError(t, err)
+
+	// запись заблокирована: в пакете, записи и очереди не больше трех метрик
+	for i := 0; i < 10; i++ {
+		sink.write("a:1|c")
+	}
+	assert.GreaterOrEqual(t, sink.Dropped(), uint64(7))
+
+	close(w.release)
+	require.NoError(t, sink.Close())
+	assert.Equal(t, 10, len(w.lines())+int(sink.Dropped()))
+}
+
+func TestDialStatsd(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	sink, err := DialStatsd(listener.LocalAddr().String())
+	require.NoError(t, err)
+	sink.write("cb.requests:1|c")
+	require.NoError(t, sink.Close())
+	require.NoError(t, sink.Close())
+
+	buf := make([]byte, 1500)
+	require.NoError(t, listener.SetReadDeadline(time.Now().Add(time.Second)))
+	n, _, err := listener.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "cb.requests:1|c", string(buf[:n]))
+
+	_, err = DialStatsd(listener.LocalAddr().String(), WithStatsdSampleRate(2))
+	assert.ErrorIs(t, err, ErrInvalidConfig)
+}
@@ -0,0 +1,104 @@
+package main
+
+import "time"
+
+// stateSpanLimit - кол-во последних завершенных периодов нахождения в состоянии,
+// по которым считается OpenPercentage.
+const stateSpanLimit = 1024
+
+// stateSpan - период нахождения в состоянии.
+type stateSpan struct {
+	state    State
+	from, to time.Time
+}
+
+// stateTimes - время нахождения в состояниях. Изменяется и читается под cb.mu.
+type stateTimes struct {
+	// Суммарное время завершенных периодов по состояниям.
+	total [StateHalfOpen + 1]time.Duration
+	spans []stateSpan
+}
+
+func (t *stateTimes) record(span stateSpan) {
+	t.total[span.state] += span.to.Sub(span.from)
+
+	if len(t.spans) == stateSpanLimit {
+		copy(t.spans, t.spans[1:])
+		t.spans = t.spans[:stateSpanLimit-1]
+	}
+	t.spans = append(t.spans, span)
+}
+
+// TimeInCurrentState возвращает время с последней смены состояния.
+func (cb *CircuitBreaker) TimeInCurrentState() time.Duration {
+	return cb.config().timeProvider.Now().Sub(cb.current.Load().since)
+}
+
+// TimeInState возвращает суммарное время нахождения в state за все время жизни.
+func (cb *CircuitBreaker) TimeInState(state State) time.Duration {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	var total time.Duration
+	if int(state) < len(cb.stateTimes.total) {
+		total = cb.stateTimes.total[state]
+	}
+	if current := cb.current.Load(); current.state == state {
+		total += cb.config().timeProvider.Now().Sub(current.since)
+	}
+	return total
+}
+
+// OpenPercentage возвращает долю (0..100) времени за последний window, проведенного
+// в состоянии Open. Если Circuit Breaker создан позже начала window, доля считается
+// от времени жизни. Учитываются последние 1024 смены состояния.
+func (cb *CircuitBreaker) OpenPercentage(window time.Duration) float64 {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := cb.config().timeProvider.Now()
+	start := now.Add(-window)
+	if start.Before(cb.createdAt) {
+		start = cb.createdAt
+	}
+	if !now.After(start) {
+		return 0
+	}
+
+	open := cb.timeIn(StateOpen, start, now)
+	return float64(open) / float64(now.Sub(start)) * 100
+}
+
+// timeIn возвращает время нахождения в state в интервале [start, end].
+// Вызывается под cb.mu.
+func (cb *CircuitBreaker) timeIn(state State, start, end time.Time) time.Duration {
+	current := cb.current.Load()
+	spans := append(cb.stateTimes.spans[:len(cb.stateTimes.spans):len(cb.stateTimes.spans)],
+		stateSpan{state: current.state, from: current.since, to: end})
+
+	var total time.Duration
+	for _, span := range spans {
+		if span.state != state {
+			continue
+		}
+		from, to := maxTime(span.from, start), minTime(span.to, end)
+		if to.After(from) {
+			total += to.Sub(from)
+		}
+	}
+	return total
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker_TimeInState(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cb := NewCircuitBreaker(WithClock(clock), WithTimeout(10*time.Second), WithMaxRequests(1))
+
+	clock.Advance(30 * time.Second)
+	cb.trip()
+	clock.Advance(15 * time.Second)
+	assert.Equal(t, 15*time.Second, cb.TimeInCurrentState())
+	assert.Nil(t, succeed(cb))
+	clock.Advance(5 * time.Second)
+
+	assert.Equal(t, 5*time.Second, cb.TimeInCurrentState())
+	assert.Equal(t, 35*time.Second, cb.TimeInState(StateClosed))
+	assert.Equal(t, 15*time.Second, cb.TimeInState(StateOpen))
+	assert.Equal(t, time.Duration(0), cb.TimeInState(StateHalfOpen))
+
+	assert.InDelta(t, 30, cb.OpenPercentage(50*time.Second), 0.001)
+	assert.InDelta(t, 50, cb.OpenPercentage(10*time.Second), 0.001)
+	assert.InDelta(t, 0, cb.OpenPercentage(5*time.Second), 0.001)
+	// окно больше времени жизни
+	assert.InDelta(t, 30, cb.OpenPercentage(time.Hour), 0.001)
+
+	cb.trip()
+	clock.Advance(5 * time.Second)
+	assert.InDelta(t, 50, cb.OpenPercentage(10*time.Second), 0.001)
+	assert.Equal(t, 20*time.Second, cb.TimeInState(StateOpen))
+}
+
+func TestCircuitBreaker_OpenPercentageNoTime(t *testing.T) {
+	cb := NewCircuitBreaker(WithClock(clocktest.New(time.Now())))
+	assert.Equal(t, 0.0, cb.OpenPercentage(time.Minute))
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	wheelLevels    = 4
+	wheelSlotBits  = 6
+	wheelSlots     = 1 << wheelSlotBits
+	wheelSlotsMask = wheelSlots - 1
+)
+
+// wheelEntry - запланированный переход Circuit Breaker из Open в Half-Open.
+type wheelEntry struct {
+	cb         *CircuitBreaker
+	generation uint64
+	// Номер тика, на котором истекает состояние Open.
+	tick uint64
+}
+
+// TimerWheel - иерархическое колесо таймеров, общее для множества Circuit Breaker.
+// Оно переводит Circuit Breaker из Open в Half-Open по истечении timeout
+// без отдельного таймера на каждый экземпляр и без ожидания следующего запроса.
+//
+// Колесо состоит из wheelLevels уровней по wheelSlots слотов. Слот уровня l
+// покрывает wheelSlots^l тиков; записи с верхних уровней по мере приближения
+// срока перекладываются на нижние.
+type TimerWheel struct {
+	mu    sync.Mutex
+	clock Clock
+	tick  time.Duration
+	start time.Time
+	// Номер последнего обработанного тика.
+	now    uint64
+	levels [wheelLevels][wheelSlots][]wheelEntry
+	// Пока колесо приостановлено, переходы не планируются.
+	paused bool
+}
+
+// NewTimerWheel создает колесо с шагом tick, которое Run продвигает по часам c.
+// Точность перехода в Half-Open не превышает одного шага.
+func NewTimerWheel(tick time.Duration, c Clock) *TimerWheel {
+	return &TimerWheel{
+		clock: c,
+		tick:  tick,
+		start: c.Now(),
+	}
+}
+
+// WithTimerWheel передает переходы Open -> Half-Open общему колесу таймеров.
+func WithTimerWheel(wheel *TimerWheel) Option {
+	return func(s *settings) {
+		s.timerWheel = wheel
+	}
+}
+
+// Run продвигает колесо по часам колеса, пока не будет отменен ctx.
+func (w *TimerWheel) Run(ctx context.Context) {
+	timer := w.clock.NewTimer(w.tick)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C():
+			w.Advance(w.clock.Now())
+			timer.Reset(w.tick)
+		}
+	}
+}
+
+// Advance обрабатывает все тики до момента now включительно
+// и переводит Circuit Breaker с истекшим состоянием Open в Half-Open.
+func (w *TimerWheel) Advance(now time.Time) {
+	w.mu.Lock()
+	target := w.tickOf(now)
+	var expired []wheelEntry
+	for w.now < target {
+		w.now++
+		expired = w.advanceTick(expired)
+	}
+	w.mu.Unlock()
+
+	for _, e := range expired {
+		e.cb.expire(e.generation)
+	}
+}
+
+// schedule планирует переход в Half-Open для состояния generation,
+// истекающего в момент expiry.
+func (w *TimerWheel) schedule(cb *CircuitBreaker, generation uint64, expiry time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.paused {
+		return
+	}
+	tick := w.tickOf(expiry)
+	// состояние Open истекает строго после expiry
+	tick++
+	w.insert(wheelEntry{cb: cb, generation: generation, tick: tick})
+}
+
+// setPaused приостанавливает или возобновляет планирование переходов.
+// При остановке запланированные переходы отбрасываются.
+func (w *TimerWheel) setPaused(paused bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.paused = paused
+	if paused {
+		w.levels = [wheelLevels][wheelSlots][]wheelEntry{}
+	}
+}
+
+// tickOf возвращает номер тика, в который попадает момент t.
+func (w *TimerWheel) tickOf(t time.Time) uint64 {
+	if !t.After(w.start) {
+		return 0
+	}
+	return uint64(t.Sub(w.start) / w.tick)
+}
+
+func (w *TimerWheel) insert(e wheelEntry) {
+	if e.tick <= w.now {
+		e.tick = w.now + 1
+	}
+
+	delta := e.tick - w.now
+	for level := 0; level < wheelLevels; level++ {
+		if delta < 1<<(wheelSlotBits*(level+1)) || level == wheelLevels-1 {
+			tick := e.tick
+			// запись дальше горизонта колеса ждет в последнем слоте верхнего уровня
+			if level == wheelLevels-1 && delta >= 1<<(wheelSlotBits*wheelLevels) {
+				tick = w.now + 1<<(wheelSlotBits*wheelLevels) - 1
+			}
+			slot := (tick >> (wheelSlotBits * level)) & wheelSlotsMask
+			w.levels[level][slot] = append(w.levels[level][slot], e)
+			return
+		}
+	}
+}
+
+func (w *TimerWheel) advanceTick(expired []wheelEntry) []wheelEntry {
+	// при переходе через границу уровня записи верхних уровней перекладываются ниже
+	for level := 1; level < wheelLevels; level++ {
+		if w.now&(1<<(wheelSlotBits*level)-1) != 0 {
+			break
+		}
+		slot := (w.now >> (wheelSlotBits * level)) & wheelSlotsMask
+		entries := w.levels[level][slot]
+		w.levels[level][slot] = nil
+		for _, e := range entries {
+			if e.tick <= w.now {
+				expired = append(expired, e)
+			} else {
+				w.insert(e)
+			}
+		}
+	}
+
+	slot := w.now & wheelSlotsMask
+	entries := w.levels[0][slot]
+	w.levels[0][slot] = nil
+	for _, e := range entries {
+		if e.tick <= w.now {
+			expired = append(expired, e)
+		} else {
+			w.insert(e)
+		}
+	}
+
+	return expired
+}
+
+// expire переводит Circuit Breaker в Half-Open, если он все еще находится
+// в состоянии Open с номером generation.
+func (cb *CircuitBreaker) expire(generation uint64) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	current := cb.current.Load()
+	if current.generation == generation && current.state == StateOpen {
+		cb.transition(StateHalfOpen, ReasonTimeout, nil)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+)
+
+func TestTimerWheel_Advance(t *testing.T) {
+	timeProvider := clocktest.New(time.Unix(1000, 0))
+	wheel := NewTimerWheel(10*time.Millisecond, timeProvider)
+
+	short := NewCircuitBreaker(
+		WithTimeout(time.Second),
+		WithTimeProvider(timeProvider),
+		WithTimerWheel(wheel),
+	)
+	// timeout больше горизонта нижнего уровня колеса
+	long := NewCircuitBreaker(
+		WithTimeout(time.Minute),
+		WithTimeProvider(timeProvider),
+		WithTimerWheel(wheel),
+	)
+
+	short.trip()
+	long.trip()
+
+	wheel.Advance(timeProvider.Now().Add(time.Second))
+	assert.Equal(t, StateOpen, short.State())
+
+	wheel.Advance(timeProvider.Now().Add(time.Second + 10*time.Millisecond))
+	assert.Equal(t, StateHalfOpen, short.State())
+	assert.Equal(t, StateOpen, long.State())
+
+	wheel.Advance(timeProvider.Now().Add(59 * time.Second))
+	assert.Equal(t, StateOpen, long.State())
+
+	wheel.Advance(timeProvider.Now().Add(time.Minute + 10*time.Millisecond))
+	assert.Equal(t, StateHalfOpen, long.State())
+}
+
+func TestTimerWheel_BeyondHorizon(t *testing.T) {
+	timeProvider := clocktest.New(time.Unix(1000, 0))
+	// горизонт колеса - 64^4 тиков по 1мкс, около 16 секунд
+	wheel := NewTimerWheel(time.Microsecond, timeProvider)
+
+	cb := NewCircuitBreaker(
+		WithTimeout(20*time.Second),
+		WithTimeProvider(timeProvider),
+		WithTimerWheel(wheel),
+	)
+	cb.trip()
+
+	wheel.Advance(timeProvider.Now().Add(19 * time.Second))
+	assert.Equal(t, StateOpen, cb.State())
+
+	wheel.Advance(timeProvider.Now().Add(20*time.Second + time.Millisecond))
+	assert.Equal(t, StateHalfOpen, cb.State())
+}
+
+func TestTimerWheel_StaleEntry(t *testing.T) {
+	timeProvider := clocktest.New(time.Unix(1000, 0))
+	wheel := NewTimerWheel(10*time.Millisecond, timeProvider)
+
+	cb := NewCircuitBreaker(
+		WithTimeout(time.Second),
+		WithTimeProvider(timeProvider),
+		WithTimerWheel(wheel),
+	)
+	cb.trip()
+
+	// Circuit Breaker вышел из Open раньше - запись в колесе устарела
+	cb.mu.Lock()
+	cb.setState(StateClosed)
+	cb.mu.Unlock()
+
+	wheel.Advance(timeProvider.Now().Add(2 * time.Second))
+	assert.Equal(t, StateClosed, cb.State())
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// TransitionReason - причина смены состояния.
+type TransitionReason string
+
+const (
+	// ReasonTripStrategy - сработала стратегия перехода в Open (WithReadyToTrip, WithWarmup).
+	ReasonTripStrategy TransitionReason = "trip strategy"
+	// ReasonHalfOpenFailure - ошибка запроса в состоянии Half-Open.
+	ReasonHalfOpenFailure TransitionReason = "half-open failure"
+	// ReasonRecovered - достаточно успешных запросов в состоянии Half-Open.
+	ReasonRecovered TransitionReason = "recovered"
+	// ReasonTimeout - истек период нахождения в состоянии Open.
+	ReasonTimeout TransitionReason = "open timeout"
+	// ReasonHealthCheck - успешная проверка доступности, см. WithHealthCheck.
+	ReasonHealthCheck TransitionReason = "health check"
+	// ReasonQueueDepth - упреждающий переход по глубине очереди, см. WithShedOnQueueDepth.
+	ReasonQueueDepth TransitionReason = "queue depth"
+	// ReasonChildren - в Open перешло достаточно дочерних Circuit Breaker.
+	ReasonChildren TransitionReason = "children tripped"
+	// ReasonOutlier - Circuit Breaker исключен OutlierDetector.
+	ReasonOutlier TransitionReason = "outlier"
+	// ReasonManual - переход выполнен вручную.
+	ReasonManual TransitionReason = "manual"
+)
+
+// transitionLogSize - кол-во последних переходов, доступных через Transitions.
+const transitionLogSize = 128
+
+type transitionLog struct {
+	mu      sync.Mutex
+	changes []StateChange
+}
+
+func (l *transitionLog) record(change StateChange) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.changes) == transitionLogSize {
+		copy(l.changes, l.changes[1:])
+		l.changes = l.changes[:transitionLogSize-1]
+	}
+	l.changes = append(l.changes, change)
+}
+
+// transition меняет состояние, сохраняя причину и ошибку вызвавшего переход запроса.
+// Вызывается под cb.mu.
+func (cb *CircuitBreaker) transition(state State, reason TransitionReason, err error) {
+	cb.reason, cb.cause = reason, err
+	cb.setState(state)
+}
+
+// Transitions возвращает переходы не раньше since от старых к новым: время, причину,
+// счетчики прошлого состояния и ошибку вызвавшего переход запроса.
+// Хранятся последние 128 переходов.
+func (cb *CircuitBreaker) Transitions(since time.Time) []StateChange {
+	l := &cb.audit
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var changes []StateChange
+	for _, change := range l.changes {
+		if !change.At.Before(since) {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
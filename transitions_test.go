@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker_Transitions(t *testing.T) {
+	start := time.Date(2024, 3, 1, 3, 0, 0, 0, time.UTC)
+	clock := clocktest.New(start)
+	cb := NewCircuitBreaker(
+		WithName("payments"),
+		WithClock(clock),
+		WithTimeout(time.Second),
+		WithMaxRequests(1),
+		WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures > 1 }),
+	)
+
+	assert.Nil(t, succeed(cb))
+	assert.NotNil(t, fail(cb))
+	assert.NotNil(t, fail(cb))
+	clock.Advance(2 * time.Second)
+	assert.Nil(t, succeed(cb))
+	clock.Advance(time.Second)
+	cb.trip()
+
+	assert.Equal(t, []StateChange{
+		{Name: "payments", From: StateClosed, To: StateOpen, At: start, Reason: ReasonTripStrategy,
+			Counts: Counts{Requests: 3, TotalSuccess: 1, TotalFailures: 2, ConsecutiveFailures: 2}, Err: errors.New("fail")},
+		{Name: "payments", From: StateOpen, To: StateHalfOpen, At: start.Add(2 * time.Second), Reason: ReasonTimeout},
+		{Name: "payments", From: StateHalfOpen, To: StateClosed, At: start.Add(2 * time.Second), Reason: ReasonRecovered,
+			Counts: Counts{Requests: 1, TotalSuccess: 1, ConsecutiveSuccesses: 1}},
+		{Name: "payments", From: StateClosed, To: StateOpen, At: start.Add(3 * time.Second), Reason: ReasonManual},
+	}, cb.Transitions(time.Time{}))
+
+	changes := cb.Transitions(start.Add(2 * time.Second))
+	assert.Len(t, changes, 3)
+	assert.Equal(t, ReasonTimeout, changes[0].Reason)
+	assert.Empty(t, cb.Transitions(start.Add(time.Hour)))
+}
+
+func TestCircuitBreaker_TransitionsBounded(t *testing.T) {
+	clock := clocktest.New(time.Now())
+	cb := NewCircuitBreaker(WithClock(clock), WithTimeout(time.Second), WithMaxRequests(1))
+
+	for i := 0; i < transitionLogSize; i++ {
+		cb.trip()
+		clock.Advance(2 * time.Second)
+		assert.Nil(t, succeed(cb))
+	}
+
+	changes := cb.Transitions(time.Time{})
+	assert.Len(t, changes, transitionLogSize)
+	assert.Equal(t, ReasonTimeout, changes[0].Reason)
+	assert.Equal(t, ReasonRecovered, changes[len(changes)-1].Reason)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/twitchtv/twirp"
+)
+
+type TwirpOption func(*twirpHooks)
+
+// WithTwirpKey задает ключ Circuit Breaker для вызова метода method сервиса service,
+// где service - полное имя сервиса с пакетом, например "shop.Orders".
+// По умолчанию TwirpMethodKey.
+func WithTwirpKey(key func(ctx context.Context, service, method string) string) TwirpOption {
+	return func(h *twirpHooks) {
+		h.key = key
+	}
+}
+
+// WithTwirpFailurePolicy задает, какие коды ошибок считаются неуспешными вызовами.
+// По умолчанию DefaultTwirpFailurePolicy.
+func WithTwirpFailurePolicy(isFailure func(code twirp.ErrorCode) bool) TwirpOption {
+	return func(h *twirpHooks) {
+		h.isFailure = isFailure
+	}
+}
+
+// TwirpServiceKey возвращает полное имя сервиса, один Circuit Breaker на сервис.
+func TwirpServiceKey(_ context.Context, service, _ string) string {
+	return service
+}
+
+// TwirpMethodKey возвращает полное имя сервиса и метода, например "shop.Orders/Get".
+func TwirpMethodKey(_ context.Context, service, method string) string {
+	return service + "/" + method
+}
+
+// DefaultTwirpFailurePolicy считает неуспешными вызовы с кодами, означающими
+// недоступность или сбой сервера, аналогично DefaultGRPCFailurePolicy.
+func DefaultTwirpFailurePolicy(code twirp.ErrorCode) bool {
+	switch code {
+	case twirp.Unavailable, twirp.DeadlineExceeded, twirp.Internal, twirp.Unknown, twirp.DataLoss:
+		return true
+	default:
+		return false
+	}
+}
+
+type twirpHooks struct {
+	group     *Group
+	key       func(ctx context.Context, service, method string) string
+	isFailure func(code twirp.ErrorCode) bool
+}
+
+type twirpDoneKey struct{}
+
+// TwirpClientHooks возвращает hooks клиента Twirp, выполняющие вызовы через
+// Circuit Breaker группы по ключу вызова. Результат вызова учитывается по коду
+// ошибки, см. WithTwirpFailurePolicy. Отклоненный вызов не отправляется и
+// завершается ошибкой twirp.Unavailable с метаданными "breaker", "state" и "reason",
+// а для состояния Open - "retry_after" с оставшимся временем состояния.
+// Hooks объединяются с остальными через twirp.ChainClientHooks.
+func TwirpClientHooks(group *Group, options ...TwirpOption) *twirp.ClientHooks {
+	h := &twirpHooks{group: group, key: TwirpMethodKey, isFailure: DefaultTwirpFailurePolicy}
+	for _, opt := range options {
+		opt(h)
+	}
+	return &twirp.ClientHooks{
+		RequestPrepared: h.requestPrepared,
+		ResponseReceived: func(ctx context.Context) {
+			if done, ok := ctx.Value(twirpDoneKey{}).(func(error)); ok {
+				done(nil)
+			}
+		},
+		Error: func(ctx context.Context, twerr twirp.Error) {
+			if done, ok := ctx.Value(twirpDoneKey{}).(func(error)); ok {
+				if h.isFailure(twerr.Code()) {
+					done(twerr)
+				} else {
+					done(nil)
+				}
+			}
+		},
+	}
+}
+
+func (h *twirpHooks) requestPrepared(ctx context.Context, _ *http.Request) (context.Context, error) {
+	service, _ := twirp.ServiceName(ctx)
+	if pkg, ok := twirp.PackageName(ctx); ok && pkg != "" {
+		service = pkg + "." + service
+	}
+	method, _ := twirp.MethodName(ctx)
+
+	cb := h.group.Get(h.key(ctx, service, method))
+	done, err := cb.allow()
+	if err != nil {
+		return ctx, twirpRejection(cb, err)
+	}
+	return context.WithValue(ctx, twirpDoneKey{}, done), nil
+}
+
+// twirpRejection описывает отказ Circuit Breaker cb с ошибкой err в виде ошибки Twirp.
+func twirpRejection(cb *CircuitBreaker, err error) twirp.Error {
+	current := cb.current.Load()
+	twerr := twirp.WrapError(twirp.NewError(twirp.Unavailable, fmt.Sprintf("circuit breaker %s: %v", cb.Path(), err)), err).
+		WithMeta("breaker", cb.Path()).
+		WithMeta("state", current.state.String()).
+		WithMeta("reason", strings.ToUpper(rejectionReason(err)))
+	if current.state == StateOpen {
+		if remaining := current.expiry.Sub(cb.config().timeProvider.Now()); remaining > 0 {
+			twerr = twerr.WithMeta("retry_after", remaining.String())
+		}
+	}
+	return twerr
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+	"github.com/twitchtv/twirp/example"
+)
+
+type haberdasherFunc func(ctx context.Context, size *example.Size) (*example.Hat, error)
+
+func (f haberdasherFunc) MakeHat(ctx context.Context, size *example.Size) (*example.Hat, error) {
+	return f(ctx, size)
+}
+
+// twirpClient возвращает клиент тестового сервиса: размер 0 - ошибка twirp.InvalidArgument,
+// отрицательный размер - twirp.Internal, иначе успешный ответ.
+func twirpClient(t *testing.T, hooks *twirp.ClientHooks) (example.Haberdasher, *int) {
+	var calls int
+	server := httptest.NewServer(example.NewHaberdasherServer(haberdasherFunc(func(_ context.Context, size *example.Size) (*example.Hat, error) {
+		calls++
+		switch {
+		case size.Inches == 0:
+			return nil, twirp.InvalidArgumentError("inches", "must be positive")
+		case size.Inches < 0:
+			return nil, twirp.InternalError("out of fabric")
+		default:
+			return &example.Hat{Size: size.Inches}, nil
+		}
+	})))
+	t.Cleanup(server.Close)
+	return example.NewHaberdasherProtobufClient(server.URL, http.DefaultClient, twirp.WithClientHooks(hooks)), &calls
+}
+
+func TestTwirpClientHooks(t *testing.T) {
+	group := tripOnFirstFailure()
+	client, calls := twirpClient(t, TwirpClientHooks(group))
+	ctx := context.Background()
+
+	hat, err := client.MakeHat(ctx, &example.Size{Inches: 12})
+	require.NoError(t, err)
+	assert.Equal(t, int32(12), hat.Size)
+
+	_, err = client.MakeHat(ctx, &example.Size{Inches: 0})
+	var twerr twirp.Error
+	require.ErrorAs(t, err, &twerr)
+	assert.Equal(t, twirp.InvalidArgument, twerr.Code())
+
+	cb := group.Get("twitch.twirp.example.Haberdasher/MakeHat")
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, uint32(2), cb.Counts().TotalSuccess)
+
+	_, err = client.MakeHat(ctx, &example.Size{Inches: -1})
+	require.ErrorAs(t, err, &twerr)
+	assert.Equal(t, twirp.Internal, twerr.Code())
+	assert.Equal(t, StateOpen, cb.State())
+
+	_, err = client.MakeHat(ctx, &example.Size{Inches: 12})
+	require.ErrorAs(t, err, &twerr)
+	assert.Equal(t, twirp.Unavailable, twerr.Code())
+	assert.ErrorIs(t, err, ErrOpenState)
+	assert.Equal(t, "twitch.twirp.example.Haberdasher/MakeHat", twerr.Meta("breaker"))
+	assert.Equal(t, "open", twerr.Meta("state"))
+	assert.Equal(t, "OPEN", twerr.Meta("reason"))
+	assert.NotEmpty(t, twerr.Meta("retry_after"))
+	assert.Equal(t, 3, *calls)
+}
+
+func TestTwirpClientHooks_FailurePolicy(t *testing.T) {
+	group := tripOnFirstFailure()
+	client, _ := twirpClient(t, TwirpClientHooks(group, WithTwirpKey(TwirpServiceKey), WithTwirpFailurePolicy(func(code twirp.ErrorCode) bool {
+		return code == twirp.InvalidArgument
+	})))
+
+	_, err := client.MakeHat(context.Background(), &example.Size{Inches: -1})
+	require.Error(t, err)
+	cb := group.Get("twitch.twirp.example.Haberdasher")
+	assert.Equal(t, StateClosed, cb.State())
+
+	_, err = client.MakeHat(context.Background(), &example.Size{Inches: 0})
+	require.Error(t, err)
+	assert.Equal(t, StateOpen, cb.State())
+}
+
+func TestTwirpClientHooks_Chain(t *testing.T) {
+	group := tripOnFirstFailure()
+	var errs []twirp.ErrorCode
+	client, calls := twirpClient(t, twirp.ChainClientHooks(TwirpClientHooks(group), &twirp.ClientHooks{
+		Error: func(_ context.Context, err twirp.Error) {
+			errs = append(errs, err.Code())
+		},
+	}))
+
+	_, err := client.MakeHat(context.Background(), &example.Size{Inches: -1})
+	require.Error(t, err)
+	_, err = client.MakeHat(context.Background(), &example.Size{Inches: 12})
+	assert.ErrorIs(t, err, ErrOpenState)
+	assert.Equal(t, []twirp.ErrorCode{twirp.Internal, twirp.Unavailable}, errs)
+	assert.Equal(t, 1, *calls)
+}
+
+func TestTwirpKeys(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "shop.Orders", TwirpServiceKey(ctx, "shop.Orders", "Get"))
+	assert.Equal(t, "shop.Orders/Get", TwirpMethodKey(ctx, "shop.Orders", "Get"))
+}
+
+func TestDefaultTwirpFailurePolicy(t *testing.T) {
+	for _, code := range []twirp.ErrorCode{twirp.Unavailable, twirp.DeadlineExceeded, twirp.Internal, twirp.Unknown, twirp.DataLoss} {
+		assert.True(t, DefaultTwirpFailurePolicy(code), code)
+	}
+	for _, code := range []twirp.ErrorCode{twirp.NotFound, twirp.InvalidArgument, twirp.PermissionDenied, twirp.Canceled} {
+		assert.False(t, DefaultTwirpFailurePolicy(code), code)
+	}
+}
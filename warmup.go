@@ -0,0 +1,23 @@
+package main
+
+import "time"
+
+// WithWarmup задает период прогрева после создания Circuit Breaker. В течение
+// period ошибки учитываются как обычно, но переход в Open определяется стратегией
+// readyToTrip вместо основной, например с более мягким порогом.
+// Если readyToTrip равен nil, переход в Open на время прогрева отключен.
+func WithWarmup(period time.Duration, readyToTrip func(counts Counts) bool) Option {
+	return func(s *settings) {
+		s.warmupPeriod = period
+		s.warmupReadyToTrip = readyToTrip
+	}
+}
+
+// shouldTrip применяет стратегию перехода в Open с учетом периода прогрева.
+func (cb *CircuitBreaker) shouldTrip(counts Counts) bool {
+	s := cb.config()
+	if s.warmupPeriod > 0 && s.timeProvider.Now().Sub(cb.createdAt) < s.warmupPeriod {
+		return s.warmupReadyToTrip != nil && s.warmupReadyToTrip(counts)
+	}
+	return s.readyToTrip(counts)
+}
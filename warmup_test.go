@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+)
+
+func TestCircuitBreaker_WarmupSuppressesTrip(t *testing.T) {
+	clock := clocktest.New(time.Unix(1000, 0))
+	cb := NewCircuitBreaker(
+		WithTimeProvider(clock),
+		WithWarmup(time.Minute, nil),
+		WithReadyToTrip(func(counts Counts) bool {
+			return counts.ConsecutiveFailures >= 2
+		}),
+	)
+
+	// во время прогрева ошибки учитываются, но не открывают Circuit Breaker
+	for i := 0; i < 5; i++ {
+		assert.NotNil(t, fail(cb))
+	}
+	assert.Equal(t, StateClosed, cb.State())
+	assert.Equal(t, uint32(5), cb.Counts().ConsecutiveFailures)
+
+	clock.Advance(time.Minute)
+	assert.NotNil(t, fail(cb))
+	assert.Equal(t, StateOpen, cb.State())
+}
+
+func TestCircuitBreaker_WarmupRelaxedThreshold(t *testing.T) {
+	clock := clocktest.New(time.Unix(1000, 0))
+	cb := NewCircuitBreaker(
+		WithTimeProvider(clock),
+		WithWarmup(time.Minute, func(counts Counts) bool {
+			return counts.ConsecutiveFailures >= 10
+		}),
+		WithReadyToTrip(func(counts Counts) bool {
+			return counts.ConsecutiveFailures >= 2
+		}),
+	)
+
+	for i := 0; i < 9; i++ {
+		assert.NotNil(t, fail(cb))
+	}
+	assert.Equal(t, StateClosed, cb.State())
+
+	assert.NotNil(t, fail(cb))
+	assert.Equal(t, StateOpen, cb.State())
+}
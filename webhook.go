@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clock"
+)
+
+// webhookQueueSize - кол-во переходов, ожидающих отправки. Переходы сверх
+// очереди отбрасываются, см. Webhook.Dropped.
+const webhookQueueSize = 64
+
+// Webhook отправляет POST-запрос с JSON-описанием перехода на каждый из адресов.
+// Отправка выполняется в отдельной горутине и не задерживает Execute.
+type Webhook struct {
+	urls    []string
+	client  *http.Client
+	timeout time.Duration
+	retries int
+	backoff time.Duration
+	states  []State
+	onError func(url string, err error)
+	clock   clock.Clock
+
+	mu      sync.Mutex
+	closed  bool
+	stop    chan struct{}
+	queue   chan []byte
+	done    chan struct{}
+	dropped atomic.Uint64
+}
+
+type WebhookOption func(*Webhook)
+
+// WithWebhookClient задает HTTP-клиент. По умолчанию http.DefaultClient.
+func WithWebhookClient(client *http.Client) WebhookOption {
+	return func(w *Webhook) {
+		w.client = client
+	}
+}
+
+// WithWebhookTimeout ограничивает время одной попытки отправки. По умолчанию 5s.
+func WithWebhookTimeout(timeout time.Duration) WebhookOption {
+	return func(w *Webhook) {
+		w.timeout = timeout
+	}
+}
+
+// WithWebhookRetries задает кол-во повторных попыток при ошибке сети,
+// статусе 5xx или 429. Пауза перед каждой попыткой удваивается, начиная с backoff.
+// По умолчанию 3 попытки, начиная с 1s.
+func WithWebhookRetries(retries int, backoff time.Duration) WebhookOption {
+	return func(w *Webhook) {
+		w.retries = retries
+		w.backoff = backoff
+	}
+}
+
+// WithWebhookStates задает состояния, переход в которые отправляется.
+// По умолчанию Open и Closed.
+func WithWebhookStates(states ...State) WebhookOption {
+	return func(w *Webhook) {
+		w.states = states
+	}
+}
+
+// WithWebhookOnError задает обработчик ошибки отправки на url после всех попыток.
+func WithWebhookOnError(onError func(url string, err error)) WebhookOption {
+	return func(w *Webhook) {
+		w.onError = onError
+	}
+}
+
+// WithWebhookClock задает источник времени для пауз между попытками.
+func WithWebhookClock(c clock.Clock) WebhookOption {
+	return func(w *Webhook) {
+		w.clock = c
+	}
+}
+
+func NewWebhook(urls []string, options ...WebhookOption) *Webhook {
+	w := &Webhook{
+		urls:    urls,
+		client:  http.DefaultClient,
+		timeout: 5 * time.Second,
+		retries: 3,
+		backoff: time.Second,
+		states:  []State{StateOpen, StateClosed},
+		clock:   clock.Real{},
+		stop:    make(chan struct{}),
+		queue:   make(chan []byte, webhookQueueSize),
+		done:    make(chan struct{}),
+	}
+	for _, opt := range options {
+		opt(w)
+	}
+
+	go w.deliver()
+	return w
+}
+
+// WithWebhook включает отправку переходов Circuit Breaker в w.
+// Один Webhook может использоваться несколькими Circuit Breaker.
+func WithWebhook(w *Webhook) Option {
+	return withObserver(w)
+}
+
+// Dropped возвращает кол-во переходов, отброшенных из-за переполнения очереди.
+func (w *Webhook) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Close прекращает прием переходов и повторные попытки и дожидается одной
+// попытки отправки уже принятых, не дольше WithWebhookTimeout каждая.
+func (w *Webhook) Close() {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.stop)
+		close(w.queue)
+	}
+	w.mu.Unlock()
+
+	<-w.done
+}
+
+func (w *Webhook) observeTransition(_ *CircuitBreaker, change StateChange) {
+	if !w.accepts(change.To) {
+		return
+	}
+	body, err := json.Marshal(newStateChangeJSON(change))
+	if err != nil {
+		return
+	}
+	w.enqueue(body)
+}
+
+// enqueue ставит body в очередь отправки, не блокируя вызывающего, и
+// сообщает, принят ли body.
+func (w *Webhook) enqueue(body []byte) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return false
+	}
+	select {
+	case w.queue <- body:
+		return true
+	default:
+		w.dropped.Add(1)
+		return false
+	}
+}
+
+func (*Webhook) observeCall(*CircuitBreaker, OutcomeRecord) {}
+
+func (*Webhook) observeRejection(*CircuitBreaker, error) {}
+
+func (w *Webhook) accepts(state State) bool {
+	for _, s := range w.states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Webhook) deliver() {
+	defer close(w.done)
+
+	for body := range w.queue {
+		for _, url := range w.urls {
+			if err := w.post(url, body); err != nil && w.onError != nil {
+				w.onError(url, err)
+			}
+		}
+	}
+}
+
+// post отправляет body на url, повторяя попытки при временных ошибках
+// до Close.
+func (w *Webhook) post(url string, body []byte) error {
+	backoff := w.backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := w.send(url, body)
+		if err == nil || !retry || attempt == w.retries {
+			return err
+		}
+
+		timer := w.clock.NewTimer(backoff)
+		select {
+		case <-timer.C():
+		case <-w.stop:
+			timer.Stop()
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+func (w *Webhook) send(url string, body []byte) (retry bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), w.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	// тело дочитывается, чтобы соединение вернулось в пул
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	err = fmt.Errorf("webhook %s: unexpected status %s", url, resp.Status)
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests, err
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/raymanovg/circuit-breaker/clocktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithWebhook(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		bodies   []map[string]any
+		attempts atomic.Int32
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		// первая попытка завершается ошибкой и повторяется
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var body map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		mu.Lock()
+		bodies = append(bodies, body)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook([]string{server.URL}, WithWebhookRetries(2, time.Millisecond))
+	clock := clocktest.New(time.Date(2024, 3, 1, 3, 0, 0, 0, time.UTC))
+	cb := NewCircuitBreaker(
+		WithName("payments"),
+		WithLabels(map[string]string{"team": "billing"}),
+		WithClock(clock),
+		WithTimeout(time.Second),
+		WithMaxRequests(1),
+		WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures > 0 }),
+		WithWebhook(webhook),
+	)
+
+	assert.NotNil(t, fail(cb))
+	clock.Advance(2 * time.Second)
+	assert.Nil(t, succeed(cb))
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(bodies) == 2
+	}, time.Second, time.Millisecond)
+	webhook.Close()
+
+	// переход в Half-Open по умолчанию не отправляется
+	assert.Equal(t, int32(3), attempts.Load())
+	require.Len(t, bodies, 2)
+	assert.Equal(t, map[string]any{
+		"name":   "payments",
+		"labels": map[string]any{"team": "billing"},
+		"from":   "closed",
+		"to":     "open",
+		"at":     "2024-03-01T03:00:00Z",
+		"reason": "trip strategy",
+		"error":  "fail",
+		"counts": map[string]any{
+			"requests": 1.0, "total_success": 0.0, "total_failures": 1.0,
+			"consecutive_successes": 0.0, "consecutive_failures": 1.0,
+		},
+	}, bodies[0])
+	assert.Equal(t, "half-open", bodies[1]["from"])
+	assert.Equal(t, "closed", bodies[1]["to"])
+	assert.Equal(t, "recovered", bodies[1]["reason"])
+}
+
+func TestWebhook_OnError(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	errs := make(chan error, 1)
+	webhook := NewWebhook([]string{server.URL},
+		WithWebhookStates(StateOpen),
+		WithWebhookRetries(3, time.Millisecond),
+		WithWebhookOnError(func(url string, err error) {
+			assert.Equal(t, server.URL, url)
+			errs <- err
+		}),
+	)
+	cb := NewCircuitBreaker(WithWebhook(webhook))
+	cb.trip()
+	err := <-errs
+	webhook.Close()
+
+	// статус 4xx не повторяется
+	assert.Equal(t, int32(1), attempts.Load())
+	assert.ErrorContains(t, err, "unexpected status 400 Bad Request")
+}
+
+func TestWebhook_Close(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	clock := clocktest.New(time.Now())
+	errs := make(chan error, 2)
+	webhook := NewWebhook([]string{server.URL},
+		WithWebhookStates(StateOpen),
+		WithWebhookRetries(3, time.Minute),
+		WithWebhookClock(clock),
+		WithWebhookOnError(func(_ string, err error) { errs <- err }),
+	)
+	NewCircuitBreaker(WithName("payments"), WithWebhook(webhook)).trip()
+	NewCircuitBreaker(WithName("orders"), WithWebhook(webhook)).trip()
+	assert.Eventually(t, func() bool { return clock.Timers() == 1 }, time.Second, time.Millisecond)
+
+	// Close прерывает паузу перед повтором, второй переход отправляется без повторов
+	webhook.Close()
+	assert.Equal(t, int32(2), attempts.Load())
+	assert.ErrorContains(t, <-errs, "unexpected status 503 Service Unavailable")
+	assert.ErrorContains(t, <-errs, "unexpected status 503 Service Unavailable")
+}
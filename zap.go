@@ -0,0 +1,80 @@
+package main
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// WithZap пишет события Circuit Breaker в logger, а при ненулевом summaryInterval -
+// и периодическую сводку (см. WithLogger и WithLogSummary).
+func WithZap(logger *zap.Logger, summaryInterval time.Duration) Option {
+	return combine(WithLogger(NewZapLogger(logger)), WithLogSummary(summaryInterval))
+}
+
+// NewZapLogger адаптирует logger к интерфейсу Logger. Значения известных типов
+// передаются типизированными полями zap.
+func NewZapLogger(logger *zap.Logger) Logger {
+	return zapLogger{logger}
+}
+
+type zapLogger struct {
+	logger *zap.Logger
+}
+
+func (l zapLogger) Log(level LogLevel, msg string, keyvals ...any) {
+	ce := l.logger.Check(zapLevel(level), msg)
+	if ce == nil {
+		return
+	}
+
+	fields := make([]zap.Field, 0, (len(keyvals)+1)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		key, _ := keyvals[i].(string)
+		var value any
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+		fields = append(fields, zapField(key, value))
+	}
+	ce.Write(fields...)
+}
+
+func zapField(key string, value any) zap.Field {
+	switch v := value.(type) {
+	case string:
+		return zap.String(key, v)
+	case uint64:
+		return zap.Uint64(key, v)
+	case error:
+		return zap.NamedError(key, v)
+	case Counts:
+		return zap.Object(key, v)
+	default:
+		return zap.Any(key, v)
+	}
+}
+
+func zapLevel(level LogLevel) zapcore.Level {
+	switch level {
+	case LogDebug:
+		return zapcore.DebugLevel
+	case LogWarn:
+		return zapcore.WarnLevel
+	case LogError:
+		return zapcore.ErrorLevel
+	default:
+		return zapcore.InfoLevel
+	}
+}
+
+// MarshalLogObject представляет счетчики объектом zap.
+func (c Counts) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddUint32("requests", c.Requests)
+	enc.AddUint32("total_success", c.TotalSuccess)
+	enc.AddUint32("total_failures", c.TotalFailures)
+	enc.AddUint32("consecutive_successes", c.ConsecutiveSuccesses)
+	enc.AddUint32("consecutive_failures", c.ConsecutiveFailures)
+	return nil
+}
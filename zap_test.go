@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	zapobserver "go.uber.org/zap/zaptest/observer"
+)
+
+func TestWithZap(t *testing.T) {
+	core, logs := zapobserver.New(zapcore.InfoLevel)
+	cb := NewCircuitBreaker(
+		WithName("payments"),
+		WithReadyToTrip(func(counts Counts) bool { return counts.ConsecutiveFailures > 0 }),
+		WithZap(zap.New(core), 0),
+	)
+
+	assert.NotNil(t, fail(cb))
+	assert.Equal(t, ErrOpenState, succeed(cb))
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, "state changed", entries[0].Message)
+	assert.Equal(t, zapcore.InfoLevel, entries[0].Level)
+	assert.Equal(t, map[string]any{
+		"name": "payments",
+		"from": "closed",
+		"to":   "open",
+		"counts": map[string]any{
+			"requests": uint32(1), "total_success": uint32(0), "total_failures": uint32(1),
+			"consecutive_successes": uint32(0), "consecutive_failures": uint32(1),
+		},
+	}, entries[0].ContextMap())
+
+	assert.Equal(t, "requests rejected", entries[1].Message)
+	assert.Equal(t, zapcore.WarnLevel, entries[1].Level)
+	assert.Equal(t, map[string]any{
+		"name":  "payments",
+		"count": uint64(1),
+		"error": "state is open",
+	}, entries[1].ContextMap())
+}